@@ -0,0 +1,246 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// approvalsField is the version-document field recording the approvals a submitted version has
+// collected - stored beside the lifecycle fields, like the claim fields.
+const approvalsField = "approvals"
+
+// ApprovalPolicy is what it takes to accept a submission of one type. The zero value is a single
+// reviewer's say-so, which is how every type behaves until its policy variable
+// (VolumeApprovalPolicy, LicenseApprovalPolicy, ...) is set - once at startup, like
+// VolumeReferenceValidation.
+type ApprovalPolicy struct {
+	// RequiredApprovals is how many distinct reviewers must approve, the accepting reviewer
+	// included; 0 means 1.
+	RequiredApprovals int
+	// ForbidSelfApproval stops a submitter approving or accepting their own change; their
+	// approval doesn't count toward RequiredApprovals either.
+	ForbidSelfApproval bool
+	// ElevatedFields need sign-off from a reviewer holding ElevatedRole: a submission changing any
+	// of them can only be accepted once such a reviewer has recorded an approval (see
+	// ApproveVersion - Accept*Version carries no roles).
+	ElevatedFields []string
+	ElevatedRole   string
+}
+
+// VersionApproval is one reviewer's recorded approval of a submitted version.
+type VersionApproval struct {
+	Reviewer   string    `bson:"reviewer"`
+	Roles      []string  `bson:"roles"`
+	ApprovedAt time.Time `bson:"approved_at"`
+	Note       *string   `bson:"note"`
+}
+
+// ApprovalStatus is where a submitted version stands against its type's ApprovalPolicy.
+// Satisfied means an Accept*Version by any other eligible reviewer would now go through;
+// otherwise Outstanding says what's still missing.
+type ApprovalStatus struct {
+	Type        string
+	RecordID    string
+	Version     int
+	Approvals   []VersionApproval
+	Required    int
+	Satisfied   bool
+	Outstanding string
+}
+
+// required is RequiredApprovals with its zero value resolved.
+func (p *ApprovalPolicy) required() int {
+	return max(p.RequiredApprovals, 1)
+}
+
+// singleReviewer reports whether p asks no more of an accept than the zero policy does, so the
+// accept needn't read the version's approvals at all.
+func (p *ApprovalPolicy) singleReviewer() bool {
+	return p.required() == 1 && !p.ForbidSelfApproval && len(p.ElevatedFields) == 0
+}
+
+// unmet returns what p still needs before a submission by submitter changing changed can be
+// accepted, given approvals recorded so far plus acceptor's accept ("" for none) - or "" if it's
+// satisfied.
+func (p *ApprovalPolicy) unmet(approvals []VersionApproval, changed []string, submitter, acceptor string) string {
+	if p.ForbidSelfApproval && acceptor != "" && acceptor == submitter {
+		return "the submitter can't accept their own change"
+	}
+	approvers := map[string]bool{}
+	elevated := false
+	for _, approval := range approvals {
+		if p.ForbidSelfApproval && approval.Reviewer == submitter {
+			continue
+		}
+		approvers[approval.Reviewer] = true
+		elevated = elevated || slices.Contains(approval.Roles, p.ElevatedRole)
+	}
+	if acceptor != "" {
+		approvers[acceptor] = true
+	}
+	if len(approvers) < p.required() {
+		return fmt.Sprintf("%d of %d required approvals", len(approvers), p.required())
+	}
+	for _, field := range changed {
+		if slices.Contains(p.ElevatedFields, field) && !elevated {
+			return fmt.Sprintf("a change to %s needs approval from a reviewer with role %q", field, p.ElevatedRole)
+		}
+	}
+	return ""
+}
+
+// policy returns cfg's approval policy, the zero policy if it has none.
+func (cfg entityVersioningConfig[T]) policy() *ApprovalPolicy {
+	if cfg.approvalPolicy == nil {
+		return &ApprovalPolicy{}
+	}
+	return cfg.approvalPolicy
+}
+
+// changedSinceBase returns the fields submitted changes relative to its base version.
+func (cfg entityVersioningConfig[T]) changedSinceBase(c context.Context, id string, submitted *T) ([]string, error) {
+	lc := cfg.lifecycle(submitted)
+	if lc.BaseVersion == nil {
+		return nil, &NoBaseVersionError{Type: cfg.typeName, ID: id, Version: cfg.version(submitted)}
+	}
+	base, err := cfg.requireVersion(c, id, *lc.BaseVersion)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.changedFields(submitted, base), nil
+}
+
+// checkApprovalPolicy returns an *ApprovalError unless cfg's policy lets acceptor accept
+// submitted now - planAccept's gate.
+func (cfg entityVersioningConfig[T]) checkApprovalPolicy(c context.Context, id string, submitted *T, acceptor string) error {
+	policy := cfg.policy()
+	if policy.singleReviewer() {
+		return nil
+	}
+	version := cfg.version(submitted)
+	pending, err := cfg.readPendingVersion(c, id, version)
+	if err != nil || pending == nil {
+		return err
+	}
+	changed, err := cfg.changedSinceBase(c, id, submitted)
+	if err != nil {
+		return err
+	}
+	if reason := policy.unmet(pending.Approvals, changed, pending.SubmittedBy, acceptor); reason != "" {
+		return &ApprovalError{Type: cfg.typeName, ID: id, Version: version, Reason: reason}
+	}
+	return nil
+}
+
+// approvalStatus reports where a submitted version stands against cfg's policy.
+func (cfg entityVersioningConfig[T]) approvalStatus(c context.Context, id string, version int) (*ApprovalStatus, error) {
+	submitted, err := cfg.requireVersion(c, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if state := cfg.lifecycle(submitted).State; state != models.VersionStateSubmitted {
+		return nil, &InvalidStateError{Type: cfg.typeName, ID: id, Version: version, State: state}
+	}
+	pending, err := cfg.readPendingVersion(c, id, version)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := cfg.changedSinceBase(c, id, submitted)
+	if err != nil {
+		return nil, err
+	}
+	policy := cfg.policy()
+	status := &ApprovalStatus{
+		Type: cfg.typeName, RecordID: id, Version: version,
+		Approvals: pending.Approvals, Required: policy.required(),
+		Outstanding: policy.unmet(pending.Approvals, changed, pending.SubmittedBy, ""),
+	}
+	if status.Approvals == nil {
+		status.Approvals = []VersionApproval{}
+	}
+	status.Satisfied = status.Outstanding == ""
+	return status, nil
+}
+
+// approveVersion records reviewer's approval of a submitted version, replacing any earlier one
+// of theirs, and returns the version's ApprovalStatus. Check and write share one transaction.
+func (cfg entityVersioningConfig[T]) approveVersion(c context.Context, id string, version int, reviewer string, roles []string, note *string) (*ApprovalStatus, error) {
+	var status *ApprovalStatus
+	err := withTransaction(c, func(tc context.Context) error {
+		now := time.Now()
+		pending, err := cfg.readPendingVersion(tc, id, version)
+		if err != nil {
+			return err
+		}
+		if pending == nil {
+			if _, err := cfg.requireMeta(tc, id); err != nil {
+				return err
+			}
+			return &VersionNotFoundError{Type: cfg.typeName, ID: id, Version: version}
+		}
+		if pending.State != models.VersionStateSubmitted {
+			return &InvalidStateError{Type: cfg.typeName, ID: id, Version: version, State: pending.State}
+		}
+		if err := cfg.checkClaim(tc, id, version, reviewer, now); err != nil {
+			return err
+		}
+		if cfg.policy().ForbidSelfApproval && reviewer == pending.SubmittedBy {
+			return &ApprovalError{Type: cfg.typeName, ID: id, Version: version, Reason: "the submitter can't approve their own change"}
+		}
+
+		approvals := slices.DeleteFunc(pending.Approvals, func(a VersionApproval) bool { return a.Reviewer == reviewer })
+		approvals = append(approvals, VersionApproval{Reviewer: reviewer, Roles: roles, ApprovedAt: now, Note: note})
+		if err := cfg.setVersionState(tc, id, version, bson.D{{Key: approvalsField, Value: approvals}}); err != nil {
+			return err
+		}
+		status, err = cfg.approvalStatus(tc, id, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ApproveVersion records reviewer's approval of a submitted version of an entity ("volume",
+// "publisher", "studio", "person", "license" or "contribution"), with the roles they hold and an
+// optional note, and returns where the version now stands against the type's ApprovalPolicy.
+// Approving doesn't accept: once the status is Satisfied, the matching Accept*Version promotes
+// the submission, and until then refuses with an *ApprovalError. Approving again replaces the
+// reviewer's earlier approval. A version claimed by another reviewer is a *ClaimError, and a
+// submitter approving their own change under ForbidSelfApproval an *ApprovalError.
+func ApproveVersion(c context.Context, entity, id string, version int, reviewer string, roles []string, note *string) (*ApprovalStatus, error) {
+	_, span := otel.Tracer("review-queue").Start(c, "db-approve-version", oteltrace.WithAttributes(
+		attribute.String("entity", entity), attribute.String("id", id), attribute.Int("version", version)))
+	defer span.End()
+
+	source, ok := reviewQueueSources[entity]
+	if !ok {
+		return nil, fmt.Errorf("approve: %s versions can't be approved", entity)
+	}
+	status, err := source.approveVersion(c, id, version, reviewer, roles, note)
+	if err != nil {
+		logging.Logger.Info("Version not approved", "entity", entity, "id", id, "version", version, "error", err)
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetApprovalStatus returns where a submitted version stands against its type's ApprovalPolicy -
+// see ApproveVersion.
+func GetApprovalStatus(c context.Context, entity, id string, version int) (*ApprovalStatus, error) {
+	source, ok := reviewQueueSources[entity]
+	if !ok {
+		return nil, fmt.Errorf("approve: %s versions can't be approved", entity)
+	}
+	return source.approvalStatus(c, id, version)
+}
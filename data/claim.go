@@ -0,0 +1,204 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Version-document fields holding a reviewer's claim on a submitted version. They sit alongside
+// the lifecycle fields rather than in models.VersionLifecycle, which catalog-objects owns, so
+// every version type carries them without a model change. A claim only means anything while the
+// version is submitted; accepting or rejecting it leaves them behind as a record of who had it.
+const (
+	claimedByField      = "claimed_by"
+	claimExpiresAtField = "claim_expires_at"
+)
+
+// DefaultClaimDuration is how long a claim lasts when ClaimVersion isn't given a duration.
+const DefaultClaimDuration = 30 * time.Minute
+
+// VersionClaim is a reviewer's hold on a submitted version: until ExpiresAt, only ClaimedBy can
+// accept or reject it.
+type VersionClaim struct {
+	Type      string
+	RecordID  string
+	Version   int
+	ClaimedBy string
+	ExpiresAt time.Time
+}
+
+// pendingVersionDocument is the slice of a version document the review queue and claims need -
+// the lifecycle fields every version type stores under the same keys, plus the claim and
+// approval fields.
+type pendingVersionDocument struct {
+	RecordID       string              `bson:"record_id"`
+	Version        int                 `bson:"version"`
+	State          models.VersionState `bson:"state"`
+	BaseVersion    *int                `bson:"base_version"`
+	SubmittedBy    string              `bson:"submitted_by"`
+	SubmittedAt    time.Time           `bson:"submitted_at"`
+	ClaimedBy      *string             `bson:"claimed_by"`
+	ClaimExpiresAt *time.Time          `bson:"claim_expires_at"`
+	Approvals      []VersionApproval   `bson:"approvals"`
+}
+
+// claimant returns who holds an unexpired claim on the version as of now, or "" if nobody does.
+func (d *pendingVersionDocument) claimant(now time.Time) string {
+	if d.ClaimedBy == nil || d.ClaimExpiresAt == nil || !d.ClaimExpiresAt.After(now) {
+		return ""
+	}
+	return *d.ClaimedBy
+}
+
+// readPendingVersion reads version's queue fields, or nil if there's no such version.
+func (cfg entityVersioningConfig[T]) readPendingVersion(c context.Context, id string, version int) (*pendingVersionDocument, error) {
+	filter := bson.D{{Key: "record_id", Value: id}, {Key: "version", Value: version}}
+	results, err := storeQuery[pendingVersionDocument](c, cfg.versionCollection, filter, nil, nil, 0, 1)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// checkClaim returns a *ClaimError if someone other than reviewer holds an unexpired claim on
+// the version. An unclaimed version is open to any reviewer - claiming is how reviewers keep
+// out of each other's way, not a step every review has to take.
+func (cfg entityVersioningConfig[T]) checkClaim(c context.Context, id string, version int, reviewer string, now time.Time) error {
+	pending, err := cfg.readPendingVersion(c, id, version)
+	if err != nil || pending == nil {
+		return err
+	}
+	if holder := pending.claimant(now); holder != "" && holder != reviewer {
+		return &ClaimError{Type: cfg.typeName, ID: id, Version: version, ClaimedBy: holder, ExpiresAt: *pending.ClaimExpiresAt}
+	}
+	return nil
+}
+
+// claimVersion gives reviewer the claim on a submitted version for duration, in one atomic
+// update that succeeds only if the version is unclaimed, already reviewer's (which extends the
+// claim), or claimed by someone whose claim has lapsed.
+func (cfg entityVersioningConfig[T]) claimVersion(c context.Context, id string, version int, reviewer string, duration time.Duration) (*VersionClaim, error) {
+	now := time.Now()
+	expiresAt := now.Add(duration)
+	filter := bson.D{
+		{Key: "record_id", Value: id},
+		{Key: "version", Value: version},
+		{Key: "state", Value: string(models.VersionStateSubmitted)},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: claimedByField, Value: nil}},
+			bson.D{{Key: claimedByField, Value: reviewer}},
+			bson.D{{Key: claimExpiresAtField, Value: bson.D{{Key: "$lte", Value: now}}}},
+		}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: claimedByField, Value: reviewer}, {Key: claimExpiresAtField, Value: expiresAt}}}}
+	// A claim released or lapsing between the update and the diagnosis below gets one retry.
+	for attempt := 0; attempt < 2; attempt++ {
+		raw, err := Storage.FindOneAndUpdate(c, cfg.versionCollection, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		if raw != nil {
+			return &VersionClaim{Type: cfg.typeName, RecordID: id, Version: version, ClaimedBy: reviewer, ExpiresAt: expiresAt}, nil
+		}
+
+		// Nothing matched: say why.
+		submitted, err := cfg.requireVersion(c, id, version)
+		if err != nil {
+			return nil, err
+		}
+		if state := cfg.lifecycle(submitted).State; state != models.VersionStateSubmitted {
+			return nil, &InvalidStateError{Type: cfg.typeName, ID: id, Version: version, State: state}
+		}
+		if err := cfg.checkClaim(c, id, version, reviewer, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%s %s: version %d could not be claimed", cfg.typeName, id, version)
+}
+
+// releaseClaim gives up reviewer's claim on a version. Releasing a claim reviewer doesn't hold is
+// a no-op, unless someone else holds it - then it's a *ClaimError, so a reviewer can't free
+// another's claim.
+func (cfg entityVersioningConfig[T]) releaseClaim(c context.Context, id string, version int, reviewer string) error {
+	filter := bson.D{{Key: "record_id", Value: id}, {Key: "version", Value: version}, {Key: claimedByField, Value: reviewer}}
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: claimedByField, Value: ""}, {Key: claimExpiresAtField, Value: ""}}}}
+	matched, err := Storage.UpdateOne(c, cfg.versionCollection, filter, update)
+	if err != nil || matched > 0 {
+		return err
+	}
+	if _, err := cfg.requireVersion(c, id, version); err != nil {
+		return err
+	}
+	return cfg.checkClaim(c, id, version, reviewer, time.Now())
+}
+
+// claimSource looks up entity's versioning engine for the claim calls below.
+func claimSource(entity string) (reviewQueueSource, error) {
+	source, ok := reviewQueueSources[entity]
+	if !ok {
+		return nil, fmt.Errorf("claim: %s versions can't be claimed", entity)
+	}
+	return source, nil
+}
+
+// ClaimVersion claims a submitted version of an entity ("volume", "publisher", "studio",
+// "person", "license" or "contribution") for reviewer, for duration (DefaultClaimDuration if
+// zero or less). Until the claim expires or is released, Accept*Version, PreviewAccept*Version
+// and Reject*Version by any other reviewer fail with a *ClaimError. Claiming a version reviewer
+// already holds extends the claim; one held by someone else is a *ClaimError, and one that isn't
+// submitted an *InvalidStateError.
+func ClaimVersion(c context.Context, entity, id string, version int, reviewer string, duration time.Duration) (*VersionClaim, error) {
+	_, span := otel.Tracer("review-queue").Start(c, "db-claim-version", oteltrace.WithAttributes(
+		attribute.String("entity", entity), attribute.String("id", id), attribute.Int("version", version)))
+	defer span.End()
+
+	source, err := claimSource(entity)
+	if err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		duration = DefaultClaimDuration
+	}
+	claim, err := source.claimVersion(c, id, version, reviewer, duration)
+	if err != nil {
+		logging.Logger.Info("Version not claimed", "entity", entity, "id", id, "version", version, "error", err)
+		return nil, err
+	}
+	return claim, nil
+}
+
+// ReleaseClaim gives up reviewer's claim on a version - see ClaimVersion.
+func ReleaseClaim(c context.Context, entity, id string, version int, reviewer string) error {
+	_, span := otel.Tracer("review-queue").Start(c, "db-release-claim", oteltrace.WithAttributes(
+		attribute.String("entity", entity), attribute.String("id", id), attribute.Int("version", version)))
+	defer span.End()
+
+	source, err := claimSource(entity)
+	if err != nil {
+		return err
+	}
+	return source.releaseClaim(c, id, version, reviewer)
+}
+
+// ListClaimedSubmissions returns the submitted versions reviewer holds unexpired claims on,
+// across every versioned type, oldest first - described like ListPendingSubmissions' items.
+func ListClaimedSubmissions(c context.Context, reviewer string) ([]*PendingSubmission, error) {
+	_, span := otel.Tracer("review-queue").Start(c, "db-list-claimed-submissions", oteltrace.WithAttributes(attribute.String("reviewer", reviewer)))
+	defer span.End()
+
+	claimed := bson.D{{Key: claimedByField, Value: reviewer}, {Key: claimExpiresAtField, Value: bson.D{{Key: "$gt", Value: time.Now()}}}}
+	items, err := listPending(c, claimed, SortPendingOldestFirst, 0, 0)
+	if err != nil {
+		logging.Logger.Error("Error while querying database for claimed submissions", "error", err)
+		return nil, err
+	}
+	return items, nil
+}
@@ -2,7 +2,9 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/sweetrpg/api-core.go/tracing"
@@ -10,16 +12,25 @@ import (
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
 	"github.com/sweetrpg/common.go/logging"
-	modelcore "github.com/sweetrpg/model-core.go/models"
 	modelcorevo "github.com/sweetrpg/model-core.go/vo"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
+// contributionPositionField is the contribution-document field holding a credit's place in its
+// volume's credit order (see ReorderContributions). Not on models.Contribution, so written through
+// contributionDocument.
+const contributionPositionField = "position"
+
+// contributionDocument is the stored form of a contribution: models.Contribution plus its
+// position in the volume's credits.
+type contributionDocument struct {
+	models.Contribution `bson:",inline"`
+	Position            int `bson:"position"`
+}
+
 // Get a single contribution.
 //
 //	  @Summary Get a contribution
@@ -28,7 +39,7 @@ import (
 //		 @Param id
 func GetContribution(c context.Context, id string) (*vo.ContributionVO, error) {
 	_, span := otel.Tracer("contribution").Start(c, "db-get-contribution", oteltrace.WithAttributes(attribute.String("id", id)))
-	results, err := database.Query[models.Contribution]("contributions", bson.D{{Key: "_id", Value: id}}, nil, nil, 0, 1)
+	results, err := storeQuery[models.Contribution](c, "contributions", bson.D{{Key: "_id", Value: id}}, nil, nil, 0, 1)
 	span.End()
 	if err != nil {
 		logging.Logger.Error("Error while querying database for Contribution", "error", err)
@@ -37,22 +48,40 @@ func GetContribution(c context.Context, id string) (*vo.ContributionVO, error) {
 
 	if len(results) == 0 {
 		logging.Logger.Info("Contribution not found for ID", "id", id)
-		return nil, nil
+		return nil, &NotFoundError{Type: "contribution", ID: id}
 	}
 
-	return contributionModelToVO(c, results[0]), nil
+	return contributionModelsToVOs(c, results)[0], nil
 }
 
-func contributionModelToVO(c context.Context, model *models.Contribution) *vo.ContributionVO {
-	personVO, err := GetPerson(c, model.PersonId)
-	if err != nil {
-		logging.Logger.Error(fmt.Sprintf("No Person found from Contribution for ID %s: %s", model.PersonId, err.Error()))
+// contributionModelsToVOs maps contributions to VOs, resolving every row's person and volume in
+// one batch (see resolveCurrent, loadVolumes) rather than a GetPerson and GetVolume per row.
+func contributionModelsToVOs(c context.Context, results []*models.Contribution) []*vo.ContributionVO {
+	personIDs := make([]string, 0, len(results))
+	volumeIDs := make([]string, 0, len(results))
+	for _, result := range results {
+		personIDs = append(personIDs, result.PersonId)
+		volumeIDs = append(volumeIDs, result.VolumeId)
 	}
-	volumeVO, err := GetVolume(c, model.VolumeId)
-	if err != nil {
-		logging.Logger.Error(fmt.Sprintf("No Volume found from Contribution for ID %s: %s", model.VolumeId, err.Error()))
+	persons := resolveCurrent(c, personVersioning, personIDs, flattenPerson)
+	volumes := loadVolumes(c, volumeIDs)
+
+	vos := make([]*vo.ContributionVO, 0, len(results))
+	for _, result := range results {
+		personVO, ok := persons[result.PersonId]
+		if !ok {
+			logging.Logger.Error(fmt.Sprintf("No Person found from Contribution for ID %s", result.PersonId))
+		}
+		volumeVO, ok := volumes[result.VolumeId]
+		if !ok {
+			logging.Logger.Error(fmt.Sprintf("No Volume found from Contribution for ID %s", result.VolumeId))
+		}
+		vos = append(vos, contributionModelToVO(result, personVO, volumeVO))
 	}
+	return vos
+}
 
+func contributionModelToVO(model *models.Contribution, personVO *vo.PersonVO, volumeVO *vo.VolumeVO) *vo.ContributionVO {
 	return &vo.ContributionVO{
 		ID:     model.ID,
 		Person: personVO,
@@ -78,75 +107,240 @@ func contributionModelToVO(c context.Context, model *models.Contribution) *vo.Co
 func QueryContributions(c context.Context, params apiutil.QueryParams) ([]*vo.ContributionVO, error) {
 	span := tracing.BuildSpanWithParams(c, "contributions", "db-get-contributions", params)
 	filter, sort, projection := apiutil.ConvertQueryParams(params)
-	models, err := database.Query[models.Contribution]("contributions", filter, sort, projection, params.Start, params.Limit)
+	models, err := storeQuery[models.Contribution](c, "contributions", filter, sort, projection, params.Start, params.Limit)
 	span.End()
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for Contributions: %v", err))
 		return nil, err
 	}
 
-	vos := make([]*vo.ContributionVO, 0, len(models))
-	for _, model := range models {
-		vos = append(vos, contributionModelToVO(c, model))
-	}
-
-	return vos, nil
+	return contributionModelsToVOs(c, models), nil
 }
 
-// QueryContributionsByVolume returns every contribution credited to volumeID.
+// QueryContributionsByVolume returns every contribution credited to volumeID, in credit order
+// (see ReorderContributions).
 func QueryContributionsByVolume(c context.Context, volumeID string) ([]*vo.ContributionVO, error) {
 	_, span := otel.Tracer("contribution").Start(c, "db-query-contributions-by-volume", oteltrace.WithAttributes(attribute.String("volumeId", volumeID)))
-	results, err := database.Query[models.Contribution]("contributions", bson.D{{Key: "volume_id", Value: volumeID}}, nil, nil, 0, 0)
+	results, err := storeQuery[models.Contribution](c, "contributions", bson.D{{Key: "volume_id", Value: volumeID}}, creditOrder, nil, 0, 0)
 	span.End()
 	if err != nil {
 		logging.Logger.Error("Error while querying database for Contributions by volume", "error", err)
 		return nil, err
 	}
 
-	vos := make([]*vo.ContributionVO, 0, len(results))
-	for _, model := range results {
-		vos = append(vos, contributionModelToVO(c, model))
+	return contributionModelsToVOs(c, results), nil
+}
+
+// QueryContributionsByPerson returns the contributions crediting personID, paged via params -
+// the reverse of QueryContributionsByVolume. Soft-deleted contributions are skipped, and a
+// soft-deleted person lists nothing; a missing person is a *NotFoundError.
+func QueryContributionsByPerson(c context.Context, personID string, params apiutil.QueryParams) ([]*vo.ContributionVO, error) {
+	span := tracing.BuildSpanWithParams(c, "contributions", "db-query-contributions-by-person", params)
+	defer span.End()
+
+	meta, err := personVersioning.requireMeta(c, personID)
+	if err != nil {
+		logging.Logger.Info("Contributions not listed for person", "personId", personID, "error", err)
+		return nil, err
+	}
+	if meta.DeletedAt != nil {
+		return []*vo.ContributionVO{}, nil
+	}
+
+	filter, sort, projection := apiutil.ConvertQueryParams(params)
+	filter = append(filter, bson.E{Key: "person_id", Value: personID}, bson.E{Key: "deleted_at", Value: nil})
+	results, err := storeQuery[models.Contribution](c, "contributions", filter, sort, projection, params.Start, params.Limit)
+	if err != nil {
+		logging.Logger.Error("Error while querying database for Contributions by person", "error", err)
+		return nil, err
 	}
-	return vos, nil
+
+	return contributionModelsToVOs(c, results), nil
 }
 
-// AddContribution creates a new person-to-volume credit and returns its ID.
+// creditOrder sorts a volume's contributions into credit order. Credits added before positions
+// existed all sit at 0 and fall back to the order they were added in.
+var creditOrder = bson.D{{Key: contributionPositionField, Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+
+// AddContribution creates a new person-to-volume credit, at the end of the volume's credit order,
+// and returns its ID. roles are normalized onto the role vocabulary (see
+// NormalizeContributionRoles); an unknown one is a *ValidationError. A person is credited once
+// per volume - a second credit is a *DuplicateError naming the first, whose roles UpdateContribution
+// can extend. The credit is an editor's write: its first version goes live directly (see
+// SubmitContribution for the reviewed path), in one transaction with its outbox events.
 func AddContribution(c context.Context, personID, volumeID string, roles []string, createdBy string) (*string, error) {
 	_, span := otel.Tracer("contribution").Start(c, "db-add-contribution", oteltrace.WithAttributes(
 		attribute.String("personId", personID), attribute.String("volumeId", volumeID)))
 	defer span.End()
 
-	now := time.Now()
-	model := models.Contribution{
-		ID:       primitive.NewObjectID().Hex(),
-		PersonId: personID,
-		VolumeId: volumeID,
-		Roles:    roles,
-		Auditable: modelcore.Auditable{
-			CreatedAt: now,
-			CreatedBy: createdBy,
-			UpdatedAt: now,
-			UpdatedBy: createdBy,
-		},
+	roles, err := NormalizeContributionRoles(roles)
+	if err != nil {
+		return nil, err
 	}
-
-	if _, err := database.Insert[models.Contribution]("contributions", model); err != nil {
+	var id *string
+	err = withTransaction(c, func(tc context.Context) error {
+		if err := checkNotCredited(tc, personID, volumeID); err != nil {
+			return err
+		}
+		version := ContributionVersion{PersonId: personID, VolumeId: volumeID, Roles: roles}
+		id, err = contributionVersioning.addEntityTx(tc, &version, createdBy)
+		return err
+	})
+	if err != nil {
 		logging.Logger.Error("Error while inserting Contribution", "error", err)
 		return nil, err
 	}
-	return &model.ID, nil
+	return id, nil
 }
 
-// DeleteContribution removes a person-to-volume credit by ID. Returns false if no matching
-// document existed.
-func DeleteContribution(c context.Context, id string) (bool, error) {
+// UpdateContribution replaces a contribution's roles, normalized as for AddContribution, as a new
+// live version, and returns the updated contribution. A missing or removed contribution is a
+// *NotFoundError. SubmitContributionUpdate is the reviewed path.
+func UpdateContribution(c context.Context, id string, roles []string, updatedBy string) (*vo.ContributionVO, error) {
+	_, span := otel.Tracer("contribution").Start(c, "db-update-contribution", oteltrace.WithAttributes(attribute.String("id", id)))
+	defer span.End()
+
+	roles, err := NormalizeContributionRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	if err := liveCreditChange(c, id, updatedBy, func(v *ContributionVersion) { v.Roles = roles }); err != nil {
+		logging.Logger.Info("Contribution not updated", "id", id, "error", err)
+		return nil, err
+	}
+	return GetContribution(c, id)
+}
+
+// liveCreditChange makes credit id's live version with change applied its new live version.
+func liveCreditChange(c context.Context, id, actor string, change func(*ContributionVersion)) error {
+	return withTransaction(c, func(tc context.Context) error {
+		next, err := currentCredit(tc, id)
+		if err != nil {
+			return err
+		}
+		change(next)
+		_, err = contributionVersioning.createVersionTx(tc, id, next, models.VersionStateLive, actor, time.Now(), nil)
+		return err
+	})
+}
+
+// ReorderContributions sets volumeID's credit order to contributionIDs, which must list each of
+// the volume's contributions exactly once - anything else is a *ValidationError, so a reorder
+// racing an add or delete fails rather than silently misplacing a credit.
+func ReorderContributions(c context.Context, volumeID string, contributionIDs []string, updatedBy string) error {
+	_, span := otel.Tracer("contribution").Start(c, "db-reorder-contributions", oteltrace.WithAttributes(attribute.String("volumeId", volumeID)))
+	defer span.End()
+
+	err := withTransaction(c, func(tc context.Context) error {
+		credits, err := storeQuery[contributionDocument](tc, "contributions", bson.D{{Key: "volume_id", Value: volumeID}}, nil, nil, 0, 0)
+		if err != nil {
+			return err
+		}
+		positions := make(map[string]int, len(credits))
+		for _, credit := range credits {
+			positions[credit.ID] = credit.Position
+		}
+		if len(contributionIDs) != len(credits) {
+			return &ValidationError{Type: "contribution", Field: "order",
+				Reason: fmt.Sprintf("volume %s has %d contributions, %d given", volumeID, len(credits), len(contributionIDs))}
+		}
+
+		now := time.Now()
+		placed := make(map[string]bool, len(contributionIDs))
+		for position, id := range contributionIDs {
+			current, ok := positions[id]
+			if !ok || placed[id] {
+				return &ValidationError{Type: "contribution", Field: "order",
+					Reason: fmt.Sprintf("contribution %s is not on volume %s or is listed twice", id, volumeID)}
+			}
+			placed[id] = true
+			if current == position {
+				continue
+			}
+			_, err := Storage.UpdateOne(tc, "contributions", bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
+				{Key: contributionPositionField, Value: position},
+				{Key: "updated_at", Value: now},
+				{Key: "updated_by", Value: updatedBy},
+			}}})
+			if err != nil {
+				return err
+			}
+			if err := appendEvent(tc, Event{Type: EventContributionUpdated, Entity: "contribution", RecordID: id, Actor: updatedBy}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Info("Contributions not reordered", "volumeId", volumeID, "error", err)
+	}
+	return err
+}
+
+// DeleteContribution takes a person-to-volume credit off its volume, as a new live version
+// marked removed - the credit's history stays (see SubmitContributionRemoval for the reviewed
+// path). Returns false if there was no such credit, or it was already removed.
+func DeleteContribution(c context.Context, id string, deletedBy string) (bool, error) {
 	_, span := otel.Tracer("contribution").Start(c, "db-delete-contribution", oteltrace.WithAttributes(attribute.String("id", id)))
 	defer span.End()
 
-	result, err := database.Db.Collection("contributions").DeleteOne(c, bson.D{{Key: "_id", Value: id}})
+	err := liveCreditChange(c, id, deletedBy, func(v *ContributionVersion) { v.Removed = true })
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
 	if err != nil {
 		logging.Logger.Error("Error while deleting Contribution", "error", err)
 		return false, err
 	}
-	return result.DeletedCount > 0, nil
+	return true, nil
+}
+
+// foldContributions moves loserID's credits onto survivorID for MergeRecords. Where both people
+// were credited on the same volume, the survivor's credit takes on the loser's roles and keeps
+// its own place in the credit order, and the loser's credit is removed - both as new live
+// versions by mergedBy. Every other version naming the loser, pending submissions included, is
+// repointed at the survivor.
+func foldContributions(c context.Context, survivorID, loserID, mergedBy string) error {
+	credits, err := storeQuery[contributionDocument](c, "contributions", bson.D{{Key: "person_id", Value: loserID}}, nil, nil, 0, 0)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, credit := range credits {
+		filter := bson.D{{Key: "volume_id", Value: credit.VolumeId}, {Key: "person_id", Value: survivorID}}
+		existing, err := storeQuery[contributionDocument](c, "contributions", filter, nil, nil, 0, 1)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			continue
+		}
+		kept, err := currentCredit(c, existing[0].ID)
+		if err != nil {
+			return err
+		}
+		for _, role := range credit.Roles {
+			if !slices.Contains(kept.Roles, role) {
+				kept.Roles = append(kept.Roles, role)
+			}
+		}
+		if _, err := contributionVersioning.createVersionTx(c, existing[0].ID, kept, models.VersionStateLive, mergedBy, now, nil); err != nil {
+			return err
+		}
+		removed, err := currentCredit(c, credit.ID)
+		if err != nil {
+			return err
+		}
+		removed.Removed = true
+		if _, err := contributionVersioning.createVersionTx(c, credit.ID, removed, models.VersionStateLive, mergedBy, now, nil); err != nil {
+			return err
+		}
+	}
+
+	repoint := bson.D{{Key: "$set", Value: bson.D{{Key: "person_id", Value: survivorID}}}}
+	if _, err := Storage.UpdateMany(c, contributionVersionCollection, bson.D{{Key: "person_id", Value: loserID}}, repoint); err != nil {
+		return err
+	}
+	_, err = Storage.UpdateMany(c, "contributions", bson.D{{Key: "person_id", Value: loserID}}, repoint)
+	return err
 }
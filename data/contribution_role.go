@@ -0,0 +1,105 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContributionRole is one entry in the controlled vocabulary of credits a contribution can carry.
+type ContributionRole string
+
+const (
+	RoleWriter        ContributionRole = "writer"
+	RoleDesigner      ContributionRole = "designer"
+	RoleDeveloper     ContributionRole = "developer"
+	RoleEditor        ContributionRole = "editor"
+	RoleProofreader   ContributionRole = "proofreader"
+	RoleArtist        ContributionRole = "artist"
+	RoleCoverArtist   ContributionRole = "cover_artist"
+	RoleArtDirector   ContributionRole = "art_director"
+	RoleCartographer  ContributionRole = "cartographer"
+	RoleLayout        ContributionRole = "layout"
+	RoleGraphicDesign ContributionRole = "graphic_design"
+	RoleProducer      ContributionRole = "producer"
+	RolePlaytester    ContributionRole = "playtester"
+	RoleTranslator    ContributionRole = "translator"
+)
+
+// ContributionRoles is the vocabulary, in the order it's offered to editors.
+var ContributionRoles = []ContributionRole{
+	RoleWriter, RoleDesigner, RoleDeveloper, RoleEditor, RoleProofreader, RoleArtist, RoleCoverArtist,
+	RoleArtDirector, RoleCartographer, RoleLayout, RoleGraphicDesign, RoleProducer, RolePlaytester,
+	RoleTranslator,
+}
+
+// contributionRoleAliases maps the other names credits commonly use, keyed the way roleKey
+// folds them, onto the vocabulary.
+var contributionRoleAliases = map[string]ContributionRole{
+	"author":           RoleWriter,
+	"writing":          RoleWriter,
+	"game_designer":    RoleDesigner,
+	"design":           RoleDesigner,
+	"game_design":      RoleDesigner,
+	"development":      RoleDeveloper,
+	"line_developer":   RoleDeveloper,
+	"editing":          RoleEditor,
+	"copy_editor":      RoleEditor,
+	"proofreading":     RoleProofreader,
+	"illustrator":      RoleArtist,
+	"illustration":     RoleArtist,
+	"interior_artist":  RoleArtist,
+	"interior_art":     RoleArtist,
+	"cover_art":        RoleCoverArtist,
+	"cover":            RoleCoverArtist,
+	"art_direction":    RoleArtDirector,
+	"cartography":      RoleCartographer,
+	"maps":             RoleCartographer,
+	"layout_artist":    RoleLayout,
+	"typesetting":      RoleLayout,
+	"graphic_designer": RoleGraphicDesign,
+	"graphics":         RoleGraphicDesign,
+	"production":       RoleProducer,
+	"playtesting":      RolePlaytester,
+	"translation":      RoleTranslator,
+}
+
+// roleKey folds a role as typed ("Cover Artist", "cover-artist") to the form the vocabulary and
+// its aliases are keyed by ("cover_artist").
+func roleKey(role string) string {
+	return strings.ReplaceAll(NormalizeName(role), " ", "_")
+}
+
+// NormalizeContributionRoles maps roles onto the vocabulary - case, punctuation and known aliases
+// ("Author", "Illustrator", "Maps") don't matter - dropping repeats and keeping the first-seen
+// order. An unknown role, or no roles at all, is a *ValidationError.
+func NormalizeContributionRoles(roles []string) ([]string, error) {
+	normalized := make([]string, 0, len(roles))
+	seen := map[ContributionRole]bool{}
+	for _, role := range roles {
+		key := roleKey(role)
+		canonical, ok := contributionRoleAliases[key]
+		if !ok {
+			canonical = ContributionRole(key)
+			if !isContributionRole(canonical) {
+				return nil, &ValidationError{Type: "contribution", Field: "roles", Reason: fmt.Sprintf("unknown role %q", role)}
+			}
+		}
+		if !seen[canonical] {
+			seen[canonical] = true
+			normalized = append(normalized, string(canonical))
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, &ValidationError{Type: "contribution", Field: "roles", Reason: "at least one role is required"}
+	}
+	return normalized, nil
+}
+
+func isContributionRole(role ContributionRole) bool {
+	for _, known := range ContributionRoles {
+		if role == known {
+			return true
+		}
+	}
+	return false
+}
@@ -1,13 +1,18 @@
 package data
 
 import (
+	"time"
+
 	"github.com/stretchr/testify/assert"
+	apiutil "github.com/sweetrpg/api-core.go/util"
+	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // These live as methods on VolumeDataTestSuite (defined in volume_test.go) rather than
-// standalone Test* funcs so they pick up SetupTest's logging.Init()/database.SetupDatabase()
-// call - this package has no package-level TestMain of its own.
+// standalone Test* funcs so they pick up SetupTest's setupTestStorage() call - this package has
+// no package-level TestMain of its own.
 
 func (suite *VolumeDataTestSuite) TestAddContributionAndQueryByVolume() {
 	ctx := suite.T().Context()
@@ -26,7 +31,7 @@ func (suite *VolumeDataTestSuite) TestAddContributionAndQueryByVolume() {
 	assert.Len(suite.T(), contributions, 1)
 	assert.NotNil(suite.T(), contributions[0].Person)
 	assert.Equal(suite.T(), *personID, contributions[0].Person.ID)
-	assert.Equal(suite.T(), []string{"Author"}, contributions[0].Roles)
+	assert.Equal(suite.T(), []string{"writer"}, contributions[0].Roles)
 
 	got, err := GetContribution(ctx, *id)
 	assert.NoError(suite.T(), err)
@@ -44,15 +49,257 @@ func (suite *VolumeDataTestSuite) TestDeleteContribution() {
 	id, err := AddContribution(ctx, *personID, suite.seedVolumeID, []string{"Editor"}, "auth0|editor")
 	assert.NoError(suite.T(), err)
 
-	deleted, err := DeleteContribution(ctx, *id)
+	deleted, err := DeleteContribution(ctx, *id, "editor-1")
 	assert.NoError(suite.T(), err)
 	assert.True(suite.T(), deleted)
 
 	got, err := GetContribution(ctx, *id)
-	assert.NoError(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
 	assert.Nil(suite.T(), got)
 
-	deletedAgain, err := DeleteContribution(ctx, *id)
+	deletedAgain, err := DeleteContribution(ctx, *id, "editor-1")
 	assert.NoError(suite.T(), err)
 	assert.False(suite.T(), deletedAgain)
 }
+
+func (suite *VolumeDataTestSuite) TestQueryContributionsByPerson() {
+	ctx := suite.T().Context()
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Prolific Author"})
+	assert.NoError(suite.T(), err)
+	otherID, err := AddPerson(ctx, &vo.PersonVO{Name: "Someone Else"})
+	assert.NoError(suite.T(), err)
+	id, err := AddContribution(ctx, *personID, suite.seedVolumeID, []string{"Author"}, "auth0|editor")
+	assert.NoError(suite.T(), err)
+	_, err = AddContribution(ctx, *otherID, suite.seedVolumeID, []string{"Editor"}, "auth0|editor")
+	assert.NoError(suite.T(), err)
+
+	contributions, err := QueryContributionsByPerson(ctx, *personID, apiutil.QueryParams{Limit: 10})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), contributions, 1) {
+		assert.Equal(suite.T(), *id, contributions[0].ID)
+	}
+
+	assert.NoError(suite.T(), SoftDeletePerson(ctx, *personID, "admin-1"))
+	contributions, err = QueryContributionsByPerson(ctx, *personID, apiutil.QueryParams{Limit: 10})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), contributions)
+
+	_, err = QueryContributionsByPerson(ctx, "no-such-person", apiutil.QueryParams{Limit: 10})
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+}
+
+func (suite *VolumeDataTestSuite) TestNormalizeContributionRoles() {
+	roles, err := NormalizeContributionRoles([]string{"Author", "Cover Artist", "cover-art", "MAPS", "writer"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"writer", "cover_artist", "cartographer"}, roles)
+
+	_, err = NormalizeContributionRoles([]string{"writer", "caterer"})
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+	_, err = NormalizeContributionRoles(nil)
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+}
+
+func (suite *VolumeDataTestSuite) TestUpdateContributionKeepsCreditAudit() {
+	ctx := suite.T().Context()
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Versatile Creator"})
+	assert.NoError(suite.T(), err)
+	id, err := AddContribution(ctx, *personID, suite.seedVolumeID, []string{"writer"}, "auth0|editor")
+	assert.NoError(suite.T(), err)
+
+	updated, err := UpdateContribution(ctx, *id, []string{"Writer", "Illustrator"}, "auth0|other-editor")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"writer", "artist"}, updated.Roles)
+	assert.Equal(suite.T(), "auth0|editor", updated.CreatedBy)
+	assert.Equal(suite.T(), "auth0|other-editor", updated.UpdatedBy)
+
+	_, err = UpdateContribution(ctx, *id, []string{"caterer"}, "auth0|editor")
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+	_, err = UpdateContribution(ctx, "no-such-contribution", []string{"writer"}, "auth0|editor")
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+
+	// The person already has their credit on this volume.
+	_, err = AddContribution(ctx, *personID, suite.seedVolumeID, []string{"editor"}, "auth0|editor")
+	var duplicate *DuplicateError
+	if assert.ErrorAs(suite.T(), err, &duplicate) {
+		assert.Equal(suite.T(), *id, duplicate.ExistingID)
+	}
+}
+
+func (suite *VolumeDataTestSuite) TestContributionsListInCreditOrder() {
+	ctx := suite.T().Context()
+	var ids []string
+	for _, name := range []string{"First Added", "Second Added", "Third Added"} {
+		personID, err := AddPerson(ctx, &vo.PersonVO{Name: name})
+		assert.NoError(suite.T(), err)
+		id, err := AddContribution(ctx, *personID, suite.seedVolumeID, []string{"writer"}, "auth0|editor")
+		assert.NoError(suite.T(), err)
+		ids = append(ids, *id)
+	}
+	listed := func() []string {
+		contributions, err := QueryContributionsByVolume(ctx, suite.seedVolumeID)
+		assert.NoError(suite.T(), err)
+		order := make([]string, 0, len(contributions))
+		for _, contribution := range contributions {
+			order = append(order, contribution.ID)
+		}
+		return order
+	}
+	assert.Equal(suite.T(), ids, listed())
+
+	reordered := []string{ids[2], ids[0], ids[1]}
+	assert.NoError(suite.T(), ReorderContributions(ctx, suite.seedVolumeID, reordered, "auth0|editor"))
+	assert.Equal(suite.T(), reordered, listed())
+
+	err := ReorderContributions(ctx, suite.seedVolumeID, []string{ids[0], ids[1]}, "auth0|editor")
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+	err = ReorderContributions(ctx, suite.seedVolumeID, []string{ids[0], ids[0], ids[1]}, "auth0|editor")
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+	assert.Equal(suite.T(), reordered, listed())
+}
+
+func (suite *VolumeDataTestSuite) TestSubmitContributionAppearsOnlyOnceAccepted() {
+	ctx := suite.T().Context()
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Submitted Author"})
+	assert.NoError(suite.T(), err)
+	submitted, err := SubmitContribution(ctx, *personID, suite.seedVolumeID, []string{"Illustrator"}, "user-1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, submitted.Version)
+	assert.Equal(suite.T(), []string{"artist"}, submitted.Roles)
+
+	_, err = GetContribution(ctx, submitted.RecordID)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+
+	accepted, _, err := AcceptContributionVersion(ctx, submitted.RecordID, submitted.Version, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.VersionStateLive, models.VersionState(accepted.State))
+	got, err := GetContribution(ctx, submitted.RecordID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"artist"}, got.Roles)
+
+	_, err = SubmitContribution(ctx, *personID, suite.seedVolumeID, []string{"writer"}, "user-2")
+	assert.ErrorIs(suite.T(), err, ErrDuplicate)
+}
+
+func (suite *VolumeDataTestSuite) TestAbandonedContributionProposalLeavesNoRecord() {
+	defer func(delay time.Duration) { EventSettleDelay = delay }(EventSettleDelay)
+	EventSettleDelay = 0
+	ctx := suite.T().Context()
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Proposed Author"})
+	assert.NoError(suite.T(), err)
+	rejected, err := SubmitContribution(ctx, *personID, suite.seedVolumeID, []string{"writer"}, "user-1")
+	assert.NoError(suite.T(), err)
+	_, err = SubmitContribution(ctx, *personID, suite.seedVolumeID, []string{"editor"}, "user-2")
+	assert.ErrorIs(suite.T(), err, ErrDuplicate)
+
+	assert.NoError(suite.T(), RejectContributionVersion(ctx, rejected.RecordID, rejected.Version, "editor-1", nil))
+	versions, err := ListContributionVersions(ctx, rejected.RecordID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), versions)
+
+	retracted, err := SubmitContribution(ctx, *personID, suite.seedVolumeID, []string{"editor"}, "user-2")
+	assert.NoError(suite.T(), err)
+	_, err = RetractContributionVersion(ctx, retracted.RecordID, retracted.Version, "user-2")
+	assert.NoError(suite.T(), err)
+	versions, err = ListContributionVersions(ctx, retracted.RecordID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), versions)
+
+	// Only the proposal that goes live is announced as a new record.
+	accepted, err := SubmitContribution(ctx, *personID, suite.seedVolumeID, []string{"artist"}, "user-3")
+	assert.NoError(suite.T(), err)
+	_, _, err = AcceptContributionVersion(ctx, accepted.RecordID, accepted.Version, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	events, err := ReadEvents(ctx, EventPosition{}, 0)
+	assert.NoError(suite.T(), err)
+	var created []string
+	for _, event := range events {
+		if event.Type == EventRecordCreated && event.Entity == "contribution" {
+			created = append(created, event.RecordID)
+		}
+	}
+	assert.Equal(suite.T(), []string{accepted.RecordID}, created)
+}
+
+func (suite *VolumeDataTestSuite) TestPreVersioningCreditMigratesOnFirstWrite() {
+	ctx := suite.T().Context()
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Legacy Author"})
+	assert.NoError(suite.T(), err)
+	legacy := contributionDocument{Contribution: models.Contribution{
+		ID: primitive.NewObjectID().Hex(), PersonId: *personID, VolumeId: suite.seedVolumeID, Roles: []string{"writer"},
+	}}
+	assert.NoError(suite.T(), storeInsert(ctx, "contributions", legacy))
+
+	updated, err := UpdateContribution(ctx, legacy.ID, []string{"writer", "editor"}, "editor-1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"writer", "editor"}, updated.Roles)
+	versions, err := ListContributionVersions(ctx, legacy.ID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), versions, 2) {
+		assert.Equal(suite.T(), []string{"writer"}, versions[1].Roles)
+	}
+
+	deleted, err := DeleteContribution(ctx, legacy.ID, "editor-1")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), deleted)
+	migrated, err := MigrateContributions(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, migrated)
+}
+
+func (suite *VolumeDataTestSuite) TestRejectedContributionSubmissionChangesNothing() {
+	ctx := suite.T().Context()
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Rejected Author"})
+	assert.NoError(suite.T(), err)
+	id, err := AddContribution(ctx, *personID, suite.seedVolumeID, []string{"writer"}, "editor-1")
+	assert.NoError(suite.T(), err)
+
+	update, err := SubmitContributionUpdate(ctx, *id, []string{"editor"}, "user-1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), RejectContributionVersion(ctx, *id, update.Version, "editor-1", nil))
+	got, err := GetContribution(ctx, *id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"writer"}, got.Roles)
+
+	_, err = SubmitContributionUpdate(ctx, *id, []string{"Juggler"}, "user-1")
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+}
+
+func (suite *VolumeDataTestSuite) TestContributionRemovalIsReviewedAndKeepsHistory() {
+	ctx := suite.T().Context()
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Removed Author"})
+	assert.NoError(suite.T(), err)
+	id, err := AddContribution(ctx, *personID, suite.seedVolumeID, []string{"writer"}, "editor-1")
+	assert.NoError(suite.T(), err)
+	_, err = UpdateContribution(ctx, *id, []string{"writer", "editor"}, "editor-1")
+	assert.NoError(suite.T(), err)
+
+	removal, err := SubmitContributionRemoval(ctx, *id, "user-1")
+	assert.NoError(suite.T(), err)
+	_, err = GetContribution(ctx, *id)
+	assert.NoError(suite.T(), err)
+	_, _, err = AcceptContributionVersion(ctx, *id, removal.Version, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	_, err = GetContribution(ctx, *id)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+
+	versions, err := ListContributionVersions(ctx, *id)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), versions, 3) {
+		assert.True(suite.T(), versions[0].Removed)
+		assert.Equal(suite.T(), []string{"writer", "editor"}, versions[1].Roles)
+		assert.Equal(suite.T(), []string{"writer"}, versions[2].Roles)
+	}
+
+	// Rolling the removal back puts the credit back on the volume.
+	_, err = SetCurrentContributionVersion(ctx, *id, 2, nil)
+	assert.NoError(suite.T(), err)
+	got, err := GetContribution(ctx, *id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"writer", "editor"}, got.Roles)
+}
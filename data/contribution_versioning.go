@@ -0,0 +1,531 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"github.com/sweetrpg/common.go/logging"
+	modelcore "github.com/sweetrpg/model-core.go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	contributionMetaCollection    = "contributions_meta"
+	contributionVersionCollection = "contributions_versions"
+)
+
+// ContributionVersion is one version of a person-to-volume credit. catalog-objects has no
+// contribution version model, so it lives here. A credit's person and volume never change
+// between its versions; what a version changes is Roles, or Removed - a version with Removed set
+// takes the credit off the volume when it goes live, which is how a removal is submitted and
+// reviewed like any other change.
+type ContributionVersion struct {
+	ID                      string   `bson:"_id"`
+	RecordID                string   `bson:"record_id"`
+	Version                 int      `bson:"version"`
+	PersonId                string   `bson:"person_id"`
+	VolumeId                string   `bson:"volume_id"`
+	Roles                   []string `bson:"roles"`
+	Removed                 bool     `bson:"removed"`
+	models.VersionLifecycle `bson:",inline"`
+}
+
+// ContributionVersionVO is the API shape of a ContributionVersion.
+type ContributionVersionVO struct {
+	ID       string   `json:"id"`
+	RecordID string   `json:"recordId"`
+	Version  int      `json:"version"`
+	PersonID string   `json:"personId"`
+	VolumeID string   `json:"volumeId"`
+	Roles    []string `json:"roles"`
+	Removed  bool     `json:"removed"`
+	vo.VersionLifecycleVO
+}
+
+// ContributionApprovalPolicy is VolumeApprovalPolicy for contributions.
+var ContributionApprovalPolicy ApprovalPolicy
+
+// ContributionSubmissionCap is VolumeSubmissionCap for contributions.
+var ContributionSubmissionCap SubmissionCap
+
+// contributionVersioning drives credits through the same submit/review engine as every other
+// type. The "contributions" collection every read uses stays as the projection of each credit's
+// live version, kept in step by syncContribution; a purge of the credited volume or person
+// purges the credit records naming it too (see purgeCredits).
+var contributionVersioning = entityVersioningConfig[ContributionVersion]{
+	metaCollection:    contributionMetaCollection,
+	versionCollection: contributionVersionCollection,
+	typeName:          "contribution",
+	lifecycle:         func(v *ContributionVersion) models.VersionLifecycle { return v.VersionLifecycle },
+	setLifecycle:      func(v *ContributionVersion, lc models.VersionLifecycle) { v.VersionLifecycle = lc },
+	setID:             func(v *ContributionVersion, id string) { v.ID = id },
+	setRecordID:       func(v *ContributionVersion, id string) { v.RecordID = id },
+	setVersion:        func(v *ContributionVersion, n int) { v.Version = n },
+	version:           func(v *ContributionVersion) int { return v.Version },
+	recordID:          func(v *ContributionVersion) string { return v.RecordID },
+	// A credit has no name of its own.
+	displayName: func(v *ContributionVersion) string { return "" },
+	fields: map[string]entityFieldAccessor[ContributionVersion]{
+		"roles":   {get: func(v *ContributionVersion) any { return v.Roles }, set: func(v *ContributionVersion, val any) { v.Roles = val.([]string) }, setMerge: true},
+		"removed": {get: func(v *ContributionVersion) any { return v.Removed }, set: func(v *ContributionVersion, val any) { v.Removed = val.(bool) }},
+	},
+	onLive:         syncContribution,
+	onDropped:      dropAbandonedProposal,
+	approvalPolicy: &ContributionApprovalPolicy,
+	submissionCap:  &ContributionSubmissionCap,
+}
+
+// EnsureContributionVersioningIndexes creates the indexes contribution queries rely on: the
+// version collection's, plus on the "contributions" projection a unique (volume_id, person_id)
+// index backing the one-credit-per-person-per-volume rule - which fails to build while a database
+// still holds duplicate credits, so merge those (UpdateContribution, DeleteContribution) first -
+// and a person_id index for QueryContributionsByPerson. Safe to call on every startup.
+func EnsureContributionVersioningIndexes(c context.Context) error {
+	if err := contributionVersioning.ensureIndexes(c); err != nil {
+		return err
+	}
+	credit := bson.D{{Key: "volume_id", Value: 1}, {Key: "person_id", Value: 1}}
+	if err := Storage.EnsureIndex(c, "contributions", credit, true); err != nil {
+		return fmt.Errorf("contribution: create volume_id+person_id index: %w", err)
+	}
+	if err := Storage.EnsureIndex(c, "contributions", bson.D{{Key: "person_id", Value: 1}}, false); err != nil {
+		return fmt.Errorf("contribution: create person_id index: %w", err)
+	}
+	return nil
+}
+
+func contributionVersionToVO(version *ContributionVersion) *ContributionVersionVO {
+	return &ContributionVersionVO{
+		ID: version.ID, RecordID: version.RecordID, Version: version.Version,
+		PersonID: version.PersonId, VolumeID: version.VolumeId, Roles: version.Roles, Removed: version.Removed,
+		VersionLifecycleVO: vo.VersionLifecycleVO{
+			State: vo.VersionState(version.State), BaseVersion: version.BaseVersion,
+			SubmittedBy: version.SubmittedBy, SubmittedAt: version.SubmittedAt,
+			ReviewedBy: version.ReviewedBy, ReviewedAt: version.ReviewedAt,
+			ReviewNote: version.ReviewNote, ResultingVersion: version.ResultingVersion,
+		},
+	}
+}
+
+// syncContribution is contributionVersioning's onLive: it makes the "contributions" projection
+// match credit id's new live version - inserting the credit at the end of the volume's credit
+// order, updating its roles, or deleting it for a removed version - and appends the matching
+// contribution_* event - plus the record_created SubmitContribution held back, the first time a
+// proposal puts its credit on the volume. A credit for a person already credited on the volume by
+// another record is a *DuplicateError, failing whichever write tried to make it live.
+func syncContribution(c context.Context, id string, live *ContributionVersion, deleted bool) error {
+	actor := live.SubmittedBy
+	if live.ReviewedBy != nil {
+		actor = *live.ReviewedBy
+	}
+	if live.Removed || deleted {
+		removed, err := Storage.DeleteOne(c, "contributions", bson.D{{Key: "_id", Value: id}})
+		if err != nil || removed == 0 {
+			return err
+		}
+		return appendEvent(c, Event{Type: EventContributionDeleted, Entity: "contribution", RecordID: id, Actor: actor})
+	}
+
+	now := time.Now()
+	matched, err := Storage.UpdateOne(c, "contributions", bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "person_id", Value: live.PersonId},
+		{Key: "volume_id", Value: live.VolumeId},
+		{Key: "roles", Value: live.Roles},
+		{Key: "updated_at", Value: now},
+		{Key: "updated_by", Value: actor},
+	}}})
+	if err != nil {
+		return err
+	}
+	if matched > 0 {
+		return appendEvent(c, Event{Type: EventContributionUpdated, Entity: "contribution", RecordID: id, Actor: actor})
+	}
+
+	credits, err := storeQuery[contributionDocument](c, "contributions", bson.D{{Key: "volume_id", Value: live.VolumeId}}, nil, nil, 0, 0)
+	if err != nil {
+		return err
+	}
+	credit := contributionDocument{
+		Contribution: models.Contribution{
+			ID: id, PersonId: live.PersonId, VolumeId: live.VolumeId, Roles: live.Roles,
+			Auditable: modelcore.Auditable{CreatedAt: now, CreatedBy: actor, UpdatedAt: now, UpdatedBy: actor},
+		},
+	}
+	for _, other := range credits {
+		if other.PersonId == live.PersonId {
+			return duplicateCredit(live.PersonId, live.VolumeId, other.ID)
+		}
+		credit.Position = max(credit.Position, other.Position+1)
+	}
+	if err := storeInsert(c, "contributions", credit); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// Lost a race with another credit for the same person and volume going live.
+			return duplicateCredit(live.PersonId, live.VolumeId, "")
+		}
+		return err
+	}
+	if err := appendEvent(c, Event{Type: EventContributionAdded, Entity: "contribution", RecordID: id, Actor: actor}); err != nil {
+		return err
+	}
+	return announceProposal(c, id, live, actor)
+}
+
+// announceProposal appends record id's record_created event if live is the first of its versions
+// to credit anyone - an accepted SubmitContribution proposal, past its placeholder. A credit
+// AddContribution made went live as version 1, announced by addEntityTx.
+func announceProposal(c context.Context, id string, live *ContributionVersion, actor string) error {
+	if live.Version == 1 {
+		return nil
+	}
+	filter := bson.D{
+		{Key: "record_id", Value: id},
+		{Key: "removed", Value: false},
+		{Key: "state", Value: bson.D{{Key: "$in", Value: bson.A{string(models.VersionStateLive), string(models.VersionStateArchived)}}}},
+	}
+	credited, err := Storage.Count(c, contributionVersionCollection, filter)
+	if err != nil || credited > 1 {
+		return err
+	}
+	version := live.Version
+	return appendEvent(c, Event{
+		Type: EventRecordCreated, Entity: "contribution", RecordID: id, Version: version,
+		State: models.VersionStateLive, CurrentVersion: &version, Actor: actor,
+	})
+}
+
+func duplicateCredit(personID, volumeID, existingID string) error {
+	reason := fmt.Sprintf("person %s is already credited on volume %s", personID, volumeID)
+	if existingID != "" {
+		reason += fmt.Sprintf(" (contribution %s)", existingID)
+	}
+	return &DuplicateError{Type: "contribution", ExistingID: existingID, Reason: reason}
+}
+
+// checkNotCredited returns a *DuplicateError if personID already has a live credit on volumeID.
+func checkNotCredited(c context.Context, personID, volumeID string) error {
+	filter := bson.D{{Key: "volume_id", Value: volumeID}, {Key: "person_id", Value: personID}}
+	existing, err := storeQuery[contributionDocument](c, "contributions", filter, nil, nil, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return duplicateCredit(personID, volumeID, existing[0].ID)
+	}
+	return nil
+}
+
+// purgeCredits purges every credit record naming id in field ("volume_id" or "person_id") -
+// meta record and versions, pending submissions included - for the onPurge of the volume and
+// person configs, so nothing left behind can put the credit back through syncContribution. A
+// CascadeOrphan purge leaves them, as it leaves the projected credits.
+func purgeCredits(c context.Context, field, id string, policy CascadePolicy) error {
+	if policy == CascadeOrphan {
+		return nil
+	}
+	projection := bson.D{{Key: "_id", Value: 1}, {Key: "record_id", Value: 1}}
+	versions, err := storeQuery[dependencyDocument](c, contributionVersionCollection, bson.D{{Key: field, Value: id}}, nil, projection, 0, 0)
+	if err != nil {
+		return err
+	}
+	purged := map[string]bool{}
+	for _, version := range versions {
+		if purged[version.RecordID] {
+			continue
+		}
+		purged[version.RecordID] = true
+		if err := contributionVersioning.purgeTx(c, version.RecordID, CascadeDelete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgePersonCredits is the person config's onPurge.
+func purgePersonCredits(c context.Context, personID string, policy CascadePolicy) error {
+	return purgeCredits(c, "person_id", personID, policy)
+}
+
+// checkNotProposed returns a *DuplicateError if personID already has a SubmitContribution
+// proposal pending on volumeID - one whose record is still just its placeholder.
+func checkNotProposed(c context.Context, personID, volumeID string) error {
+	filter := bson.D{
+		{Key: "volume_id", Value: volumeID},
+		{Key: "person_id", Value: personID},
+		{Key: "state", Value: string(models.VersionStateSubmitted)},
+	}
+	pending, err := storeQuery[ContributionVersion](c, contributionVersionCollection, filter, nil, nil, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, proposal := range pending {
+		first, err := contributionVersioning.getVersion(c, proposal.RecordID, 1)
+		if err != nil {
+			return err
+		}
+		if first != nil && first.Removed {
+			reason := fmt.Sprintf("person %s already has a credit on volume %s pending review (contribution %s)", personID, volumeID, proposal.RecordID)
+			return &DuplicateError{Type: "contribution", ExistingID: proposal.RecordID, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// addProposalTx creates the record a SubmitContribution proposal is submitted against: a meta
+// record whose only version is a live, removed placeholder. Unlike addEntityTx it announces
+// nothing - the credit doesn't exist until a proposal on it goes live (see announceProposal).
+func addProposalTx(c context.Context, personID, volumeID, submittedBy string) (string, error) {
+	now := time.Now()
+	id := primitive.NewObjectID().Hex()
+	meta := models.EntityMeta{ID: id, CurrentVersion: 1, CreatedAt: now, CreatedBy: submittedBy}
+	if err := storeInsert(c, contributionMetaCollection, meta); err != nil {
+		return "", err
+	}
+	placeholder := ContributionVersion{
+		ID: primitive.NewObjectID().Hex(), RecordID: id, Version: 1,
+		PersonId: personID, VolumeId: volumeID, Removed: true,
+		VersionLifecycle: models.VersionLifecycle{State: models.VersionStateLive, SubmittedBy: submittedBy, SubmittedAt: now},
+	}
+	if err := contributionVersioning.insertVersion(c, &placeholder); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// dropAbandonedProposal is contributionVersioning's onDropped: once a SubmitContribution record
+// has nothing pending and nothing but its placeholder has ever been live, the record is deleted,
+// so a rejected, retracted or expired proposal leaves no credit record behind.
+func dropAbandonedProposal(c context.Context, id string) error {
+	// Read straight from the collection: going through contributionVersioning here would make
+	// its initializer depend on itself.
+	versions, err := storeQuery[ContributionVersion](c, contributionVersionCollection, bson.D{{Key: "record_id", Value: id}}, nil, nil, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if version.Version == 1 {
+			if !version.Removed {
+				return nil
+			}
+			continue
+		}
+		switch version.State {
+		case models.VersionStateSubmitted, models.VersionStateLive, models.VersionStateArchived:
+			return nil
+		}
+	}
+	return deleteCreditRecord(c, id)
+}
+
+// deleteCreditRecord deletes credit record id's meta record and every version, without events -
+// for a proposal that never became a credit.
+func deleteCreditRecord(c context.Context, id string) error {
+	if _, err := Storage.DeleteMany(c, contributionVersionCollection, bson.D{{Key: "record_id", Value: id}}); err != nil {
+		return err
+	}
+	_, err := Storage.DeleteOne(c, contributionMetaCollection, bson.D{{Key: "_id", Value: id}})
+	return err
+}
+
+// currentCredit returns credit id's live version, for building the next one. A credit written
+// before contributions were versioned is migrated first (see migrateLegacyCredit). A credit whose
+// live version is removed is a *NotFoundError, the same as one that never existed.
+func currentCredit(c context.Context, id string) (*ContributionVersion, error) {
+	_, live, err := contributionVersioning.getCurrent(c, id)
+	if errors.Is(err, ErrNotFound) {
+		migrated, migrateErr := migrateLegacyCredit(c, id)
+		if migrateErr != nil {
+			return nil, migrateErr
+		}
+		if migrated {
+			_, live, err = contributionVersioning.getCurrent(c, id)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if live.Removed {
+		return nil, &NotFoundError{Type: "contribution", ID: id}
+	}
+	next := *live
+	return &next, nil
+}
+
+// SubmitContribution proposes crediting personID on volumeID with roles (normalized as for
+// AddContribution) for review, returning the submitted version - accepting it adds the credit.
+// The proposal is a new credit record whose first live version is a removed placeholder, so it
+// appears nowhere - and isn't announced as created - until accepted; if it's rejected, retracted
+// or expires instead, the record goes (see dropAbandonedProposal). A person already credited on
+// the volume, or with a proposal for it already pending, is a *DuplicateError.
+func SubmitContribution(c context.Context, personID, volumeID string, roles []string, submittedBy string) (*ContributionVersionVO, error) {
+	roles, err := NormalizeContributionRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	var result *ContributionVersion
+	err = withTransaction(c, func(tc context.Context) error {
+		if err := checkNotCredited(tc, personID, volumeID); err != nil {
+			return err
+		}
+		if err := checkNotProposed(tc, personID, volumeID); err != nil {
+			return err
+		}
+		id, err := addProposalTx(tc, personID, volumeID, submittedBy)
+		if err != nil {
+			return err
+		}
+		proposal := ContributionVersion{PersonId: personID, VolumeId: volumeID, Roles: roles}
+		result, err = contributionVersioning.createVersionTx(tc, id, &proposal, models.VersionStateSubmitted, submittedBy, time.Now(), nil)
+		if err != nil {
+			// Without a transaction to roll back, a refused proposal (over its submitter's cap,
+			// say) would otherwise leave its placeholder behind.
+			return errors.Join(err, deleteCreditRecord(tc, id))
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Info("Contribution not submitted", "personId", personID, "volumeId", volumeID, "error", err)
+		return nil, err
+	}
+	return contributionVersionToVO(result), nil
+}
+
+// SubmitContributionUpdate proposes new roles for a credit, for review - the submitted
+// counterpart of UpdateContribution.
+func SubmitContributionUpdate(c context.Context, id string, roles []string, submittedBy string) (*ContributionVersionVO, error) {
+	roles, err := NormalizeContributionRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	return submitCreditChange(c, id, submittedBy, func(v *ContributionVersion) { v.Roles = roles })
+}
+
+// SubmitContributionRemoval proposes taking a credit off its volume, for review - the submitted
+// counterpart of DeleteContribution.
+func SubmitContributionRemoval(c context.Context, id string, submittedBy string) (*ContributionVersionVO, error) {
+	return submitCreditChange(c, id, submittedBy, func(v *ContributionVersion) { v.Removed = true })
+}
+
+// submitCreditChange submits credit id's live version with change applied.
+func submitCreditChange(c context.Context, id, submittedBy string, change func(*ContributionVersion)) (*ContributionVersionVO, error) {
+	var result *ContributionVersion
+	err := withTransaction(c, func(tc context.Context) error {
+		next, err := currentCredit(tc, id)
+		if err != nil {
+			return err
+		}
+		change(next)
+		result, err = contributionVersioning.createVersionTx(tc, id, next, models.VersionStateSubmitted, submittedBy, time.Now(), nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contributionVersionToVO(result), nil
+}
+
+// ListContributionVersions returns every version of a credit, newest first.
+func ListContributionVersions(c context.Context, id string) ([]*ContributionVersionVO, error) {
+	versions, err := contributionVersioning.listVersions(c, id)
+	if err != nil {
+		return nil, err
+	}
+	vos := make([]*ContributionVersionVO, 0, len(versions))
+	for _, v := range versions {
+		vos = append(vos, contributionVersionToVO(v))
+	}
+	return vos, nil
+}
+
+// GetContributionVersion returns one version's full snapshot, regardless of whether it's current.
+func GetContributionVersion(c context.Context, id string, version int) (*ContributionVersionVO, error) {
+	result, err := contributionVersioning.requireVersion(c, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return contributionVersionToVO(result), nil
+}
+
+// DiffContributionSubmission is DiffVolumeSubmission for a credit, previewing
+// AcceptContributionVersion.
+func DiffContributionSubmission(c context.Context, id string, version int) (*SubmissionDiff, error) {
+	return contributionVersioning.diffSubmission(c, id, version)
+}
+
+// AcceptContributionVersion reviews a submitted version - see AcceptVolumeVersion's doc comment.
+// The credit's projection follows the accepted version: added, re-roled or removed.
+func AcceptContributionVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*ContributionVersionVO, []string, error) {
+	result, conflicts, err := contributionVersioning.acceptVersion(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, expectedCurrentVersion)
+	if err != nil {
+		return nil, conflicts, err
+	}
+	return contributionVersionToVO(result), conflicts, nil
+}
+
+// RejectContributionVersion marks a submitted version rejected.
+func RejectContributionVersion(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
+	return contributionVersioning.rejectVersion(c, id, version, reviewedBy, reviewNote)
+}
+
+// RetractContributionVersion lets the original submitter withdraw their own pending submission.
+func RetractContributionVersion(c context.Context, id string, version int, submitterID string) (*ContributionVersionVO, error) {
+	result, err := contributionVersioning.retractVersion(c, id, version, submitterID)
+	if err != nil {
+		return nil, err
+	}
+	return contributionVersionToVO(result), nil
+}
+
+// SetCurrentContributionVersion rolls a credit back (or forward) to an arbitrary existing version
+// - rolling back a removal puts the credit back on its volume.
+func SetCurrentContributionVersion(c context.Context, id string, version int, expectedCurrentVersion *int) (*ContributionVersionVO, error) {
+	result, err := contributionVersioning.setCurrentVersion(c, id, version, expectedCurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	return contributionVersionToVO(result), nil
+}
+
+// CountSubmittedContributionVersionsBySubmitter counts a submitter's currently-pending versions.
+func CountSubmittedContributionVersionsBySubmitter(c context.Context, submittedBy string) (int64, error) {
+	return contributionVersioning.countSubmittedBySubmitter(c, submittedBy)
+}
+
+// contributionMigration maps a pre-versioning credit onto its first version.
+var contributionMigration = migrationConfig[models.Contribution, ContributionVersion]{
+	oldCollection: "contributions",
+	versioning:    contributionVersioning,
+	id:            func(m *models.Contribution) string { return m.ID },
+	auditable:     func(m *models.Contribution) modelcore.Auditable { return m.Auditable },
+	toVersion: func(m *models.Contribution) ContributionVersion {
+		return ContributionVersion{PersonId: m.PersonId, VolumeId: m.VolumeId, Roles: m.Roles}
+	},
+}
+
+// MigrateContributions gives every credit written before contributions were versioned a meta
+// record and a single live version matching it, under the same id. The "contributions" documents
+// themselves are already the projection and are left as they are. Idempotent - see
+// migrateEntity's doc comment. Running it isn't a prerequisite for deploying: a credit edited or
+// deleted first is migrated on that write.
+func MigrateContributions(c context.Context) (int, error) {
+	return migrateEntity(c, contributionMigration)
+}
+
+// migrateLegacyCredit migrates credit id the way MigrateContributions would, if it's a
+// pre-versioning credit - one in the "contributions" projection without a meta record. Returns
+// false, changing nothing, if there's no such credit. Run inside the write's transaction.
+func migrateLegacyCredit(c context.Context, id string) (bool, error) {
+	legacy, err := storeQuery[models.Contribution](c, "contributions", bson.D{{Key: "_id", Value: id}}, nil, nil, 0, 1)
+	if err != nil || len(legacy) == 0 {
+		return false, err
+	}
+	if err := migrateRecordTx(c, contributionMigration, legacy[0]); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -1,7 +1,6 @@
 package data
 
 import (
-	"os"
 	"testing"
 	"time"
 
@@ -9,10 +8,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
-	"github.com/sweetrpg/common.go/logging"
 	modelcore "github.com/sweetrpg/model-core.go/models"
-	"github.com/sweetrpg/mongodb.go/constants"
-	"github.com/sweetrpg/mongodb.go/database"
 )
 
 // EntityMigrationTestSuite exercises the generic migrateEntity engine (data/entity_versioning.go)
@@ -26,9 +22,7 @@ type EntityMigrationTestSuite struct {
 }
 
 func (suite *EntityMigrationTestSuite) SetupTest() {
-	_ = os.Setenv(constants.DB_URI, os.Getenv("TEST_DB_URI"))
-	logging.Init()
-	database.SetupDatabase()
+	setupTestStorage()
 	assert.NoError(suite.T(), EnsurePublisherVersioningIndexes(suite.T().Context()))
 }
 
@@ -40,7 +34,7 @@ func (suite *EntityMigrationTestSuite) TestMigratePublishersBackfillsMetaAndLive
 			UpdatedAt: time.Now().Add(-24 * time.Hour), UpdatedBy: "auth0|last-editor",
 		},
 	}
-	_, err := database.Insert[models.Publisher]("publishers", legacy)
+	err := storeInsert(suite.T().Context(), "publishers", legacy)
 	assert.NoError(suite.T(), err)
 
 	migrated, err := MigratePublishers(suite.T().Context())
@@ -62,7 +56,7 @@ func (suite *EntityMigrationTestSuite) TestMigratePublishersBackfillsMetaAndLive
 
 func (suite *EntityMigrationTestSuite) TestMigratePublishersIsIdempotent() {
 	legacy := models.Publisher{ID: "legacy-publisher-2", Name: "Legacy Publisher Two"}
-	_, err := database.Insert[models.Publisher]("publishers", legacy)
+	err := storeInsert(suite.T().Context(), "publishers", legacy)
 	assert.NoError(suite.T(), err)
 
 	first, err := MigratePublishers(suite.T().Context())
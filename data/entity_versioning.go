@@ -2,6 +2,7 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -9,12 +10,10 @@ import (
 	"time"
 
 	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/common.go/logging"
 	modelcore "github.com/sweetrpg/model-core.go/models"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // parseWebsite best-effort parses a VO's plain-string website into the url.URL the models layer
@@ -34,31 +33,80 @@ func parseWebsite(s string) url.URL {
 type entityFieldAccessor[T any] struct {
 	get func(*T) any
 	set func(*T, any)
+	// setMerge marks a list field whose conflicts accept resolves by set-merging both sides'
+	// changes (see setMerge) rather than excluding the field.
+	setMerge bool
 }
 
-// entityVersioningConfig wires the generic meta+version engine below (shared across publisher,
-// studio, person, license, and system - the same meta-record+version-record model volume.go/
-// volume_versioning.go implement bespoke, predating this shared type) to one entity type's
-// collections and field accessors. See design.md's "share a generic version-store engine"
-// decision - this avoids reimplementing the version lifecycle five times. Volume itself is not
-// migrated onto this engine - its bespoke implementation is already shipped and tested, and it
-// alone needs staged-asset handling this generic engine doesn't support.
+// stagedFieldsConfig is the optional staged-field hook on entityVersioningConfig - for a type
+// whose submitted versions can carry staged (not-yet-promoted) values alongside their
+// substantive fields, today only Volume's staged cover/sample assets (see design.md's "Staged
+// edit-session assets ride on the submitted version as separate staged fields"). Whichever
+// version goes live on accept has its staged fields cleared, both in memory and in Mongo.
+type stagedFieldsConfig[T any] struct {
+	keys  []string // bson keys cleared on the version that goes live
+	clear func(*T)
+}
+
+// entityVersioningConfig wires the generic meta+version engine below (shared across volume,
+// publisher, studio, person, license, and system) to one entity type's collections and field
+// accessors. See design.md's "share a generic version-store engine" decision - this avoids
+// reimplementing the version lifecycle six times. Volume was the bespoke original this engine
+// was extracted from, and is now driven by it too via the staged hook below.
 type entityVersioningConfig[T any] struct {
 	metaCollection    string
 	versionCollection string
 	typeName          string // for error messages, e.g. "publisher"
-	lifecycle         func(*T) *models.VersionLifecycle
-	setID             func(*T, string)
-	setRecordID       func(*T, string)
-	setVersion        func(*T, int)
+	// lifecycle/setLifecycle read and write T's version-lifecycle bookkeeping as a unit - a
+	// getter/setter pair rather than a pointer into T, since VolumeVersion carries those fields
+	// flat instead of embedding models.VersionLifecycle like every other version type.
+	lifecycle    func(*T) models.VersionLifecycle
+	setLifecycle func(*T, models.VersionLifecycle)
+	setID        func(*T, string)
+	setRecordID  func(*T, string)
+	setVersion   func(*T, int)
+	version      func(*T) int
 	// recordID/displayName back the landing-page summary's per-type stats() query (catalog-
 	// landing-page-summary) - the only two fields that query needs beyond what lifecycle()
 	// already exposes (State, SubmittedAt).
 	recordID    func(*T) string
 	displayName func(*T) string
 	// fields are the substantive fields a submission can change and a review can selectively
-	// accept - everything on T except its version-lifecycle bookkeeping.
+	// accept - everything on T except its version-lifecycle bookkeeping. Keys double as each
+	// field's bson key wherever acceptVersionWithOverrides $sets an override directly.
 	fields map[string]entityFieldAccessor[T]
+	// staged is nil for every type without staged fields.
+	staged *stagedFieldsConfig[T]
+	// references are the places other collections point at this type's record ids - what a
+	// hard delete (purge) has to cascade to, orphan, or refuse over.
+	references []entityReference
+	// search returns a version's display title and the rest of its searchable text, for the
+	// catalog_search index (see indexSearch). nil for a type that isn't searchable.
+	search func(*T) (title string, text []string)
+	// extraFields returns fields written onto every new version document beyond T's own (see
+	// insertVersion) - for volumes, the rating aggregates QueryVolumes can sort by. nil for none.
+	extraFields func(c context.Context, version *T) (bson.D, error)
+	// onLive is called with each new current version, in the transaction that made it current -
+	// for contributions, keeping the "contributions" projection in step. nil for none.
+	onLive func(c context.Context, id string, live *T, deleted bool) error
+	// onPurge is called when record id is about to be purged under policy, in the purge's
+	// transaction, before its dependents are cascaded or orphaned - for state keyed by the record
+	// that isn't one of its references. nil for none.
+	onPurge func(c context.Context, id string, policy CascadePolicy) error
+	// onDropped is called when one of record id's submissions is rejected, withdrawn or expired,
+	// in the transaction that ended it - for contributions, clearing away a proposal that never
+	// went live. nil for none.
+	onDropped func(c context.Context, id string) error
+	// checkAccept vets the version an accept would make live against the current one, in the
+	// accept's transaction (and in a preview) - for volumes, VolumeReferenceValidation on the
+	// relation ids the accept adds. An error refuses the accept. nil for none.
+	checkAccept func(c context.Context, id string, live, current *T) error
+	// approvalPolicy points at the type's exported policy variable, so a change made at startup
+	// is seen here; nil (or the zero policy) is a single reviewer's accept.
+	approvalPolicy *ApprovalPolicy
+	// submissionCap points at the type's exported cap variable, like approvalPolicy; nil (or the
+	// zero cap) lets anyone submit without limit.
+	submissionCap *SubmissionCap
 }
 
 // TypeStats is one entity type's catalog-landing-page-summary card: a live-record count plus
@@ -80,7 +128,7 @@ func (cfg entityVersioningConfig[T]) stats(c context.Context) (*TypeStats, error
 	filter := bson.D{{Key: "state", Value: string(models.VersionStateLive)}}
 
 	countProjection := bson.D{{Key: "record_id", Value: 1}}
-	all, err := database.Query[T](cfg.versionCollection, filter, nil, countProjection, 0, 0)
+	all, err := storeQuery[T](c, cfg.versionCollection, filter, nil, countProjection, 0, 0)
 	if err != nil {
 		return nil, fmt.Errorf("%s: count live versions: %w", cfg.typeName, err)
 	}
@@ -91,7 +139,7 @@ func (cfg entityVersioningConfig[T]) stats(c context.Context) (*TypeStats, error
 	}
 
 	sortOrder := bson.D{{Key: "submitted_at", Value: -1}}
-	recent, err := database.Query[T](cfg.versionCollection, filter, sortOrder, nil, 0, 1)
+	recent, err := storeQuery[T](c, cfg.versionCollection, filter, sortOrder, nil, 0, 1)
 	if err != nil {
 		return nil, fmt.Errorf("%s: query most recent version: %w", cfg.typeName, err)
 	}
@@ -107,16 +155,11 @@ func (cfg entityVersioningConfig[T]) stats(c context.Context) (*TypeStats, error
 }
 
 func (cfg entityVersioningConfig[T]) ensureIndexes(c context.Context) error {
-	_, err := database.Db.Collection(cfg.versionCollection).Indexes().CreateOne(c, mongo.IndexModel{
-		Keys:    bson.D{{Key: "record_id", Value: 1}, {Key: "version", Value: 1}},
-		Options: options.Index().SetUnique(true),
-	})
+	err := Storage.EnsureIndex(c, cfg.versionCollection, bson.D{{Key: "record_id", Value: 1}, {Key: "version", Value: 1}}, true)
 	if err != nil {
 		return fmt.Errorf("%s: create record_id+version index: %w", cfg.typeName, err)
 	}
-	_, err = database.Db.Collection(cfg.versionCollection).Indexes().CreateOne(c, mongo.IndexModel{
-		Keys: bson.D{{Key: "record_id", Value: 1}, {Key: "state", Value: 1}},
-	})
+	err = Storage.EnsureIndex(c, cfg.versionCollection, bson.D{{Key: "record_id", Value: 1}, {Key: "state", Value: 1}}, false)
 	if err != nil {
 		return fmt.Errorf("%s: create record_id+state index: %w", cfg.typeName, err)
 	}
@@ -124,7 +167,7 @@ func (cfg entityVersioningConfig[T]) ensureIndexes(c context.Context) error {
 }
 
 func (cfg entityVersioningConfig[T]) getMeta(c context.Context, id string) (*models.EntityMeta, error) {
-	results, err := database.Query[models.EntityMeta](cfg.metaCollection, bson.D{{Key: "_id", Value: id}}, nil, nil, 0, 1)
+	results, err := storeQuery[models.EntityMeta](c, cfg.metaCollection, bson.D{{Key: "_id", Value: id}}, nil, nil, 0, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +179,7 @@ func (cfg entityVersioningConfig[T]) getMeta(c context.Context, id string) (*mod
 
 func (cfg entityVersioningConfig[T]) getVersion(c context.Context, recordID string, version int) (*T, error) {
 	filter := bson.D{{Key: "record_id", Value: recordID}, {Key: "version", Value: version}}
-	results, err := database.Query[T](cfg.versionCollection, filter, nil, nil, 0, 1)
+	results, err := storeQuery[T](c, cfg.versionCollection, filter, nil, nil, 0, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -146,9 +189,49 @@ func (cfg entityVersioningConfig[T]) getVersion(c context.Context, recordID stri
 	return results[0], nil
 }
 
+// requireMeta is getMeta for callers that need the record to exist - a missing one is a
+// *NotFoundError rather than a nil result.
+func (cfg entityVersioningConfig[T]) requireMeta(c context.Context, id string) (*models.EntityMeta, error) {
+	meta, err := cfg.getMeta(c, id)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, &NotFoundError{Type: cfg.typeName, ID: id}
+	}
+	return meta, nil
+}
+
+// requireVersion is getVersion's counterpart to requireMeta - a missing version is a
+// *VersionNotFoundError.
+func (cfg entityVersioningConfig[T]) requireVersion(c context.Context, recordID string, version int) (*T, error) {
+	result, err := cfg.getVersion(c, recordID, version)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, &VersionNotFoundError{Type: cfg.typeName, ID: recordID, Version: version}
+	}
+	return result, nil
+}
+
+// getCurrent returns a record's meta and its current version - the pair every flattened Get*
+// read needs.
+func (cfg entityVersioningConfig[T]) getCurrent(c context.Context, id string) (*models.EntityMeta, *T, error) {
+	meta, err := cfg.requireMeta(c, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	version, err := cfg.requireVersion(c, id, meta.CurrentVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta, version, nil
+}
+
 func (cfg entityVersioningConfig[T]) setVersionState(c context.Context, recordID string, version int, fields bson.D) error {
 	filter := bson.D{{Key: "record_id", Value: recordID}, {Key: "version", Value: version}}
-	_, err := database.Db.Collection(cfg.versionCollection).UpdateOne(c, filter, bson.D{{Key: "$set", Value: fields}})
+	_, err := Storage.UpdateOne(c, cfg.versionCollection, filter, bson.D{{Key: "$set", Value: fields}})
 	return err
 }
 
@@ -156,20 +239,88 @@ func (cfg entityVersioningConfig[T]) archiveVersion(c context.Context, recordID
 	return cfg.setVersionState(c, recordID, version, bson.D{{Key: "state", Value: string(models.VersionStateArchived)}})
 }
 
-func (cfg entityVersioningConfig[T]) setMetaCurrentVersion(c context.Context, recordID string, version int) error {
-	_, err := database.Db.Collection(cfg.metaCollection).UpdateOne(
+// setMetaCurrentVersion moves the meta record's current-version pointer from `from` to `to` -
+// a compare-and-swap, so if another writer moved the pointer since the caller read it the update
+// matches nothing and a *ConflictError is returned instead of silently clobbering their change.
+// Inside a transaction that aborts the whole operation. Without one, callers write the version
+// they're moving to first and undo it if they lose (see swapCurrentVersion), so the pointer only
+// ever names a version that exists and the loser leaves nothing behind.
+func (cfg entityVersioningConfig[T]) setMetaCurrentVersion(c context.Context, recordID string, from int, to int) error {
+	matched, err := Storage.UpdateOne(
 		c,
-		bson.D{{Key: "_id", Value: recordID}},
-		bson.D{{Key: "$set", Value: bson.D{{Key: "current_version", Value: version}}}},
+		cfg.metaCollection,
+		bson.D{{Key: "_id", Value: recordID}, {Key: "current_version", Value: from}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "current_version", Value: to}}}},
 	)
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		meta, err := cfg.getMeta(c, recordID)
+		if err != nil {
+			return err
+		}
+		conflict := &ConflictError{Type: cfg.typeName, ID: recordID, ExpectedVersion: from}
+		if meta != nil {
+			conflict.CurrentVersion = meta.CurrentVersion
+		}
+		return conflict
+	}
+	return nil
+}
+
+// swapCurrentVersion is setMetaCurrentVersion for a caller that has already written version `to`:
+// if the swap loses the race, undo reverses that write before the *ConflictError is returned.
+func (cfg entityVersioningConfig[T]) swapCurrentVersion(c context.Context, recordID string, from int, to int, undo func() error) error {
+	err := cfg.setMetaCurrentVersion(c, recordID, from, to)
+	var conflict *ConflictError
+	if errors.As(err, &conflict) {
+		if undoErr := undo(); undoErr != nil {
+			return errors.Join(err, fmt.Errorf("%s %s: undo version %d: %w", cfg.typeName, recordID, to, undoErr))
+		}
+	}
+	return err
+}
+
+// discardVersion deletes a version this writer inserted but lost the race to make current.
+func (cfg entityVersioningConfig[T]) discardVersion(c context.Context, recordID string, version int) error {
+	_, err := Storage.DeleteOne(c, cfg.versionCollection, bson.D{{Key: "record_id", Value: recordID}, {Key: "version", Value: version}})
 	return err
 }
 
+// transitionVersion moves a version from state `from` to `to`, but only if it's still in `from` -
+// so of two writers racing to flip the same version, the one that finds it already moved fails
+// with an *InvalidStateError instead of undoing the other's flip later.
+func (cfg entityVersioningConfig[T]) transitionVersion(c context.Context, recordID string, version int, from models.VersionState, to models.VersionState) error {
+	filter := bson.D{{Key: "record_id", Value: recordID}, {Key: "version", Value: version}, {Key: "state", Value: string(from)}}
+	matched, err := Storage.UpdateOne(c, cfg.versionCollection, filter, bson.D{{Key: "$set", Value: bson.D{{Key: "state", Value: string(to)}}}})
+	if err != nil {
+		return err
+	}
+	if matched == 0 {
+		current, err := cfg.requireVersion(c, recordID, version)
+		if err != nil {
+			return err
+		}
+		return &InvalidStateError{Type: cfg.typeName, ID: recordID, Version: version, State: cfg.lifecycle(current).State}
+	}
+	return nil
+}
+
+// checkExpectedCurrentVersion enforces an Update*/Accept*/SetCurrent* caller's optional
+// expectedCurrentVersion precondition against the meta record it just read.
+func (cfg entityVersioningConfig[T]) checkExpectedCurrentVersion(meta *models.EntityMeta, expectedCurrentVersion *int) error {
+	if expectedCurrentVersion == nil || *expectedCurrentVersion == meta.CurrentVersion {
+		return nil
+	}
+	return &ConflictError{Type: cfg.typeName, ID: meta.ID, ExpectedVersion: *expectedCurrentVersion, CurrentVersion: meta.CurrentVersion}
+}
+
 // listVersions returns every version of a record, newest first.
 func (cfg entityVersioningConfig[T]) listVersions(c context.Context, id string) ([]*T, error) {
 	filter := bson.D{{Key: "record_id", Value: id}}
 	sortOrder := bson.D{{Key: "version", Value: -1}}
-	return database.Query[T](cfg.versionCollection, filter, sortOrder, nil, 0, 0)
+	return storeQuery[T](c, cfg.versionCollection, filter, sortOrder, nil, 0, 0)
 }
 
 func (cfg entityVersioningConfig[T]) fieldValue(v *T, field string) any {
@@ -186,6 +337,37 @@ func (cfg entityVersioningConfig[T]) setFieldValue(v *T, field string, value any
 	}
 }
 
+// clearStaged clears v's staged fields, if cfg has any, returning the $set fragment that
+// persists the same change.
+func (cfg entityVersioningConfig[T]) clearStaged(v *T) bson.D {
+	if cfg.staged == nil {
+		return nil
+	}
+	cfg.staged.clear(v)
+	update := make(bson.D, 0, len(cfg.staged.keys))
+	for _, key := range cfg.staged.keys {
+		update = append(update, bson.E{Key: key, Value: nil})
+	}
+	return update
+}
+
+// applyOverrides overlays overrides (field name -> value) onto v, returning the $set fragment
+// that persists the same change. Applied in sorted field order so the fragment is deterministic.
+func (cfg entityVersioningConfig[T]) applyOverrides(v *T, overrides map[string]any) bson.D {
+	fields := make([]string, 0, len(overrides))
+	for field := range overrides {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	update := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		cfg.setFieldValue(v, field, overrides[field])
+		update = append(update, bson.E{Key: field, Value: overrides[field]})
+	}
+	return update
+}
+
 func (cfg entityVersioningConfig[T]) changedFields(submitted, base *T) []string {
 	var changed []string
 	for field := range cfg.fields {
@@ -197,13 +379,103 @@ func (cfg entityVersioningConfig[T]) changedFields(submitted, base *T) []string
 	return changed
 }
 
-// addEntity creates a record's meta record and its first (live) version. entity must already
-// carry its substantive field values; addEntity sets id/record_id/version/lifecycle fields.
+// derivedFromField marks a version a partial accept derived, holding the number of the submission
+// it was derived from. The derived version carries that submission's submitter, so this is what
+// keeps it out of countSubmissions' window - it's the same submission, not another one.
+const derivedFromField = "derived_from"
+
+// recordDeletedField mirrors the meta record's soft deletion onto its version documents, so a
+// query over live versions can leave deleted records out in the filter itself instead of
+// dropping them after the page has been cut. Absent means not deleted.
+const recordDeletedField = "record_deleted"
+
+// notRecordDeleted is the filter clause matching versions of records that aren't soft-deleted.
+var notRecordDeleted = bson.E{Key: recordDeletedField, Value: bson.D{{Key: "$ne", Value: true}}}
+
+// markRecordDeleted sets recordDeletedField on every version of record id.
+func (cfg entityVersioningConfig[T]) markRecordDeleted(c context.Context, id string, deleted bool) error {
+	_, err := Storage.UpdateMany(c, cfg.versionCollection, bson.D{{Key: "record_id", Value: id}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: recordDeletedField, Value: deleted}}}})
+	return err
+}
+
+// backfillRecordDeleted sets recordDeletedField on every version of each of cfg's soft-deleted
+// records, returning how many records it marked.
+func (cfg entityVersioningConfig[T]) backfillRecordDeleted(c context.Context) (int, error) {
+	filter := bson.D{{Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}}}
+	metas, err := storeQuery[models.EntityMeta](c, cfg.metaCollection, filter, nil, bson.D{{Key: "_id", Value: 1}}, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	for i, meta := range metas {
+		if err := cfg.markRecordDeleted(c, meta.ID, true); err != nil {
+			return i, fmt.Errorf("%s %s: %w", cfg.typeName, meta.ID, err)
+		}
+	}
+	return len(metas), nil
+}
+
+// BackfillRecordDeleted sets record_deleted on the versions of every volume, publisher, studio,
+// person and license soft-deleted before the field existed, returning how many records it
+// marked. Run it once on upgrade: until it has run, QueryVolumes can still return a short page
+// where such a volume would have been. Soft deletes, restores and merges keep the field current
+// on their own. Safe to re-run.
+func BackfillRecordDeleted(c context.Context) (int, error) {
+	total := 0
+	for _, backfill := range []func(context.Context) (int, error){
+		volumeVersioning.backfillRecordDeleted, publisherVersioning.backfillRecordDeleted,
+		studioVersioning.backfillRecordDeleted, personVersioning.backfillRecordDeleted,
+		licenseVersioning.backfillRecordDeleted,
+	} {
+		n, err := backfill(c)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("backfill record_deleted: %w", err)
+		}
+	}
+	return total, nil
+}
+
+// publishLive does what follows live becoming record id's current version: reindexing it for
+// search, flagging it if the record is soft-deleted, then cfg's onLive hook.
+func (cfg entityVersioningConfig[T]) publishLive(c context.Context, id string, live *T, deleted bool) error {
+	if err := cfg.indexSearch(c, id, live, deleted); err != nil {
+		return err
+	}
+	if deleted {
+		filter := bson.D{{Key: "record_id", Value: id}, {Key: "version", Value: cfg.version(live)}}
+		if _, err := Storage.UpdateOne(c, cfg.versionCollection, filter, bson.D{{Key: "$set", Value: bson.D{{Key: recordDeletedField, Value: true}}}}); err != nil {
+			return err
+		}
+	}
+	if cfg.onLive != nil {
+		return cfg.onLive(c, id, live, deleted)
+	}
+	return nil
+}
+
+// addEntity creates a record's meta record and its first (live) version, in one transaction.
+// entity must already carry its substantive field values; addEntity sets id/record_id/version/
+// lifecycle fields.
 func (cfg entityVersioningConfig[T]) addEntity(c context.Context, entity *T, createdBy string) (*string, error) {
+	var id *string
+	err := withTransaction(c, func(tc context.Context) error {
+		var err error
+		id, err = cfg.addEntityTx(tc, entity, createdBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// addEntityTx is addEntity's body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) addEntityTx(c context.Context, entity *T, createdBy string) (*string, error) {
 	now := time.Now()
 	metaID := primitive.NewObjectID().Hex()
 	meta := models.EntityMeta{ID: metaID, CurrentVersion: 1, CreatedAt: now, CreatedBy: createdBy}
-	if _, err := database.Insert[models.EntityMeta](cfg.metaCollection, meta); err != nil {
+	if err := storeInsert(c, cfg.metaCollection, meta); err != nil {
 		return nil, err
 	}
 
@@ -215,8 +487,18 @@ func (cfg entityVersioningConfig[T]) addEntity(c context.Context, entity *T, cre
 	lc.BaseVersion = nil
 	lc.SubmittedBy = createdBy
 	lc.SubmittedAt = now
+	cfg.setLifecycle(entity, lc)
 
-	if _, err := database.Insert[T](cfg.versionCollection, *entity); err != nil {
+	if err := cfg.insertVersion(c, entity); err != nil {
+		return nil, err
+	}
+	if err := cfg.publishLive(c, metaID, entity, false); err != nil {
+		return nil, err
+	}
+	if err := appendEvent(c, Event{
+		Type: EventRecordCreated, Entity: cfg.typeName, RecordID: metaID, Version: 1,
+		State: models.VersionStateLive, CurrentVersion: &meta.CurrentVersion, Actor: createdBy,
+	}); err != nil {
 		return nil, err
 	}
 	return &metaID, nil
@@ -225,20 +507,43 @@ func (cfg entityVersioningConfig[T]) addEntity(c context.Context, entity *T, cre
 // createVersion creates a new version for an existing record - editor/admin: state Live, goes
 // current immediately and archives the previous current version; submitter: state Submitted,
 // current pointer untouched. entity must already carry its desired substantive field values.
-func (cfg entityVersioningConfig[T]) createVersion(c context.Context, id string, entity *T, state models.VersionState, submittedBy string) (*T, error) {
-	return cfg.createVersionWithSubmission(c, id, entity, state, submittedBy, time.Now())
+// A non-nil expectedCurrentVersion that no longer matches the record's current version fails
+// with a *ConflictError instead.
+func (cfg entityVersioningConfig[T]) createVersion(c context.Context, id string, entity *T, state models.VersionState, submittedBy string, expectedCurrentVersion *int) (*T, error) {
+	return cfg.createVersionWithSubmission(c, id, entity, state, submittedBy, time.Now(), expectedCurrentVersion)
 }
 
 // createVersionWithSubmission is createVersion with a caller-supplied submittedAt instead of
 // always stamping "now" - used by migration, which preserves a migrated proposal's original
-// submission time rather than the time of the migration run itself.
-func (cfg entityVersioningConfig[T]) createVersionWithSubmission(c context.Context, id string, entity *T, state models.VersionState, submittedBy string, submittedAt time.Time) (*T, error) {
-	meta, err := cfg.getMeta(c, id)
+// submission time rather than the time of the migration run itself. Runs in one transaction, so
+// a live version's insert, the previous version's archive, and the meta pointer move land
+// together or not at all.
+func (cfg entityVersioningConfig[T]) createVersionWithSubmission(c context.Context, id string, entity *T, state models.VersionState, submittedBy string, submittedAt time.Time, expectedCurrentVersion *int) (*T, error) {
+	var result *T
+	err := withTransaction(c, func(tc context.Context) error {
+		var err error
+		result, err = cfg.createVersionTx(tc, id, entity, state, submittedBy, submittedAt, expectedCurrentVersion)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if meta == nil {
-		return nil, nil
+	return result, nil
+}
+
+// createVersionTx is createVersionWithSubmission's body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) createVersionTx(c context.Context, id string, entity *T, state models.VersionState, submittedBy string, submittedAt time.Time, expectedCurrentVersion *int) (*T, error) {
+	meta, err := cfg.requireMeta(c, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkExpectedCurrentVersion(meta, expectedCurrentVersion); err != nil {
+		return nil, err
+	}
+	if state == models.VersionStateSubmitted {
+		if err := cfg.checkSubmissionCap(c, id, submittedBy); err != nil {
+			return nil, err
+		}
 	}
 
 	nextVersion, err := cfg.nextVersionNumber(c, id)
@@ -255,164 +560,354 @@ func (cfg entityVersioningConfig[T]) createVersionWithSubmission(c context.Conte
 	lc.BaseVersion = &baseVersion
 	lc.SubmittedBy = submittedBy
 	lc.SubmittedAt = submittedAt
+	cfg.setLifecycle(entity, lc)
 
-	if _, err := database.Insert[T](cfg.versionCollection, *entity); err != nil {
+	if err := cfg.insertVersion(c, entity); err != nil {
 		return nil, err
 	}
 
+	event := Event{Type: EventVersionCreated, Entity: cfg.typeName, RecordID: id, Version: nextVersion, State: state, Actor: submittedBy}
 	if state == models.VersionStateLive {
+		if err := cfg.swapCurrentVersion(c, id, meta.CurrentVersion, nextVersion, func() error {
+			return cfg.discardVersion(c, id, nextVersion)
+		}); err != nil {
+			return nil, err
+		}
 		if err := cfg.archiveVersion(c, id, meta.CurrentVersion); err != nil {
 			return nil, err
 		}
-		if err := cfg.setMetaCurrentVersion(c, id, nextVersion); err != nil {
+		if err := cfg.publishLive(c, id, entity, meta.DeletedAt != nil); err != nil {
 			return nil, err
 		}
+		event.CurrentVersion = &nextVersion
+	}
+	if err := appendEvent(c, event); err != nil {
+		return nil, err
 	}
 
 	return entity, nil
 }
 
-// nextVersionNumber returns the next sequential version number for a record - 1 if it has none yet.
+// insertVersion stores a version document with its normalized_name, any cfg.extraFields and any
+// extra fields the caller adds, alongside the model's own fields.
+func (cfg entityVersioningConfig[T]) insertVersion(c context.Context, version *T, extra ...bson.E) error {
+	raw, err := bson.Marshal(version)
+	if err != nil {
+		return err
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	doc = append(doc, bson.E{Key: normalizedNameField, Value: NormalizeName(cfg.displayName(version))})
+	if cfg.extraFields != nil {
+		fields, err := cfg.extraFields(c, version)
+		if err != nil {
+			return err
+		}
+		doc = append(doc, fields...)
+	}
+	doc = append(doc, extra...)
+	return Storage.Insert(c, cfg.versionCollection, doc)
+}
+
+// versionCounter is the meta record's last_version field - the atomic per-record version
+// counter nextVersionNumber allocates from. Not on models.EntityMeta, so read through this.
+type versionCounter struct {
+	LastVersion int `bson:"last_version"`
+}
+
+// nextVersionNumber allocates the next sequential version number for a record by atomically
+// incrementing its meta record's last_version counter, so two concurrent writers can never be
+// handed the same number (and trip the unique (record_id, version) index). A meta record without
+// a counter yet - created by addEntity or a migration, neither of which allocates - is seeded from
+// its highest existing version first; the seed only applies if the counter is still absent, so
+// racing seeders agree.
 func (cfg entityVersioningConfig[T]) nextVersionNumber(c context.Context, recordID string) (int, error) {
+	increment := bson.D{{Key: "$inc", Value: bson.D{{Key: "last_version", Value: 1}}}}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		filter := bson.D{{Key: "_id", Value: recordID}, {Key: "last_version", Value: bson.D{{Key: "$exists", Value: true}}}}
+		doc, err := Storage.FindOneAndUpdate(c, cfg.metaCollection, filter, increment)
+		if err != nil {
+			return 0, err
+		}
+		if doc != nil {
+			var counter versionCounter
+			if err := bson.Unmarshal(doc, &counter); err != nil {
+				return 0, err
+			}
+			return counter.LastVersion, nil
+		}
+
+		highest, err := cfg.highestVersionNumber(c, recordID)
+		if err != nil {
+			return 0, err
+		}
+		_, err = Storage.UpdateOne(
+			c,
+			cfg.metaCollection,
+			bson.D{{Key: "_id", Value: recordID}, {Key: "last_version", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "last_version", Value: highest}}}},
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, &NotFoundError{Type: cfg.typeName, ID: recordID}
+}
+
+// highestVersionNumber returns a record's highest existing version number - 0 if it has none.
+func (cfg entityVersioningConfig[T]) highestVersionNumber(c context.Context, recordID string) (int, error) {
 	filter := bson.D{{Key: "record_id", Value: recordID}}
 	sortOrder := bson.D{{Key: "version", Value: -1}}
-	results, err := database.Query[T](cfg.versionCollection, filter, sortOrder, nil, 0, 1)
+	results, err := storeQuery[T](c, cfg.versionCollection, filter, sortOrder, nil, 0, 1)
 	if err != nil {
 		return 0, err
 	}
 	if len(results) == 0 {
-		return 1, nil
+		return 0, nil
 	}
-	return cfg.versionOf(results[0]) + 1, nil
+	return cfg.version(results[0]), nil
 }
 
-func (cfg entityVersioningConfig[T]) versionOf(v *T) int {
-	// Version is always the second config-set field; read it back via the fields the caller
-	// already exposed through setVersion's counterpart is unnecessary - version numbers are
-	// looked up via the raw bson document's own "version" key by the query filter/sort, so a
-	// dedicated getter is simplest here.
-	rv := reflect.ValueOf(v).Elem().FieldByName("Version")
-	return int(rv.Int())
+// acceptVersion reviews a submitted version - see AcceptVolumeVersion's doc comment for the full
+// accept-all/accept-selected/conflict semantics, and for resolutions.
+func (cfg entityVersioningConfig[T]) acceptVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*T, []string, error) {
+	return cfg.acceptVersionWithOverrides(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, nil, expectedCurrentVersion)
 }
 
-// acceptVersion reviews a submitted version - see AcceptVolumeVersion's doc comment for the full
-// accept-all/accept-selected/conflict semantics this mirrors exactly, minus staged-asset handling.
-func (cfg entityVersioningConfig[T]) acceptVersion(c context.Context, id string, version int, selectedFields []string, reviewedBy string, reviewNote *string) (*T, []string, error) {
-	submitted, err := cfg.getVersion(c, id, version)
+// acceptVersionWithOverrides is acceptVersion plus an optional set of field values (field name ->
+// value, nil for the common case) overlaid onto whichever version goes live - Volume's
+// liveCoverAssetId/liveSampleAssetIds promotion override. Either way, the version that goes live
+// has its staged fields cleared (see stagedFieldsConfig). Runs in one transaction.
+func (cfg entityVersioningConfig[T]) acceptVersionWithOverrides(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, overrides map[string]any, expectedCurrentVersion *int) (*T, []string, error) {
+	var result *T
+	var conflicts []string
+	err := withTransaction(c, func(tc context.Context) error {
+		var err error
+		result, conflicts, err = cfg.acceptVersionTx(tc, id, version, selectedFields, resolutions, reviewedBy, reviewNote, overrides, expectedCurrentVersion)
+		return err
+	})
 	if err != nil {
 		return nil, nil, err
 	}
-	if submitted == nil {
-		return nil, nil, fmt.Errorf("%s %s: version %d not found", cfg.typeName, id, version)
+	return result, conflicts, nil
+}
+
+// acceptPlan is what accepting a submission would do, worked out from reads alone -
+// acceptVersionTx writes it, previewAccept just returns it.
+type acceptPlan[T any] struct {
+	meta *models.EntityMeta
+	// promote: a clean full accept, which promotes the submitted version itself rather than
+	// deriving a new one.
+	promote bool
+	// live is the version that would go live. A derived version has no id or version number
+	// yet - those are only allocated when the plan is written.
+	live      *T
+	accepted  []string
+	conflicts []string
+}
+
+// planAccept checks a submission can be accepted and works out the resulting live version,
+// without writing anything. See AcceptVolumeVersion for the rules.
+func (cfg entityVersioningConfig[T]) planAccept(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, overrides map[string]any, expectedCurrentVersion *int, now time.Time) (*acceptPlan[T], error) {
+	submitted, err := cfg.requireVersion(c, id, version)
+	if err != nil {
+		return nil, err
 	}
 	submittedLC := cfg.lifecycle(submitted)
 	if submittedLC.State != models.VersionStateSubmitted {
-		return nil, nil, fmt.Errorf("%s %s: version %d is not submitted (state: %s)", cfg.typeName, id, version, submittedLC.State)
+		return nil, &InvalidStateError{Type: cfg.typeName, ID: id, Version: version, State: submittedLC.State}
 	}
 	if submittedLC.BaseVersion == nil {
-		return nil, nil, fmt.Errorf("%s %s: version %d has no base version to review against", cfg.typeName, id, version)
+		return nil, &NoBaseVersionError{Type: cfg.typeName, ID: id, Version: version}
 	}
-
-	meta, err := cfg.getMeta(c, id)
-	if err != nil {
-		return nil, nil, err
+	if err := cfg.checkClaim(c, id, version, reviewedBy, now); err != nil {
+		return nil, err
 	}
-	if meta == nil {
-		return nil, nil, fmt.Errorf("%s %s: meta record not found", cfg.typeName, id)
+	if err := cfg.checkApprovalPolicy(c, id, submitted, reviewedBy); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.checkResolutions(id, resolutions); err != nil {
+		return nil, err
 	}
 
-	current, err := cfg.getVersion(c, id, meta.CurrentVersion)
+	meta, err := cfg.requireMeta(c, id)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	if current == nil {
-		return nil, nil, fmt.Errorf("%s %s: current version %d not found", cfg.typeName, id, meta.CurrentVersion)
+	if err := cfg.checkExpectedCurrentVersion(meta, expectedCurrentVersion); err != nil {
+		return nil, err
 	}
 
-	now := time.Now()
+	current, err := cfg.requireVersion(c, id, meta.CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
 
 	if selectedFields == nil && *submittedLC.BaseVersion == meta.CurrentVersion {
-		if err := cfg.archiveVersion(c, id, meta.CurrentVersion); err != nil {
-			return nil, nil, err
-		}
+		live := *submitted
 		submittedLC.State = models.VersionStateLive
 		submittedLC.ReviewedBy = &reviewedBy
 		submittedLC.ReviewedAt = &now
 		submittedLC.ReviewNote = reviewNote
-		if err := cfg.setVersionState(c, id, version, bson.D{
-			{Key: "state", Value: string(models.VersionStateLive)},
-			{Key: "reviewed_by", Value: reviewedBy},
-			{Key: "reviewed_at", Value: now},
-			{Key: "review_note", Value: reviewNote},
-		}); err != nil {
-			return nil, nil, err
-		}
-		if err := cfg.setMetaCurrentVersion(c, id, version); err != nil {
-			return nil, nil, err
+		cfg.setLifecycle(&live, submittedLC)
+		cfg.clearStaged(&live)
+		cfg.applyOverrides(&live, overrides)
+		if err := cfg.vetAccept(c, id, &live, current); err != nil {
+			return nil, err
 		}
-		return submitted, nil, nil
+		return &acceptPlan[T]{
+			meta: meta, promote: true, live: &live,
+			accepted: cfg.changedFields(submitted, current),
+		}, nil
 	}
 
-	baseVersion, err := cfg.getVersion(c, id, *submittedLC.BaseVersion)
+	baseVersion, err := cfg.requireVersion(c, id, *submittedLC.BaseVersion)
 	if err != nil {
-		return nil, nil, err
-	}
-	if baseVersion == nil {
-		return nil, nil, fmt.Errorf("%s %s: base version %d not found", cfg.typeName, id, *submittedLC.BaseVersion)
+		return nil, err
 	}
 
 	changed := cfg.changedFields(submitted, baseVersion)
 	target := changed
 	if selectedFields != nil {
-		selectedSet := make(map[string]bool, len(selectedFields))
-		for _, f := range selectedFields {
-			selectedSet[f] = true
-		}
-		var filtered []string
-		for _, f := range changed {
-			if selectedSet[f] {
-				filtered = append(filtered, f)
-			}
-		}
-		target = filtered
+		target = intersectFields(changed, selectedFields)
 	}
 
 	derived := *current
-	var conflicts, accepted []string
-	for _, field := range target {
-		if !reflect.DeepEqual(cfg.fieldValue(current, field), cfg.fieldValue(baseVersion, field)) {
+	plan := &acceptPlan[T]{meta: meta, live: &derived}
+	plan.accepted, plan.conflicts = cfg.mergeFields(&derived, target, resolutions, baseVersion, current, submitted)
+
+	cfg.setID(&derived, "")
+	cfg.setVersion(&derived, 0)
+	cfg.setLifecycle(&derived, models.VersionLifecycle{
+		State:       models.VersionStateLive,
+		BaseVersion: &meta.CurrentVersion,
+		SubmittedBy: submittedLC.SubmittedBy,
+		SubmittedAt: now,
+		ReviewedBy:  &reviewedBy,
+		ReviewedAt:  &now,
+		ReviewNote:  reviewNote,
+	})
+	cfg.clearStaged(&derived)
+	cfg.applyOverrides(&derived, overrides)
+	if err := cfg.vetAccept(c, id, &derived, current); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// vetAccept runs cfg's checkAccept, if it has one, on the version planAccept would make live.
+func (cfg entityVersioningConfig[T]) vetAccept(c context.Context, id string, live, current *T) error {
+	if cfg.checkAccept == nil {
+		return nil
+	}
+	return cfg.checkAccept(c, id, live, current)
+}
+
+// mergeFields overlays each of fields from submitted onto derived (a copy of current), returning
+// the fields taken from the submission, wholly or merged, and those left out as conflicts: a
+// field current has also moved off base on is settled by its entry in resolutions, else set-merged
+// if it's a list, else a conflict.
+func (cfg entityVersioningConfig[T]) mergeFields(derived *T, fields []string, resolutions map[string]ConflictResolution, base, current, submitted *T) (accepted, conflicts []string) {
+	for _, field := range fields {
+		if !cfg.wouldConflict(field, base, current) {
+			cfg.setFieldValue(derived, field, cfg.fieldValue(submitted, field))
+			accepted = append(accepted, field)
+			continue
+		}
+		var resolution *ConflictResolution
+		if r, ok := resolutions[field]; ok {
+			resolution = &r
+		}
+		value, fromSubmission, ok := cfg.resolveConflict(field, resolution, base, current, submitted)
+		if !ok {
 			conflicts = append(conflicts, field)
 			continue
 		}
-		cfg.setFieldValue(&derived, field, cfg.fieldValue(submitted, field))
-		accepted = append(accepted, field)
+		cfg.setFieldValue(derived, field, value)
+		if fromSubmission {
+			accepted = append(accepted, field)
+		}
+	}
+	return accepted, conflicts
+}
+
+// previewAccept is acceptVersionWithOverrides as a dry run: the plan it would write, with nothing
+// written. The same checks apply, so anything that would make the real accept fail fails here
+// too.
+func (cfg entityVersioningConfig[T]) previewAccept(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, overrides map[string]any, expectedCurrentVersion *int) (*acceptPlan[T], error) {
+	return cfg.planAccept(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, overrides, expectedCurrentVersion, time.Now())
+}
+
+// acceptVersionTx is acceptVersionWithOverrides' body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) acceptVersionTx(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, overrides map[string]any, expectedCurrentVersion *int) (*T, []string, error) {
+	now := time.Now()
+	plan, err := cfg.planAccept(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, overrides, expectedCurrentVersion, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta := plan.meta
+
+	if plan.promote {
+		// The submission goes live before the pointer moves to it, as in createVersionTx; a lost
+		// race puts it back up for review.
+		if err := cfg.transitionVersion(c, id, version, models.VersionStateSubmitted, models.VersionStateLive); err != nil {
+			return nil, nil, err
+		}
+		if err := cfg.swapCurrentVersion(c, id, meta.CurrentVersion, version, func() error {
+			return cfg.transitionVersion(c, id, version, models.VersionStateLive, models.VersionStateSubmitted)
+		}); err != nil {
+			return nil, nil, err
+		}
+		if err := cfg.archiveVersion(c, id, meta.CurrentVersion); err != nil {
+			return nil, nil, err
+		}
+		update := bson.D{
+			{Key: "reviewed_by", Value: reviewedBy},
+			{Key: "reviewed_at", Value: now},
+			{Key: "review_note", Value: reviewNote},
+		}
+		update = append(update, cfg.clearStaged(plan.live)...)
+		update = append(update, cfg.applyOverrides(plan.live, overrides)...)
+		if err := cfg.setVersionState(c, id, version, update); err != nil {
+			return nil, nil, err
+		}
+		if err := cfg.publishLive(c, id, plan.live, meta.DeletedAt != nil); err != nil {
+			return nil, nil, err
+		}
+		if err := appendEvent(c, Event{
+			Type: EventVersionAccepted, Entity: cfg.typeName, RecordID: id, Version: version,
+			State: models.VersionStateLive, CurrentVersion: &version, Actor: reviewedBy,
+		}); err != nil {
+			return nil, nil, err
+		}
+		return plan.live, nil, nil
 	}
 
 	nextVersion, err := cfg.nextVersionNumber(c, id)
 	if err != nil {
 		return nil, nil, err
 	}
+	derived := plan.live
+	cfg.setID(derived, primitive.NewObjectID().Hex())
+	cfg.setRecordID(derived, id)
+	cfg.setVersion(derived, nextVersion)
 
-	cfg.setID(&derived, primitive.NewObjectID().Hex())
-	cfg.setRecordID(&derived, id)
-	cfg.setVersion(&derived, nextVersion)
-	derivedLC := cfg.lifecycle(&derived)
-	derivedLC.State = models.VersionStateLive
-	derivedLC.BaseVersion = &meta.CurrentVersion
-	derivedLC.SubmittedBy = submittedLC.SubmittedBy
-	derivedLC.SubmittedAt = now
-	derivedLC.ReviewedBy = &reviewedBy
-	derivedLC.ReviewedAt = &now
-	derivedLC.ReviewNote = reviewNote
-	derivedLC.ResultingVersion = nil
-
-	if _, err := database.Insert[T](cfg.versionCollection, derived); err != nil {
+	if err := cfg.insertVersion(c, derived, bson.E{Key: derivedFromField, Value: version}); err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.swapCurrentVersion(c, id, meta.CurrentVersion, nextVersion, func() error {
+		return cfg.discardVersion(c, id, nextVersion)
+	}); err != nil {
 		return nil, nil, err
 	}
 	if err := cfg.archiveVersion(c, id, meta.CurrentVersion); err != nil {
 		return nil, nil, err
 	}
-	if err := cfg.setMetaCurrentVersion(c, id, nextVersion); err != nil {
+	if err := cfg.publishLive(c, id, derived, meta.DeletedAt != nil); err != nil {
 		return nil, nil, err
 	}
 	if err := cfg.setVersionState(c, id, version, bson.D{
@@ -424,84 +919,180 @@ func (cfg entityVersioningConfig[T]) acceptVersion(c context.Context, id string,
 	}); err != nil {
 		return nil, nil, err
 	}
+	if err := appendEvent(c, Event{
+		Type: EventVersionAccepted, Entity: cfg.typeName, RecordID: id, Version: version,
+		State: models.VersionStatePartiallyAccepted, CurrentVersion: &nextVersion, Actor: reviewedBy,
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	logging.Logger.Info("acceptVersion: derived version", "type", cfg.typeName, "id", id, "version", nextVersion, "accepted", plan.accepted, "conflicts", plan.conflicts)
 
-	_ = accepted
-	return &derived, conflicts, nil
+	return derived, plan.conflicts, nil
 }
 
-// rejectVersion marks a submitted version rejected, with an optional note.
+// intersectFields returns the members of fields also present in selected, in fields' order.
+func intersectFields(fields, selected []string) []string {
+	selectedSet := make(map[string]bool, len(selected))
+	for _, f := range selected {
+		selectedSet[f] = true
+	}
+	var out []string
+	for _, f := range fields {
+		if selectedSet[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// rejectVersion marks a submitted version rejected, with an optional note - refused with a
+// *ClaimError if another reviewer has it claimed. Its state check and write share one
+// transaction, so a concurrent accept can't slip in between them.
 func (cfg entityVersioningConfig[T]) rejectVersion(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
-	submitted, err := cfg.getVersion(c, id, version)
+	return withTransaction(c, func(tc context.Context) error {
+		return cfg.rejectVersionTx(tc, id, version, reviewedBy, reviewNote)
+	})
+}
+
+// rejectVersionTx is rejectVersion's body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) rejectVersionTx(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
+	submitted, err := cfg.requireVersion(c, id, version)
 	if err != nil {
 		return err
 	}
-	if submitted == nil {
-		return fmt.Errorf("%s %s: version %d not found", cfg.typeName, id, version)
-	}
-	if cfg.lifecycle(submitted).State != models.VersionStateSubmitted {
-		return fmt.Errorf("%s %s: version %d is not submitted", cfg.typeName, id, version)
+	if state := cfg.lifecycle(submitted).State; state != models.VersionStateSubmitted {
+		return &InvalidStateError{Type: cfg.typeName, ID: id, Version: version, State: state}
 	}
 	now := time.Now()
-	return cfg.setVersionState(c, id, version, bson.D{
+	if err := cfg.checkClaim(c, id, version, reviewedBy, now); err != nil {
+		return err
+	}
+	if err := cfg.setVersionState(c, id, version, bson.D{
 		{Key: "state", Value: string(models.VersionStateRejected)},
 		{Key: "reviewed_by", Value: reviewedBy},
 		{Key: "reviewed_at", Value: now},
 		{Key: "review_note", Value: reviewNote},
-	})
+	}); err != nil {
+		return err
+	}
+	if err := appendEvent(c, Event{
+		Type: EventVersionRejected, Entity: cfg.typeName, RecordID: id, Version: version,
+		State: models.VersionStateRejected, Actor: reviewedBy,
+	}); err != nil {
+		return err
+	}
+	return cfg.submissionDropped(c, id)
 }
 
-// retractVersion lets the original submitter withdraw their own pending submission.
+// retractVersion lets the original submitter withdraw their own pending submission - check and
+// write in one transaction, like rejectVersion.
 func (cfg entityVersioningConfig[T]) retractVersion(c context.Context, id string, version int, submitterID string) (*T, error) {
-	submitted, err := cfg.getVersion(c, id, version)
+	var result *T
+	err := withTransaction(c, func(tc context.Context) error {
+		var err error
+		result, err = cfg.retractVersionTx(tc, id, version, submitterID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if submitted == nil {
-		return nil, fmt.Errorf("%s %s: version %d not found", cfg.typeName, id, version)
+	return result, nil
+}
+
+// retractVersionTx is retractVersion's body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) retractVersionTx(c context.Context, id string, version int, submitterID string) (*T, error) {
+	submitted, err := cfg.requireVersion(c, id, version)
+	if err != nil {
+		return nil, err
 	}
 	lc := cfg.lifecycle(submitted)
 	if lc.State != models.VersionStateSubmitted {
-		return nil, fmt.Errorf("%s %s: version %d is not submitted", cfg.typeName, id, version)
+		return nil, &InvalidStateError{Type: cfg.typeName, ID: id, Version: version, State: lc.State}
 	}
 	if lc.SubmittedBy != submitterID {
-		return nil, fmt.Errorf("%s %s: version %d was not submitted by %s", cfg.typeName, id, version, submitterID)
+		return nil, &NotSubmitterError{Type: cfg.typeName, ID: id, Version: version, Caller: submitterID}
 	}
 	if err := cfg.setVersionState(c, id, version, bson.D{{Key: "state", Value: string(models.VersionStateWithdrawn)}}); err != nil {
 		return nil, err
 	}
+	if err := appendEvent(c, Event{
+		Type: EventVersionRetracted, Entity: cfg.typeName, RecordID: id, Version: version,
+		State: models.VersionStateWithdrawn, Actor: submitterID,
+	}); err != nil {
+		return nil, err
+	}
+	if err := cfg.submissionDropped(c, id); err != nil {
+		return nil, err
+	}
 	lc.State = models.VersionStateWithdrawn
+	cfg.setLifecycle(submitted, lc)
 	return submitted, nil
 }
 
-// setCurrentVersion rolls a record back (or forward) to an arbitrary existing version.
-func (cfg entityVersioningConfig[T]) setCurrentVersion(c context.Context, id string, version int) (*T, error) {
-	meta, err := cfg.getMeta(c, id)
+// submissionDropped runs cfg's onDropped, if it has one, for record id.
+func (cfg entityVersioningConfig[T]) submissionDropped(c context.Context, id string) error {
+	if cfg.onDropped == nil {
+		return nil
+	}
+	return cfg.onDropped(c, id)
+}
+
+// setCurrentVersion rolls a record back (or forward) to an arbitrary existing version, in one
+// transaction - failing with a *ConflictError if a non-nil expectedCurrentVersion is stale.
+func (cfg entityVersioningConfig[T]) setCurrentVersion(c context.Context, id string, version int, expectedCurrentVersion *int) (*T, error) {
+	var result *T
+	err := withTransaction(c, func(tc context.Context) error {
+		var err error
+		result, err = cfg.setCurrentVersionTx(tc, id, version, expectedCurrentVersion)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if meta == nil {
-		return nil, nil
-	}
-	target, err := cfg.getVersion(c, id, version)
+	return result, nil
+}
+
+// setCurrentVersionTx is setCurrentVersion's body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) setCurrentVersionTx(c context.Context, id string, version int, expectedCurrentVersion *int) (*T, error) {
+	meta, err := cfg.requireMeta(c, id)
 	if err != nil {
 		return nil, err
 	}
-	if target == nil {
-		return nil, fmt.Errorf("%s %s: version %d not found", cfg.typeName, id, version)
+	if err := cfg.checkExpectedCurrentVersion(meta, expectedCurrentVersion); err != nil {
+		return nil, err
+	}
+	target, err := cfg.requireVersion(c, id, version)
+	if err != nil {
+		return nil, err
 	}
 	if version == meta.CurrentVersion {
 		return target, nil
 	}
+	previousState := cfg.lifecycle(target).State
+	if err := cfg.transitionVersion(c, id, version, previousState, models.VersionStateLive); err != nil {
+		return nil, err
+	}
+	if err := cfg.swapCurrentVersion(c, id, meta.CurrentVersion, version, func() error {
+		return cfg.transitionVersion(c, id, version, models.VersionStateLive, previousState)
+	}); err != nil {
+		return nil, err
+	}
 	if err := cfg.archiveVersion(c, id, meta.CurrentVersion); err != nil {
 		return nil, err
 	}
-	if err := cfg.setVersionState(c, id, version, bson.D{{Key: "state", Value: string(models.VersionStateLive)}}); err != nil {
+	if err := cfg.publishLive(c, id, target, meta.DeletedAt != nil); err != nil {
 		return nil, err
 	}
-	if err := cfg.setMetaCurrentVersion(c, id, version); err != nil {
+	if err := appendEvent(c, Event{
+		Type: EventCurrentVersionChanged, Entity: cfg.typeName, RecordID: id, Version: version,
+		State: models.VersionStateLive, CurrentVersion: &version,
+	}); err != nil {
 		return nil, err
 	}
-	cfg.lifecycle(target).State = models.VersionStateLive
+	lc := cfg.lifecycle(target)
+	lc.State = models.VersionStateLive
+	cfg.setLifecycle(target, lc)
 	return target, nil
 }
 
@@ -510,22 +1101,49 @@ func (cfg entityVersioningConfig[T]) setCurrentVersion(c context.Context, id str
 // with the new deletion's stamp - matches restore's own idempotent behavior.
 func (cfg entityVersioningConfig[T]) softDelete(c context.Context, id string, deletedBy string) error {
 	now := time.Now()
-	_, err := database.Db.Collection(cfg.metaCollection).UpdateOne(
-		c,
-		bson.D{{Key: "_id", Value: id}},
-		bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: now}, {Key: "deleted_by", Value: deletedBy}}}},
-	)
-	return err
+	return withTransaction(c, func(tc context.Context) error {
+		matched, err := Storage.UpdateOne(
+			tc,
+			cfg.metaCollection,
+			bson.D{{Key: "_id", Value: id}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: now}, {Key: "deleted_by", Value: deletedBy}}}},
+		)
+		if err != nil || matched == 0 {
+			return err
+		}
+		if err := cfg.markSearchDeleted(tc, id, true); err != nil {
+			return err
+		}
+		if err := cfg.markRecordDeleted(tc, id, true); err != nil {
+			return err
+		}
+		return appendEvent(tc, Event{Type: EventRecordSoftDeleted, Entity: cfg.typeName, RecordID: id, Actor: deletedBy})
+	})
 }
 
 // restore clears the meta record's deleted_at/deleted_by, returning it to every normal read path.
+// A record merged away loses its redirect too; the references MergeRecords moved stay moved.
 func (cfg entityVersioningConfig[T]) restore(c context.Context, id string) error {
-	_, err := database.Db.Collection(cfg.metaCollection).UpdateOne(
-		c,
-		bson.D{{Key: "_id", Value: id}},
-		bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: nil}, {Key: "deleted_by", Value: nil}}}},
-	)
-	return err
+	return withTransaction(c, func(tc context.Context) error {
+		matched, err := Storage.UpdateOne(
+			tc,
+			cfg.metaCollection,
+			bson.D{{Key: "_id", Value: id}},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "deleted_at", Value: nil}, {Key: "deleted_by", Value: nil}, {Key: mergedIntoField, Value: nil},
+			}}},
+		)
+		if err != nil || matched == 0 {
+			return err
+		}
+		if err := cfg.markSearchDeleted(tc, id, false); err != nil {
+			return err
+		}
+		if err := cfg.markRecordDeleted(tc, id, false); err != nil {
+			return err
+		}
+		return appendEvent(tc, Event{Type: EventRecordRestored, Entity: cfg.typeName, RecordID: id})
+	})
 }
 
 // countSubmittedBySubmitter counts a submitter's currently-pending (state: submitted) versions.
@@ -534,12 +1152,12 @@ func (cfg entityVersioningConfig[T]) countSubmittedBySubmitter(c context.Context
 		{Key: "submitted_by", Value: submittedBy},
 		{Key: "state", Value: string(models.VersionStateSubmitted)},
 	}
-	return database.Db.Collection(cfg.versionCollection).CountDocuments(c, filter)
+	return Storage.Count(c, cfg.versionCollection, filter)
 }
 
-// migrationConfig wires the generic one-time backfill below (shared across publisher, studio,
-// person, license, and system - see MigrateVolumes' doc comment for the bespoke original this
-// mirrors) to one entity type's old flat collection and old/new model shapes.
+// migrationConfig wires the generic one-time backfill below (shared across volume, publisher,
+// studio, person, license, and system) to one entity type's old flat collection and old/new
+// model shapes.
 type migrationConfig[Old any, New any] struct {
 	oldCollection string
 	versioning    entityVersioningConfig[New]
@@ -553,7 +1171,7 @@ type migrationConfig[Old any, New any] struct {
 // Idempotent - a record that already has a meta record (recognized by the same ID) is left
 // untouched, so this is safe to re-run after a partial failure.
 func migrateEntity[Old any, New any](c context.Context, cfg migrationConfig[Old, New]) (int, error) {
-	all, err := database.Query[Old](cfg.oldCollection, bson.D{}, nil, nil, 0, 0)
+	all, err := storeQuery[Old](c, cfg.oldCollection, bson.D{}, nil, nil, 0, 0)
 	if err != nil {
 		return 0, fmt.Errorf("migrate %s: query existing documents: %w", cfg.oldCollection, err)
 	}
@@ -569,26 +1187,13 @@ func migrateEntity[Old any, New any](c context.Context, cfg migrationConfig[Old,
 			continue
 		}
 
-		aud := cfg.auditable(old)
-		meta := models.EntityMeta{
-			ID: id, CurrentVersion: 1, CreatedAt: aud.CreatedAt, CreatedBy: aud.CreatedBy,
-			DeletedAt: aud.DeletedAt, DeletedBy: aud.DeletedBy,
-		}
-		if _, err := database.Insert[models.EntityMeta](cfg.versioning.metaCollection, meta); err != nil {
-			return migrated, fmt.Errorf("migrate %s: insert meta for %s: %w", cfg.oldCollection, id, err)
-		}
-
-		version := cfg.toVersion(old)
-		cfg.versioning.setID(&version, primitive.NewObjectID().Hex())
-		cfg.versioning.setRecordID(&version, id)
-		cfg.versioning.setVersion(&version, 1)
-		lc := cfg.versioning.lifecycle(&version)
-		lc.State = models.VersionStateLive
-		lc.BaseVersion = nil
-		lc.SubmittedBy = aud.UpdatedBy
-		lc.SubmittedAt = aud.UpdatedAt
-		if _, err := database.Insert[New](cfg.versioning.versionCollection, version); err != nil {
-			return migrated, fmt.Errorf("migrate %s: insert version for %s: %w", cfg.oldCollection, id, err)
+		// Meta and first version go in together, so a failure can't leave a meta with no version
+		// (which a re-run would then skip as already migrated).
+		err = withTransaction(c, func(tc context.Context) error {
+			return migrateRecordTx(tc, cfg, old)
+		})
+		if err != nil {
+			return migrated, err
 		}
 
 		migrated++
@@ -596,3 +1201,37 @@ func migrateEntity[Old any, New any](c context.Context, cfg migrationConfig[Old,
 
 	return migrated, nil
 }
+
+// migrateRecordTx writes one old document's meta record and single live version under its own
+// id - migrateEntity's step, run inside its transaction.
+func migrateRecordTx[Old any, New any](c context.Context, cfg migrationConfig[Old, New], old *Old) error {
+	id := cfg.id(old)
+	aud := cfg.auditable(old)
+	meta := models.EntityMeta{
+		ID: id, CurrentVersion: 1, CreatedAt: aud.CreatedAt, CreatedBy: aud.CreatedBy,
+		DeletedAt: aud.DeletedAt, DeletedBy: aud.DeletedBy,
+	}
+	if err := storeInsert(c, cfg.versioning.metaCollection, meta); err != nil {
+		return fmt.Errorf("migrate %s: insert meta for %s: %w", cfg.oldCollection, id, err)
+	}
+
+	version := cfg.toVersion(old)
+	cfg.versioning.setID(&version, primitive.NewObjectID().Hex())
+	cfg.versioning.setRecordID(&version, id)
+	cfg.versioning.setVersion(&version, 1)
+	lc := cfg.versioning.lifecycle(&version)
+	lc.State = models.VersionStateLive
+	lc.BaseVersion = nil
+	lc.SubmittedBy = aud.UpdatedBy
+	lc.SubmittedAt = aud.UpdatedAt
+	cfg.versioning.setLifecycle(&version, lc)
+	if err := cfg.versioning.insertVersion(c, &version); err != nil {
+		return fmt.Errorf("migrate %s: insert version for %s: %w", cfg.oldCollection, id, err)
+	}
+	if aud.DeletedAt != nil {
+		if err := cfg.versioning.markRecordDeleted(c, id, true); err != nil {
+			return fmt.Errorf("migrate %s: mark %s deleted: %w", cfg.oldCollection, id, err)
+		}
+	}
+	return nil
+}
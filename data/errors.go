@@ -0,0 +1,293 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+)
+
+// Sentinels for the data package's error taxonomy - match with errors.Is to pick an HTTP status
+// without string-matching, or errors.As the typed error below each one for its details.
+// ErrNotFound and ErrVersionNotFound both typically map to a 404, ErrInvalidState, ErrConflict,
+// ErrInvalidMerge, ErrDuplicate, ErrClaimed and ErrApprovalRequired to a 409, ErrNotSubmitter to
+// a 403, ErrDanglingReference, ErrInvalidResolution, ErrInvalidRating and ErrValidation to a 422,
+// ErrCapExceeded to a 429.
+var (
+	// ErrNotFound: no record with the given id (see NotFoundError).
+	ErrNotFound = errors.New("not found")
+	// ErrVersionNotFound: the record exists but has no such version (see VersionNotFoundError).
+	ErrVersionNotFound = errors.New("version not found")
+	// ErrInvalidState: the version isn't in a state the operation applies to, e.g. accepting a
+	// version that was already rejected (see InvalidStateError).
+	ErrInvalidState = errors.New("invalid version state")
+	// ErrNotSubmitter: the caller isn't the version's original submitter (see NotSubmitterError).
+	ErrNotSubmitter = errors.New("not the submitter")
+	// ErrConflict: the record changed underneath the caller (see ConflictError).
+	ErrConflict = errors.New("conflict")
+	// ErrHasDependents: a CascadeRefuse hard delete found documents still referencing the record
+	// (see DependencyError).
+	ErrHasDependents = errors.New("record has dependents")
+	// ErrDanglingReference: a write carried relation ids that don't resolve to live records (see
+	// ReferenceError).
+	ErrDanglingReference = errors.New("dangling reference")
+	// ErrInvalidResolution: an accept's conflict resolution map has an entry it can't apply (see
+	// ResolutionError).
+	ErrInvalidResolution = errors.New("invalid conflict resolution")
+	// ErrInvalidMerge: a MergeRecords call can't go ahead given the two records' state (see
+	// MergeError).
+	ErrInvalidMerge = errors.New("invalid merge")
+	// ErrDuplicate: a write would create a second record where only one is allowed (see
+	// DuplicateError).
+	ErrDuplicate = errors.New("duplicate")
+	// ErrInvalidRating: a review's rating is outside MinRating..MaxRating (see RatingError).
+	ErrInvalidRating = errors.New("invalid rating")
+	// ErrValidation: a write's input breaks a rule on one of its fields (see ValidationError).
+	ErrValidation = errors.New("validation failed")
+	// ErrClaimed: another reviewer holds an unexpired claim on the submitted version (see
+	// ClaimError).
+	ErrClaimed = errors.New("claimed by another reviewer")
+	// ErrApprovalRequired: the type's approval policy isn't satisfied yet (see ApprovalError).
+	ErrApprovalRequired = errors.New("approval required")
+	// ErrCapExceeded: the submitter already has as much in flight as the type's SubmissionCap
+	// allows (see CapExceededError).
+	ErrCapExceeded = errors.New("submission cap exceeded")
+)
+
+// NotFoundError reports a missing record - a meta record for the versioned types, a plain
+// document for contributions/reviews, or a gamesystems-api 404 for systems.
+type NotFoundError struct {
+	Type string // entity type, e.g. "publisher"
+	ID   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s: not found", e.Type, e.ID)
+}
+
+func (e *NotFoundError) Is(target error) bool { return target == ErrNotFound }
+
+// VersionNotFoundError reports a record that exists but has no version numbered Version.
+type VersionNotFoundError struct {
+	Type    string
+	ID      string
+	Version int
+}
+
+func (e *VersionNotFoundError) Error() string {
+	return fmt.Sprintf("%s %s: version %d not found", e.Type, e.ID, e.Version)
+}
+
+func (e *VersionNotFoundError) Is(target error) bool { return target == ErrVersionNotFound }
+
+// InvalidStateError reports a lifecycle operation attempted on a version in the wrong state -
+// State is the version's actual current state, so a caller can tell "someone else already
+// accepted this" from "the submitter withdrew it".
+type InvalidStateError struct {
+	Type    string
+	ID      string
+	Version int
+	State   models.VersionState
+}
+
+func (e *InvalidStateError) Error() string {
+	return fmt.Sprintf("%s %s: version %d is not submitted (state: %s)", e.Type, e.ID, e.Version, e.State)
+}
+
+func (e *InvalidStateError) Is(target error) bool { return target == ErrInvalidState }
+
+// NoBaseVersionError reports a submitted version with no base version recorded, which can't be
+// diffed or reviewed - matched as ErrInvalidState.
+type NoBaseVersionError struct {
+	Type    string
+	ID      string
+	Version int
+}
+
+func (e *NoBaseVersionError) Error() string {
+	return fmt.Sprintf("%s %s: version %d has no base version to review against", e.Type, e.ID, e.Version)
+}
+
+func (e *NoBaseVersionError) Is(target error) bool { return target == ErrInvalidState }
+
+// NotSubmitterError reports a retract attempted by someone other than the version's submitter.
+type NotSubmitterError struct {
+	Type    string
+	ID      string
+	Version int
+	Caller  string
+}
+
+func (e *NotSubmitterError) Error() string {
+	return fmt.Sprintf("%s %s: version %d was not submitted by %s", e.Type, e.ID, e.Version, e.Caller)
+}
+
+func (e *NotSubmitterError) Is(target error) bool { return target == ErrNotSubmitter }
+
+// ConflictError is returned by an Update*/Accept*/SetCurrent* call whose expectedCurrentVersion
+// precondition failed - the record's current version moved underneath the caller (another
+// editor's live update, accept, or rollback landed first). Callers typically re-read the record
+// and retry, or surface it as an HTTP 409.
+type ConflictError struct {
+	Type            string // entity type, e.g. "publisher"
+	ID              string
+	ExpectedVersion int
+	CurrentVersion  int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s: expected current version %d, found %d", e.Type, e.ID, e.ExpectedVersion, e.CurrentVersion)
+}
+
+func (e *ConflictError) Is(target error) bool { return target == ErrConflict }
+
+// Dependent is one document still referencing a record a hard delete was asked to purge.
+type Dependent struct {
+	Type string // e.g. "contribution", "review", "volume"
+	ID   string
+}
+
+// DependencyError is a CascadeRefuse hard delete's refusal, listing everything that still
+// references the record so the caller can show it or retry with another CascadePolicy.
+type DependencyError struct {
+	Type       string
+	ID         string
+	Dependents []Dependent
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("%s %s: still referenced by %d dependent(s)", e.Type, e.ID, len(e.Dependents))
+}
+
+func (e *DependencyError) Is(target error) bool { return target == ErrHasDependents }
+
+// ReferenceError is a ReferenceValidationReject refusal, listing every relation id on the write
+// that is missing or soft-deleted. ID is empty for an AddVolume, where no record exists yet.
+type ReferenceError struct {
+	Type       string
+	ID         string
+	References []DanglingReference
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s %s: %d dangling reference(s)", e.Type, e.ID, len(e.References))
+}
+
+func (e *ReferenceError) Is(target error) bool { return target == ErrDanglingReference }
+
+// ResolutionError reports the first unusable entry in an accept's conflict resolution map.
+type ResolutionError struct {
+	Type   string
+	ID     string
+	Field  string
+	Reason string
+}
+
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("%s %s: resolution for %s: %s", e.Type, e.ID, e.Field, e.Reason)
+}
+
+func (e *ResolutionError) Is(target error) bool { return target == ErrInvalidResolution }
+
+// MergeError reports a MergeRecords call refused before anything was written - merging a record
+// into itself or into a deleted record, or merging one that was already merged away.
+type MergeError struct {
+	Type       string
+	SurvivorID string
+	LoserID    string
+	Reason     string
+}
+
+func (e *MergeError) Error() string {
+	return fmt.Sprintf("%s %s into %s: %s", e.Type, e.LoserID, e.SurvivorID, e.Reason)
+}
+
+func (e *MergeError) Is(target error) bool { return target == ErrInvalidMerge }
+
+// DuplicateError reports a write refused by a uniqueness rule, e.g. a second review of a volume by
+// the same author. ExistingID is the record already holding the slot, when known.
+type DuplicateError struct {
+	Type       string
+	ExistingID string
+	Reason     string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate %s: %s", e.Type, e.Reason)
+}
+
+func (e *DuplicateError) Is(target error) bool { return target == ErrDuplicate }
+
+// RatingError reports a review rating outside MinRating..MaxRating (0, unrated, is allowed).
+type RatingError struct {
+	Rating int
+}
+
+func (e *RatingError) Error() string {
+	return fmt.Sprintf("rating %d is outside %d..%d", e.Rating, MinRating, MaxRating)
+}
+
+func (e *RatingError) Is(target error) bool { return target == ErrInvalidRating }
+
+// ValidationError reports a write refused over one input field, e.g. a contribution role outside
+// the vocabulary.
+type ValidationError struct {
+	Type   string
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Type, e.Field, e.Reason)
+}
+
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+// ClaimError reports a review action on a submitted version that another reviewer has claimed
+// (see ClaimVersion), naming them and when their claim lapses.
+type ClaimError struct {
+	Type      string
+	ID        string
+	Version   int
+	ClaimedBy string
+	ExpiresAt time.Time
+}
+
+func (e *ClaimError) Error() string {
+	return fmt.Sprintf("%s %s: version %d is claimed by %s until %s", e.Type, e.ID, e.Version, e.ClaimedBy, e.ExpiresAt.Format(time.RFC3339))
+}
+
+func (e *ClaimError) Is(target error) bool { return target == ErrClaimed }
+
+// ApprovalError reports an accept or approval the type's ApprovalPolicy doesn't allow yet - or at
+// all, for a submitter approving their own change. Reason says what's missing.
+type ApprovalError struct {
+	Type    string
+	ID      string
+	Version int
+	Reason  string
+}
+
+func (e *ApprovalError) Error() string {
+	return fmt.Sprintf("%s %s: version %d can't be accepted: %s", e.Type, e.ID, e.Version, e.Reason)
+}
+
+func (e *ApprovalError) Is(target error) bool { return target == ErrApprovalRequired }
+
+// CapExceededError reports a submission refused by the type's SubmissionCap. Limit is the cap it
+// ran into and Max that cap's value; Counts is where the submitter stood, for telling them why.
+type CapExceededError struct {
+	Type        string
+	ID          string
+	SubmittedBy string
+	Limit       SubmissionCapLimit
+	Max         int
+	Counts      SubmissionCounts
+}
+
+func (e *CapExceededError) Error() string {
+	return fmt.Sprintf("%s %s: %s is at the %s submission cap of %d", e.Type, e.ID, e.SubmittedBy, e.Limit, e.Max)
+}
+
+func (e *CapExceededError) Is(target error) bool { return target == ErrCapExceeded }
@@ -1,16 +1,12 @@
 package data
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
-	"github.com/sweetrpg/common.go/logging"
-	"github.com/sweetrpg/mongodb.go/constants"
-	"github.com/sweetrpg/mongodb.go/database"
 )
 
 type LicenseDataTestSuite struct {
@@ -19,9 +15,7 @@ type LicenseDataTestSuite struct {
 }
 
 func (suite *LicenseDataTestSuite) SetupTest() {
-	_ = os.Setenv(constants.DB_URI, os.Getenv("TEST_DB_URI"))
-	logging.Init()
-	database.SetupDatabase()
+	setupTestStorage()
 	assert.NoError(suite.T(), EnsureLicenseVersioningIndexes(suite.T().Context()))
 
 	id, err := AddLicense(suite.T().Context(), &vo.LicenseVO{Title: "Test License", Status: "draft"})
@@ -33,7 +27,7 @@ func (suite *LicenseDataTestSuite) SetupTest() {
 func (suite *LicenseDataTestSuite) TestUpdateLicenseLive() {
 	updated, err := UpdateLicense(suite.T().Context(), suite.seedLicenseID, &vo.LicenseVO{
 		Title: "Updated License", Status: "active",
-	}, models.VersionStateLive)
+	}, models.VersionStateLive, nil)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updated)
 	assert.Equal(suite.T(), "Updated License", updated.Title)
@@ -48,19 +42,19 @@ func (suite *LicenseDataTestSuite) TestUpdateLicenseLive() {
 func (suite *LicenseDataTestSuite) TestUpdateLicenseNotFound() {
 	updated, err := UpdateLicense(suite.T().Context(), "does-not-exist", &vo.LicenseVO{
 		Title: "Doesn't Matter",
-	}, models.VersionStateLive)
-	assert.NoError(suite.T(), err)
+	}, models.VersionStateLive, nil)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
 	assert.Nil(suite.T(), updated)
 }
 
 func (suite *LicenseDataTestSuite) TestAcceptLicenseVersionFullAccept() {
 	submitted, err := UpdateLicense(suite.T().Context(), suite.seedLicenseID, &vo.LicenseVO{
 		Title: "Proposed License",
-	}, models.VersionStateSubmitted)
+	}, models.VersionStateSubmitted, nil)
 	assert.NoError(suite.T(), err)
 
 	accepted, conflicts, err := AcceptLicenseVersion(
-		suite.T().Context(), suite.seedLicenseID, submitted.Version, nil, "editor-1", nil)
+		suite.T().Context(), suite.seedLicenseID, submitted.Version, nil, nil, "editor-1", nil, nil)
 	assert.NoError(suite.T(), err)
 	assert.Empty(suite.T(), conflicts)
 	assert.Equal(suite.T(), string(models.VersionStateLive), string(accepted.State))
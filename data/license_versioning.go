@@ -3,7 +3,6 @@ package data
 import (
 	"context"
 	"net/url"
-	"strings"
 	"time"
 
 	apiutil "github.com/sweetrpg/api-core.go/util"
@@ -12,7 +11,6 @@ import (
 	modelcore "github.com/sweetrpg/model-core.go/models"
 	modelcoreutil "github.com/sweetrpg/model-core.go/util"
 	modelcorevo "github.com/sweetrpg/model-core.go/vo"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -21,14 +19,22 @@ const (
 	licenseVersionCollection = "licenses_versions"
 )
 
+// LicenseApprovalPolicy is VolumeApprovalPolicy for licenses.
+var LicenseApprovalPolicy ApprovalPolicy
+
+// LicenseSubmissionCap is VolumeSubmissionCap for licenses.
+var LicenseSubmissionCap SubmissionCap
+
 var licenseVersioning = entityVersioningConfig[models.LicenseVersion]{
 	metaCollection:    licenseMetaCollection,
 	versionCollection: licenseVersionCollection,
 	typeName:          "license",
-	lifecycle:         func(v *models.LicenseVersion) *models.VersionLifecycle { return &v.VersionLifecycle },
+	lifecycle:         func(v *models.LicenseVersion) models.VersionLifecycle { return v.VersionLifecycle },
+	setLifecycle:      func(v *models.LicenseVersion, lc models.VersionLifecycle) { v.VersionLifecycle = lc },
 	setID:             func(v *models.LicenseVersion, id string) { v.ID = id },
 	setRecordID:       func(v *models.LicenseVersion, id string) { v.RecordID = id },
 	setVersion:        func(v *models.LicenseVersion, n int) { v.Version = n },
+	version:           func(v *models.LicenseVersion) int { return v.Version },
 	recordID:          func(v *models.LicenseVersion) string { return v.RecordID },
 	displayName:       func(v *models.LicenseVersion) string { return v.Title },
 	fields: map[string]entityFieldAccessor[models.LicenseVersion]{
@@ -41,9 +47,17 @@ var licenseVersioning = entityVersioningConfig[models.LicenseVersion]{
 		"status":        {get: func(v *models.LicenseVersion) any { return v.Status }, set: func(v *models.LicenseVersion, val any) { v.Status = val.(string) }},
 		"availability":  {get: func(v *models.LicenseVersion) any { return v.Availability }, set: func(v *models.LicenseVersion, val any) { v.Availability = val.(string) }},
 		"notes":         {get: func(v *models.LicenseVersion) any { return v.Notes }, set: func(v *models.LicenseVersion, val any) { v.Notes = val.(string) }},
-		"properties":    {get: func(v *models.LicenseVersion) any { return v.Properties }, set: func(v *models.LicenseVersion, val any) { v.Properties = val.([]modelcore.Property) }},
-		"tags":          {get: func(v *models.LicenseVersion) any { return v.Tags }, set: func(v *models.LicenseVersion, val any) { v.Tags = val.([]modelcore.Tag) }},
+		"properties":    {get: func(v *models.LicenseVersion) any { return v.Properties }, set: func(v *models.LicenseVersion, val any) { v.Properties = val.([]modelcore.Property) }, setMerge: true},
+		"tags":          {get: func(v *models.LicenseVersion) any { return v.Tags }, set: func(v *models.LicenseVersion, val any) { v.Tags = val.([]modelcore.Tag) }, setMerge: true},
+	},
+	references: []entityReference{
+		{dependentType: "volume", collection: volumeVersionCollection, field: "license_ids", listed: true},
+	},
+	search: func(v *models.LicenseVersion) (string, []string) {
+		return v.Title, append([]string{v.ShortTitle}, tagSearchText(v.Tags)...)
 	},
+	approvalPolicy: &LicenseApprovalPolicy,
+	submissionCap:  &LicenseSubmissionCap,
 }
 
 // EnsureLicenseVersioningIndexes creates the indexes license version queries rely on. Safe to
@@ -58,6 +72,12 @@ func SoftDeleteLicense(c context.Context, id string, deletedBy string) error {
 	return licenseVersioning.softDelete(c, id, deletedBy)
 }
 
+// DeleteLicense hard-deletes a license and its version history, handling volume versions still
+// listing it in license_ids per policy - see DeleteVolume.
+func DeleteLicense(c context.Context, id string, policy CascadePolicy) error {
+	return licenseVersioning.purge(c, id, policy)
+}
+
 // RestoreLicense clears a soft-deleted license's deletion, returning it to every normal read path.
 func RestoreLicense(c context.Context, id string) error {
 	return licenseVersioning.restore(c, id)
@@ -111,30 +131,20 @@ func AddLicense(c context.Context, license *vo.LicenseVO) (*string, error) {
 	return licenseVersioning.addEntity(c, &version, license.CreatedBy)
 }
 
-// GetLicense returns the flattened view of a license.
+// GetLicense returns the flattened view of a license, following merge redirects like GetPublisher.
 func GetLicense(c context.Context, id string) (*vo.LicenseVO, error) {
-	meta, err := licenseVersioning.getMeta(c, id)
-	if err != nil {
-		return nil, err
-	}
-	if meta == nil {
-		return nil, nil
-	}
-	version, err := licenseVersioning.getVersion(c, id, meta.CurrentVersion)
+	meta, version, err := licenseVersioning.getCurrentFollowingMerges(c, id)
 	if err != nil {
 		return nil, err
 	}
-	if version == nil {
-		return nil, nil
-	}
 	return flattenLicense(meta, version), nil
 }
 
 // UpdateLicense creates a new version of the license rather than mutating in place.
-func UpdateLicense(c context.Context, id string, license *vo.LicenseVO, state models.VersionState) (*vo.LicenseVersionVO, error) {
+func UpdateLicense(c context.Context, id string, license *vo.LicenseVO, state models.VersionState, expectedCurrentVersion *int) (*vo.LicenseVersionVO, error) {
 	version := licenseVersionFields(license)
-	result, err := licenseVersioning.createVersion(c, id, &version, state, license.UpdatedBy)
-	if err != nil || result == nil {
+	result, err := licenseVersioning.createVersion(c, id, &version, state, license.UpdatedBy, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return licenseVersionToVO(result), nil
@@ -155,22 +165,41 @@ func ListLicenseVersions(c context.Context, id string) ([]*vo.LicenseVersionVO,
 
 // GetLicenseVersion returns one version's full snapshot.
 func GetLicenseVersion(c context.Context, id string, version int) (*vo.LicenseVersionVO, error) {
-	result, err := licenseVersioning.getVersion(c, id, version)
-	if err != nil || result == nil {
+	result, err := licenseVersioning.requireVersion(c, id, version)
+	if err != nil {
 		return nil, err
 	}
 	return licenseVersionToVO(result), nil
 }
 
+// DiffLicenseVersions is DiffVolumeVersions for a license.
+func DiffLicenseVersions(c context.Context, id string, from, to int) (*VersionDiff, error) {
+	return licenseVersioning.diffVersions(c, id, from, to)
+}
+
+// DiffLicenseSubmission is DiffVolumeSubmission for a license, previewing AcceptLicenseVersion.
+func DiffLicenseSubmission(c context.Context, id string, version int) (*SubmissionDiff, error) {
+	return licenseVersioning.diffSubmission(c, id, version)
+}
+
 // AcceptLicenseVersion reviews a submitted version - see AcceptVolumeVersion's doc comment.
-func AcceptLicenseVersion(c context.Context, id string, version int, selectedFields []string, reviewedBy string, reviewNote *string) (*vo.LicenseVersionVO, []string, error) {
-	result, conflicts, err := licenseVersioning.acceptVersion(c, id, version, selectedFields, reviewedBy, reviewNote)
-	if err != nil || result == nil {
+func AcceptLicenseVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*vo.LicenseVersionVO, []string, error) {
+	result, conflicts, err := licenseVersioning.acceptVersion(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, expectedCurrentVersion)
+	if err != nil {
 		return nil, conflicts, err
 	}
 	return licenseVersionToVO(result), conflicts, nil
 }
 
+// PreviewAcceptLicenseVersion is PreviewAcceptVolumeVersion for a license.
+func PreviewAcceptLicenseVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*AcceptPreview[vo.LicenseVersionVO], error) {
+	plan, err := licenseVersioning.previewAccept(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, nil, expectedCurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	return newAcceptPreview(plan, licenseVersionToVO), nil
+}
+
 // RejectLicenseVersion marks a submitted version rejected.
 func RejectLicenseVersion(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
 	return licenseVersioning.rejectVersion(c, id, version, reviewedBy, reviewNote)
@@ -179,16 +208,16 @@ func RejectLicenseVersion(c context.Context, id string, version int, reviewedBy
 // RetractLicenseVersion lets the original submitter withdraw their own pending submission.
 func RetractLicenseVersion(c context.Context, id string, version int, submitterID string) (*vo.LicenseVersionVO, error) {
 	result, err := licenseVersioning.retractVersion(c, id, version, submitterID)
-	if err != nil || result == nil {
+	if err != nil {
 		return nil, err
 	}
 	return licenseVersionToVO(result), nil
 }
 
 // SetCurrentLicenseVersion rolls a license back (or forward) to an arbitrary existing version.
-func SetCurrentLicenseVersion(c context.Context, id string, version int) (*vo.LicenseVersionVO, error) {
-	result, err := licenseVersioning.setCurrentVersion(c, id, version)
-	if err != nil || result == nil {
+func SetCurrentLicenseVersion(c context.Context, id string, version int, expectedCurrentVersion *int) (*vo.LicenseVersionVO, error) {
+	result, err := licenseVersioning.setCurrentVersion(c, id, version, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return licenseVersionToVO(result), nil
@@ -203,7 +232,7 @@ func CountSubmittedLicenseVersionsBySubmitter(c context.Context, submittedBy str
 func QueryLicenses(c context.Context, params apiutil.QueryParams) ([]*vo.LicenseVO, error) {
 	filter, sort, projection := apiutil.ConvertQueryParams(params)
 	filter = append(filter, bson.E{Key: "deleted_at", Value: nil})
-	metas, err := database.Query[models.EntityMeta](licenseMetaCollection, filter, sort, projection, params.Start, params.Limit)
+	metas, err := storeQuery[models.EntityMeta](c, licenseMetaCollection, filter, sort, projection, params.Start, params.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -221,30 +250,17 @@ func QueryLicenses(c context.Context, params apiutil.QueryParams) ([]*vo.License
 	return vos, nil
 }
 
-// SearchLicenses finds live licenses whose title contains query (case-insensitive), scanning the
-// full collection - see data.SearchPersons for why this scans in memory rather than pushing the
-// match down to Mongo.
-func SearchLicenses(c context.Context, query string) ([]*vo.LicenseVO, error) {
-	all, err := QueryLicenses(c, apiutil.QueryParams{Limit: searchScanLimit})
-	if err != nil {
-		return nil, err
-	}
-	needle := strings.ToLower(query)
-	matches := make([]*vo.LicenseVO, 0, len(all))
-	for _, l := range all {
-		if strings.Contains(strings.ToLower(l.Title), needle) {
-			matches = append(matches, l)
-		}
-	}
-	return matches, nil
+// SearchLicenses is SearchVolumes for licenses, matching title, short title and tags.
+func SearchLicenses(c context.Context, query string, start, limit int) ([]*vo.LicenseVO, error) {
+	return searchEntity(c, "license", query, start, limit, resolveLicenses)
 }
 
 // CreateSubmittedLicenseVersion creates a submitted version stamped with a caller-supplied
 // submittedBy/submittedAt - see CreateSubmittedPublisherVersion's doc comment.
 func CreateSubmittedLicenseVersion(c context.Context, id string, license *vo.LicenseVO, submittedBy string, submittedAt time.Time) (*vo.LicenseVersionVO, error) {
 	version := licenseVersionFields(license)
-	result, err := licenseVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt)
-	if err != nil || result == nil {
+	result, err := licenseVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt, nil)
+	if err != nil {
 		return nil, err
 	}
 	return licenseVersionToVO(result), nil
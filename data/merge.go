@@ -0,0 +1,311 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// mergedIntoField is the meta-record field MergeRecords sets on the losing record, pointing at
+// the surviving one. Not on models.EntityMeta, so read through mergeRedirect.
+const mergedIntoField = "merged_into"
+
+// maxMergeHops bounds how many merged_into pointers a Get* follows. MergeRecords repoints older
+// redirects at each new survivor, so one hop is all a consistent database ever needs.
+const maxMergeHops = 5
+
+// duplicateThreshold is the nameSimilarity at or above which FindLikelyDuplicate* reports a pair.
+const duplicateThreshold = 0.85
+
+// nameNoiseWords are words that don't tell two organisations apart ("Chaosium" and "Chaosium
+// Inc." are the same publisher), dropped before names are compared.
+var nameNoiseWords = map[string]bool{
+	"the": true, "inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"co": true, "corp": true, "corporation": true, "company": true, "gmbh": true,
+}
+
+// mergeRedirect is the projection a merged record's redirect is read through.
+type mergeRedirect struct {
+	MergedInto string `bson:"merged_into"`
+}
+
+// DuplicatePair is two live records of one type whose names are likely the same thing, with
+// their nameSimilarity (1 for identical normalized names). ID sorts before OtherID.
+type DuplicatePair struct {
+	Type      string
+	ID        string
+	Name      string
+	OtherID   string
+	OtherName string
+	Score     float64
+}
+
+// comparableName is NormalizeName(name) without nameNoiseWords.
+func comparableName(name string) string {
+	words := strings.Fields(NormalizeName(name))
+	kept := words[:0]
+	for _, word := range words {
+		if !nameNoiseWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// nameSimilarity scores two comparableNames from 0 to 1: the better of their edit-distance
+// similarity, which catches typos ("Chaosuim"), and their word-set overlap, which catches
+// reordering ("Smith, Jo" against "Jo Smith").
+func nameSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+	edit := 1 - float64(editDistance(ra, rb))/float64(longest)
+
+	wa, wb := map[string]bool{}, map[string]bool{}
+	for _, w := range strings.Fields(a) {
+		wa[w] = true
+	}
+	for _, w := range strings.Fields(b) {
+		wb[w] = true
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	overlap := 0.0
+	if union := len(wa) + len(wb) - shared; union > 0 {
+		overlap = float64(shared) / float64(union)
+	}
+	return max(edit, overlap)
+}
+
+// editDistance is the edit distance between a and b, counting an insertion, deletion,
+// substitution or swap of two adjacent letters as one edit (optimal string alignment).
+func editDistance(a, b []rune) int {
+	older := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], older[j-2]+1)
+			}
+		}
+		older, prev, cur = prev, cur, older
+	}
+	return prev[len(b)]
+}
+
+// findLikelyDuplicates compares every pair of live records by name. Quadratic in the number of
+// records, which is fine at the few hundred per type the catalog has.
+func (cfg entityVersioningConfig[T]) findLikelyDuplicates(c context.Context) ([]*DuplicatePair, error) {
+	metas, err := storeQuery[models.EntityMeta](c, cfg.metaCollection, bson.D{{Key: "deleted_at", Value: nil}}, nil, bson.D{{Key: "_id", Value: 1}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(metas))
+	for _, meta := range metas {
+		ids = append(ids, meta.ID)
+	}
+	_, versions, err := cfg.getCurrentMany(c, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct{ id, name, key string }
+	candidates := make([]candidate, 0, len(versions))
+	for id, version := range versions {
+		name := cfg.displayName(version)
+		candidates = append(candidates, candidate{id: id, name: name, key: comparableName(name)})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].id < candidates[j].id })
+
+	pairs := make([]*DuplicatePair, 0)
+	for i, a := range candidates {
+		for _, b := range candidates[i+1:] {
+			if a.key == "" || b.key == "" {
+				continue
+			}
+			if score := nameSimilarity(a.key, b.key); score >= duplicateThreshold {
+				pairs = append(pairs, &DuplicatePair{Type: cfg.typeName, ID: a.id, Name: a.name, OtherID: b.id, OtherName: b.name, Score: score})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Score > pairs[j].Score })
+	return pairs, nil
+}
+
+// FindLikelyDuplicatePublishers lists pairs of live publishers whose names are probably the same
+// publisher - equal once normalized and stripped of words like "Inc.", or within a typo or a word
+// order of each other - best match first. Candidates for MergeRecords, for a human to confirm.
+func FindLikelyDuplicatePublishers(c context.Context) ([]*DuplicatePair, error) {
+	return publisherVersioning.findLikelyDuplicates(c)
+}
+
+// FindLikelyDuplicateStudios is FindLikelyDuplicatePublishers for studios.
+func FindLikelyDuplicateStudios(c context.Context) ([]*DuplicatePair, error) {
+	return studioVersioning.findLikelyDuplicates(c)
+}
+
+// FindLikelyDuplicatePersons is FindLikelyDuplicatePublishers for persons.
+func FindLikelyDuplicatePersons(c context.Context) ([]*DuplicatePair, error) {
+	return personVersioning.findLikelyDuplicates(c)
+}
+
+// FindLikelyDuplicateLicenses is FindLikelyDuplicatePublishers for licenses.
+func FindLikelyDuplicateLicenses(c context.Context) ([]*DuplicatePair, error) {
+	return licenseVersioning.findLikelyDuplicates(c)
+}
+
+// mergedInto returns the record id merges into, or "" if it hasn't been merged.
+func (cfg entityVersioningConfig[T]) mergedInto(c context.Context, id string) (string, error) {
+	projection := bson.D{{Key: mergedIntoField, Value: 1}}
+	results, err := storeQuery[mergeRedirect](c, cfg.metaCollection, bson.D{{Key: "_id", Value: id}}, nil, projection, 0, 1)
+	if err != nil || len(results) == 0 {
+		return "", err
+	}
+	return results[0].MergedInto, nil
+}
+
+// getCurrentFollowingMerges is getCurrent for a Get* read: an id that was merged away resolves
+// to the record it was merged into.
+func (cfg entityVersioningConfig[T]) getCurrentFollowingMerges(c context.Context, id string) (*models.EntityMeta, *T, error) {
+	for hop := 0; hop < maxMergeHops; hop++ {
+		target, err := cfg.mergedInto(c, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if target == "" {
+			break
+		}
+		id = target
+	}
+	return cfg.getCurrent(c, id)
+}
+
+// merge folds loserID into survivorID in one transaction: every reference to the loser (see
+// cfg.references) is rewritten to the survivor, in every version, so no rollback can bring the
+// loser's id back; the loser is soft-deleted with a merged_into redirect; and any record
+// previously merged into the loser is repointed at the survivor.
+func (cfg entityVersioningConfig[T]) merge(c context.Context, survivorID, loserID, mergedBy string) error {
+	return withTransaction(c, func(tc context.Context) error {
+		return cfg.mergeTx(tc, survivorID, loserID, mergedBy)
+	})
+}
+
+// mergeTx is merge's body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) mergeTx(c context.Context, survivorID, loserID, mergedBy string) error {
+	refuse := func(reason string) error {
+		return &MergeError{Type: cfg.typeName, SurvivorID: survivorID, LoserID: loserID, Reason: reason}
+	}
+	if survivorID == loserID {
+		return refuse("a record can't be merged into itself")
+	}
+	survivor, err := cfg.requireMeta(c, survivorID)
+	if err != nil {
+		return err
+	}
+	if survivor.DeletedAt != nil {
+		return refuse("the surviving record is deleted")
+	}
+	if _, err := cfg.requireMeta(c, loserID); err != nil {
+		return err
+	}
+	if target, err := cfg.mergedInto(c, loserID); err != nil {
+		return err
+	} else if target != "" {
+		return refuse("already merged into " + target)
+	}
+
+	for _, ref := range cfg.references {
+		if ref.fold != nil {
+			if err := ref.fold(c, survivorID, loserID, mergedBy); err != nil {
+				return err
+			}
+			continue
+		}
+		filter := bson.D{{Key: ref.field, Value: loserID}}
+		if ref.listed {
+			// Two passes - Mongo won't $addToSet and $pull the same array in one update.
+			add := bson.D{{Key: "$addToSet", Value: bson.D{{Key: ref.field, Value: survivorID}}}}
+			if _, err := Storage.UpdateMany(c, ref.collection, filter, add); err != nil {
+				return err
+			}
+			pull := bson.D{{Key: "$pull", Value: bson.D{{Key: ref.field, Value: loserID}}}}
+			if _, err := Storage.UpdateMany(c, ref.collection, filter, pull); err != nil {
+				return err
+			}
+			continue
+		}
+		repoint := bson.D{{Key: "$set", Value: bson.D{{Key: ref.field, Value: survivorID}}}}
+		if _, err := Storage.UpdateMany(c, ref.collection, filter, repoint); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	_, err = Storage.UpdateOne(c, cfg.metaCollection, bson.D{{Key: "_id", Value: loserID}}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "deleted_at", Value: now}, {Key: "deleted_by", Value: mergedBy}, {Key: mergedIntoField, Value: survivorID},
+	}}})
+	if err != nil {
+		return err
+	}
+	_, err = Storage.UpdateMany(c, cfg.metaCollection,
+		bson.D{{Key: mergedIntoField, Value: loserID}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: mergedIntoField, Value: survivorID}}}})
+	if err != nil {
+		return err
+	}
+	if err := cfg.markSearchDeleted(c, loserID, true); err != nil {
+		return err
+	}
+	if err := cfg.markRecordDeleted(c, loserID, true); err != nil {
+		return err
+	}
+	return appendEvent(c, Event{Type: EventRecordMerged, Entity: cfg.typeName, RecordID: loserID, MergedInto: survivorID, Actor: mergedBy})
+}
+
+// mergeableEntities are the types MergeRecords accepts, by entity name.
+var mergeableEntities = map[string]interface {
+	merge(c context.Context, survivorID, loserID, mergedBy string) error
+}{
+	"publisher": publisherVersioning,
+	"studio":    studioVersioning,
+	"person":    personVersioning,
+	"license":   licenseVersioning,
+}
+
+// MergeRecords folds a duplicate record (loserID) into the one that survives, for entity
+// "publisher", "studio", "person" or "license". Volume versions' publisher_ids/studio_ids/
+// license_ids and contributions' person_id are rewritten from the loser to the survivor across
+// all history, and the loser is soft-deleted with a redirect, so Get* on its id returns the
+// survivor from then on. All in one transaction. Merging into a deleted record, or merging a
+// record that was already merged, is a *MergeError.
+func MergeRecords(c context.Context, entity, survivorID, loserID, mergedBy string) error {
+	cfg, ok := mergeableEntities[entity]
+	if !ok {
+		return fmt.Errorf("merge: %s records can't be merged", entity)
+	}
+	return cfg.merge(c, survivorID, loserID, mergedBy)
+}
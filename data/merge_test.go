@@ -0,0 +1,137 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MergeTestSuite covers duplicate detection and MergeRecords. Names carry a fresh marker so
+// records left by other tests on a shared database don't pair up with this test's.
+type MergeTestSuite struct {
+	suite.Suite
+	marker string
+}
+
+func (suite *MergeTestSuite) SetupTest() {
+	setupTestStorage()
+	assert.NoError(suite.T(), EnsureSearchIndexes(suite.T().Context()))
+	assert.NoError(suite.T(), EnsureVolumeVersioningIndexes(suite.T().Context()))
+	assert.NoError(suite.T(), EnsureContributionVersioningIndexes(suite.T().Context()))
+	suite.marker = "m" + primitive.NewObjectID().Hex()
+}
+
+func (suite *MergeTestSuite) TestNameSimilarity() {
+	assert.Equal(suite.T(), 1.0, nameSimilarity(comparableName("Chaosium Inc."), comparableName("chaosium")))
+	assert.GreaterOrEqual(suite.T(), nameSimilarity(comparableName("Chaosuim"), comparableName("Chaosium")), duplicateThreshold)
+	assert.Equal(suite.T(), 1.0, nameSimilarity(comparableName("Smith, Jo"), comparableName("Jo Smith")))
+	assert.Less(suite.T(), nameSimilarity(comparableName("Chaosium"), comparableName("Paizo")), duplicateThreshold)
+}
+
+func (suite *MergeTestSuite) TestFindLikelyDuplicatePublishers() {
+	ctx := suite.T().Context()
+	a, err := AddPublisher(ctx, &vo.PublisherVO{Name: suite.marker + " Chaosium Inc."})
+	assert.NoError(suite.T(), err)
+	b, err := AddPublisher(ctx, &vo.PublisherVO{Name: "The " + suite.marker + " Chaosium"})
+	assert.NoError(suite.T(), err)
+	_, err = AddPublisher(ctx, &vo.PublisherVO{Name: suite.marker + " Paizo Publishing"})
+	assert.NoError(suite.T(), err)
+
+	pairs, err := FindLikelyDuplicatePublishers(ctx)
+	assert.NoError(suite.T(), err)
+	var mine []*DuplicatePair
+	for _, pair := range pairs {
+		if pair.ID == *a || pair.ID == *b || pair.OtherID == *a || pair.OtherID == *b {
+			mine = append(mine, pair)
+		}
+	}
+	if assert.Len(suite.T(), mine, 1) {
+		assert.ElementsMatch(suite.T(), []string{*a, *b}, []string{mine[0].ID, mine[0].OtherID})
+		assert.Equal(suite.T(), "publisher", mine[0].Type)
+		assert.Equal(suite.T(), 1.0, mine[0].Score)
+	}
+}
+
+func (suite *MergeTestSuite) TestMergePublishersRewritesReferencesAndRedirects() {
+	ctx := suite.T().Context()
+	survivor, err := AddPublisher(ctx, &vo.PublisherVO{Name: suite.marker + " Chaosium"})
+	assert.NoError(suite.T(), err)
+	loser, err := AddPublisher(ctx, &vo.PublisherVO{Name: suite.marker + " Chaosium Inc."})
+	assert.NoError(suite.T(), err)
+	volumeID, err := AddVolume(ctx, &vo.VolumeVO{Title: suite.marker + " Atlas", Publishers: []*vo.PublisherVO{{ID: *loser}}})
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), MergeRecords(ctx, "publisher", *survivor, *loser, "editor-1"))
+
+	volume, err := GetVolume(ctx, *volumeID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), volume.Publishers, 1) {
+		assert.Equal(suite.T(), *survivor, volume.Publishers[0].ID)
+	}
+	got, err := GetPublisher(ctx, *loser)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *survivor, got.ID)
+	found, err := SearchPublishers(ctx, suite.marker, 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+
+	err = MergeRecords(ctx, "publisher", *survivor, *loser, "editor-1")
+	assert.ErrorIs(suite.T(), err, ErrInvalidMerge)
+	err = MergeRecords(ctx, "publisher", *survivor, *survivor, "editor-1")
+	assert.ErrorIs(suite.T(), err, ErrInvalidMerge)
+	err = MergeRecords(ctx, "volume", *survivor, *loser, "editor-1")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MergeTestSuite) TestMergePersonsMovesContributions() {
+	ctx := suite.T().Context()
+	survivor, err := AddPerson(ctx, &vo.PersonVO{Name: suite.marker + " Jo Smith"})
+	assert.NoError(suite.T(), err)
+	loser, err := AddPerson(ctx, &vo.PersonVO{Name: "Smith, " + suite.marker + " Jo"})
+	assert.NoError(suite.T(), err)
+	volumeID, err := AddVolume(ctx, &vo.VolumeVO{Title: suite.marker + " Handbook"})
+	assert.NoError(suite.T(), err)
+	contributionID, err := AddContribution(ctx, *loser, *volumeID, []string{"Author"}, "editor-1")
+	assert.NoError(suite.T(), err)
+	// Both credited on a second volume: the survivor's credit absorbs the loser's roles.
+	sharedVolumeID, err := AddVolume(ctx, &vo.VolumeVO{Title: suite.marker + " Companion"})
+	assert.NoError(suite.T(), err)
+	survivorCredit, err := AddContribution(ctx, *survivor, *sharedVolumeID, []string{"writer"}, "editor-1")
+	assert.NoError(suite.T(), err)
+	loserCredit, err := AddContribution(ctx, *loser, *sharedVolumeID, []string{"cartographer"}, "editor-1")
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), MergeRecords(ctx, "person", *survivor, *loser, "editor-1"))
+
+	contribution, err := GetContribution(ctx, *contributionID)
+	assert.NoError(suite.T(), err)
+	if assert.NotNil(suite.T(), contribution.Person) {
+		assert.Equal(suite.T(), *survivor, contribution.Person.ID)
+	}
+	shared, err := QueryContributionsByVolume(ctx, *sharedVolumeID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), shared, 1) {
+		assert.Equal(suite.T(), *survivorCredit, shared[0].ID)
+		assert.Equal(suite.T(), []string{"writer", "cartographer"}, shared[0].Roles)
+	}
+	_, err = GetContribution(ctx, *loserCredit)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+
+	// A third duplicate merged into the survivor of an earlier merge keeps one-hop redirects.
+	third, err := AddPerson(ctx, &vo.PersonVO{Name: suite.marker + " J. Smith"})
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), MergeRecords(ctx, "person", *third, *survivor, "editor-1"))
+	got, err := GetPerson(ctx, *loser)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *third, got.ID)
+	target, err := personVersioning.mergedInto(ctx, *loser)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *third, target)
+}
+
+func TestMergeTestSuite(t *testing.T) {
+	suite.Run(t, new(MergeTestSuite))
+}
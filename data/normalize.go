@@ -0,0 +1,134 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"golang.org/x/text/unicode/norm"
+)
+
+// normalizedNameField is the version-document field holding NormalizeName of the version's
+// display name (title or name). Not on the models, so it's written through insertVersion and
+// read back by raw filter.
+const normalizedNameField = "normalized_name"
+
+// foldedLetters are the letters NFKD leaves whole but readers type as plain ASCII.
+var foldedLetters = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ð", "d", "þ", "th", "ı", "i",
+)
+
+// ordinalWords maps spelled-out ordinals to their numbers, for "First Edition".
+var ordinalWords = map[string]string{
+	"first": "1", "second": "2", "third": "3", "fourth": "4", "fifth": "5",
+	"sixth": "6", "seventh": "7", "eighth": "8", "ninth": "9", "tenth": "10",
+}
+
+// editionNumber matches an edition-style number: "5e", "2nd", "3rd", "1st", "4th", "5ed".
+var editionNumber = regexp.MustCompile(`^(\d+)(e|ed|st|nd|rd|th)$`)
+
+// NormalizeName folds s to the form search and duplicate detection compare: lowercase, accents
+// stripped ("Über" -> "uber"), "&" spelled "and", apostrophes dropped ("Player's" -> "players"),
+// all other punctuation turned into word breaks, and edition numbers written one way - "5e",
+// "5E", "5th Edition", "5th ed." and "Fifth Edition" all become "5e". Words are separated by
+// single spaces.
+func NormalizeName(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining accent left over from decomposition
+		case r == '\'' || r == '’' || r == 'ʼ':
+		case r == '&':
+			b.WriteString(" and ")
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	words := strings.Fields(foldedLetters.Replace(b.String()))
+
+	out := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		followedByEdition := i+1 < len(words) && (words[i+1] == "edition" || words[i+1] == "ed")
+		if n, ok := ordinalWords[word]; ok && followedByEdition {
+			out = append(out, n+"e")
+			i++
+			continue
+		}
+		if m := editionNumber.FindStringSubmatch(word); m != nil {
+			if m[2] == "e" || m[2] == "ed" {
+				out = append(out, m[1]+"e")
+				continue
+			}
+			if followedByEdition {
+				out = append(out, m[1]+"e")
+				i++
+				continue
+			}
+		}
+		if word == "edition" && i+1 < len(words) && isDigits(words[i+1]) {
+			out = append(out, words[i+1]+"e")
+			i++
+			continue
+		}
+		out = append(out, word)
+	}
+	return strings.Join(out, " ")
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// backfillNormalizedNames sets normalized_name on every version of cfg's type that doesn't have
+// one yet, returning how many it updated.
+func (cfg entityVersioningConfig[T]) backfillNormalizedNames(c context.Context) (int, error) {
+	filter := bson.D{{Key: normalizedNameField, Value: bson.D{{Key: "$exists", Value: false}}}}
+	raws, err := Storage.Find(c, cfg.versionCollection, filter, nil, nil, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, raw := range raws {
+		var version T
+		if err := bson.Unmarshal(raw, &version); err != nil {
+			return 0, err
+		}
+		_, err := Storage.UpdateOne(c, cfg.versionCollection,
+			bson.D{{Key: "_id", Value: raw.Lookup("_id")}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: normalizedNameField, Value: NormalizeName(cfg.displayName(&version))}}}})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(raws), nil
+}
+
+// BackfillNormalizedNames sets normalized_name on every volume, publisher, studio, person and
+// license version written before the field existed, returning how many versions it updated.
+// Run it once, together with RebuildSearchIndex (whose terms are normalized the same way).
+func BackfillNormalizedNames(c context.Context) (int, error) {
+	total := 0
+	for _, backfill := range []func(context.Context) (int, error){
+		volumeVersioning.backfillNormalizedNames, publisherVersioning.backfillNormalizedNames,
+		studioVersioning.backfillNormalizedNames, personVersioning.backfillNormalizedNames,
+		licenseVersioning.backfillNormalizedNames,
+	} {
+		n, err := backfill(c)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("backfill normalized names: %w", err)
+		}
+	}
+	return total, nil
+}
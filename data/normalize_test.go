@@ -0,0 +1,67 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NormalizeTestSuite struct {
+	suite.Suite
+}
+
+func (suite *NormalizeTestSuite) SetupTest() {
+	setupTestStorage()
+	assert.NoError(suite.T(), EnsureSearchIndexes(suite.T().Context()))
+}
+
+func (suite *NormalizeTestSuite) TestNormalizeName() {
+	for input, want := range map[string]string{
+		"Über-Dungeon":                  "uber dungeon",
+		"D&D 5e":                        "d and d 5e",
+		"Pathfinder 2E":                 "pathfinder 2e",
+		"Pathfinder Second Edition":     "pathfinder 2e",
+		"Player's Handbook, 5th ed.":    "players handbook 5e",
+		"Dungeons & Dragons Edition 3":  "dungeons and dragons 3e",
+		"  Straße   der  Könige ":       "strasse der konige",
+		"Call of Cthulhu (7th Edition)": "call of cthulhu 7e",
+		"5th Street Games":              "5th street games",
+	} {
+		assert.Equal(suite.T(), want, NormalizeName(input), input)
+	}
+}
+
+func (suite *NormalizeTestSuite) TestVersionsCarryNormalizedName() {
+	ctx := suite.T().Context()
+	marker := "m" + primitive.NewObjectID().Hex()
+	id, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Über-Dungeon Press " + marker})
+	assert.NoError(suite.T(), err)
+
+	filter := bson.D{{Key: "record_id", Value: *id}, {Key: normalizedNameField, Value: "uber dungeon press " + marker}}
+	count, err := Storage.Count(ctx, publisherVersionCollection, filter)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), count)
+
+	found, err := SearchPublishers(ctx, "uber dungeon "+marker, 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+
+	// A version written before the field existed gets it from the backfill.
+	_, err = Storage.UpdateOne(ctx, publisherVersionCollection, bson.D{{Key: "record_id", Value: *id}},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: normalizedNameField, Value: ""}}}})
+	assert.NoError(suite.T(), err)
+	updated, err := BackfillNormalizedNames(ctx)
+	assert.NoError(suite.T(), err)
+	assert.GreaterOrEqual(suite.T(), updated, 1)
+	count, err = Storage.Count(ctx, publisherVersionCollection, filter)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), count)
+}
+
+func TestNormalizeTestSuite(t *testing.T) {
+	suite.Run(t, new(NormalizeTestSuite))
+}
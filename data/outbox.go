@@ -0,0 +1,210 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	outboxCollection         = "catalog_events"
+	outboxConsumerCollection = "catalog_event_consumers"
+)
+
+// EventSettleDelay is how old an event must be before ReadEvents delivers it. An event is
+// stamped when its write appends it, but only becomes visible when that write's transaction
+// commits - and Mongo aborts any transaction older than its transactionLifetimeLimitSeconds (60
+// by default). So once an event is older than that limit, every event stamped before it has
+// either committed or never will, and a reader working in (OccurredAt, ID) order can't skip one.
+// Raise it along with that server limit, and for any clock skew between catalog-api instances.
+// Set once at startup, like Transactions.
+var EventSettleDelay = time.Minute
+
+// EventType says what kind of write an Event records.
+type EventType string
+
+const (
+	// EventRecordCreated: a record and its first (live) version were created.
+	EventRecordCreated EventType = "record_created"
+	// EventVersionCreated: a new version was written - live (CurrentVersion set) or submitted
+	// for review (State tells which).
+	EventVersionCreated EventType = "version_created"
+	// EventVersionAccepted: a submitted version was accepted, as itself or as a derived version
+	// (CurrentVersion is whichever went live).
+	EventVersionAccepted EventType = "version_accepted"
+	// EventVersionRejected: a submitted version was rejected.
+	EventVersionRejected EventType = "version_rejected"
+	// EventVersionRetracted: a submitter withdrew their own submitted version.
+	EventVersionRetracted EventType = "version_retracted"
+	// EventVersionExpired: SweepStaleSubmissions expired a submitted version nobody reviewed in
+	// time.
+	EventVersionExpired EventType = "version_expired"
+	// EventCurrentVersionChanged: a record was rolled back or forward to an existing version.
+	EventCurrentVersionChanged EventType = "current_version_changed"
+	// EventRecordSoftDeleted: a record was soft-deleted.
+	EventRecordSoftDeleted EventType = "record_soft_deleted"
+	// EventRecordRestored: a soft-deleted record was restored.
+	EventRecordRestored EventType = "record_restored"
+	// EventRecordPurged: a record and its version history were hard-deleted.
+	EventRecordPurged EventType = "record_purged"
+	// EventRecordMerged: a duplicate record was merged into another (MergedInto) and
+	// soft-deleted.
+	EventRecordMerged EventType = "record_merged"
+	// EventContributionAdded: a person-to-volume credit was added. RecordID is the
+	// contribution's id.
+	EventContributionAdded EventType = "contribution_added"
+	// EventContributionDeleted: a person-to-volume credit was removed.
+	EventContributionDeleted EventType = "contribution_deleted"
+	// EventContributionUpdated: a credit's roles or place in its volume's credit order changed.
+	EventContributionUpdated EventType = "contribution_updated"
+	// EventReviewAdded: a review was written. RecordID is the review's id.
+	EventReviewAdded EventType = "review_added"
+	// EventReviewUpdated: a review's text, language or tags changed.
+	EventReviewUpdated EventType = "review_updated"
+	// EventReviewDeleted: a review was soft-deleted.
+	EventReviewDeleted EventType = "review_deleted"
+)
+
+// Event is one catalog write, appended to the outbox in the same transaction as the write
+// itself, so an event exists exactly when its write committed. Events are read in (OccurredAt,
+// ID) order - see Position and EventSettleDelay. Appending touches nothing but the event itself,
+// so writes to unrelated records never contend over the outbox; the price is that an event
+// reaches readers EventSettleDelay after its write rather than at once.
+type Event struct {
+	ID   string    `bson:"_id" json:"id"`
+	Type EventType `bson:"type" json:"type"`
+	// Entity is the record's type, e.g. "volume" or "contribution".
+	Entity   string `bson:"entity" json:"entity"`
+	RecordID string `bson:"record_id" json:"recordId"`
+	// Version is the version the write was about, zero for record-level events.
+	Version int                 `bson:"version,omitempty" json:"version,omitempty"`
+	State   models.VersionState `bson:"state,omitempty" json:"state,omitempty"`
+	// CurrentVersion is the record's current version after the write, set only when the write
+	// moved it - the signal for "this record changed what readers see".
+	CurrentVersion *int `bson:"current_version,omitempty" json:"currentVersion,omitempty"`
+	// MergedInto is the surviving record's id, set only on EventRecordMerged.
+	MergedInto string    `bson:"merged_into,omitempty" json:"mergedInto,omitempty"`
+	Actor      string    `bson:"actor,omitempty" json:"actor,omitempty"`
+	OccurredAt time.Time `bson:"occurred_at" json:"occurredAt"`
+}
+
+// EventPosition is a place in the outbox: events are ordered by OccurredAt, then by ID among
+// events stamped the same millisecond. The zero EventPosition is before every event.
+type EventPosition struct {
+	OccurredAt time.Time `json:"occurredAt"`
+	ID         string    `json:"id"`
+}
+
+// Position is the event's place in the outbox, for ReadEvents and AckEvents.
+func (e *Event) Position() EventPosition {
+	return EventPosition{OccurredAt: e.OccurredAt, ID: e.ID}
+}
+
+// afterPosition is the filter clause matching events after p, in (occurred_at, _id) order.
+func afterPosition(p EventPosition) bson.E {
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "occurred_at", Value: bson.D{{Key: "$gt", Value: p.OccurredAt}}}},
+		bson.D{{Key: "occurred_at", Value: p.OccurredAt}, {Key: "_id", Value: bson.D{{Key: "$gt", Value: p.ID}}}},
+	}}
+}
+
+// outboxConsumer is one consumer's acknowledged position in the outbox.
+type outboxConsumer struct {
+	ID         string    `bson:"_id"`
+	OccurredAt time.Time `bson:"occurred_at"`
+	EventID    string    `bson:"event_id"`
+}
+
+// EnsureOutboxIndexes creates the (occurred_at, _id) index ReadEvents reads by. Safe to call on
+// every startup.
+func EnsureOutboxIndexes(c context.Context) error {
+	if err := Storage.EnsureIndex(c, outboxCollection, bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}, false); err != nil {
+		return fmt.Errorf("outbox: create occurred_at+_id index: %w", err)
+	}
+	return nil
+}
+
+// appendEvent allocates event's id and timestamp and writes it to the outbox. Call it with the
+// write's own transaction context, after the write itself.
+func appendEvent(c context.Context, event Event) error {
+	event.ID = primitive.NewObjectID().Hex()
+	// Millisecond precision is all Mongo stores, so positions compare the same before and after a
+	// round trip.
+	event.OccurredAt = time.Now().Truncate(time.Millisecond)
+	if err := storeInsert(c, outboxCollection, event); err != nil {
+		return fmt.Errorf("outbox: %w", err)
+	}
+	return nil
+}
+
+// ReadEvents returns up to limit events (0 for no limit) after the given position that are at
+// least EventSettleDelay old, oldest first - the stateless form of PollEvents, for a consumer
+// that tracks its own position.
+func ReadEvents(c context.Context, after EventPosition, limit int) ([]*Event, error) {
+	filter := bson.D{
+		afterPosition(after),
+		{Key: "occurred_at", Value: bson.D{{Key: "$lte", Value: time.Now().Add(-EventSettleDelay)}}},
+	}
+	sortOrder := bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}
+	return storeQuery[Event](c, outboxCollection, filter, sortOrder, nil, 0, limit)
+}
+
+// PollEvents returns up to limit events (0 for no limit) consumer hasn't acknowledged yet, oldest
+// first. Polling doesn't move the cursor - the same events come back until AckEvents confirms
+// them, so a consumer that crashes mid-batch sees them again (delivery is at-least-once).
+func PollEvents(c context.Context, consumer string, limit int) ([]*Event, error) {
+	cursor, err := EventCursor(c, consumer)
+	if err != nil {
+		return nil, err
+	}
+	return ReadEvents(c, cursor, limit)
+}
+
+// EventCursor returns the position consumer last acknowledged, the zero EventPosition for a
+// consumer that never has.
+func EventCursor(c context.Context, consumer string) (EventPosition, error) {
+	results, err := storeQuery[outboxConsumer](c, outboxConsumerCollection, bson.D{{Key: "_id", Value: consumer}}, nil, nil, 0, 1)
+	if err != nil {
+		return EventPosition{}, err
+	}
+	if len(results) == 0 {
+		return EventPosition{}, nil
+	}
+	return EventPosition{OccurredAt: results[0].OccurredAt, ID: results[0].EventID}, nil
+}
+
+// AckEvents records that consumer has handled every event up to and including position. The
+// cursor only moves forward, so a late or duplicate ack is harmless.
+func AckEvents(c context.Context, consumer string, position EventPosition) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		filter := bson.D{{Key: "_id", Value: consumer}, {Key: "$or", Value: bson.A{
+			bson.D{{Key: "occurred_at", Value: bson.D{{Key: "$lt", Value: position.OccurredAt}}}},
+			bson.D{{Key: "occurred_at", Value: position.OccurredAt}, {Key: "event_id", Value: bson.D{{Key: "$lt", Value: position.ID}}}},
+		}}}
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "occurred_at", Value: position.OccurredAt}, {Key: "event_id", Value: position.ID}}}}
+		if _, err := Storage.UpdateOne(c, outboxConsumerCollection, filter, update); err != nil {
+			return err
+		}
+
+		exists, err := Storage.Count(c, outboxConsumerCollection, bson.D{{Key: "_id", Value: consumer}})
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return nil
+		}
+		err = Storage.Insert(c, outboxConsumerCollection, outboxConsumer{ID: consumer, OccurredAt: position.OccurredAt, EventID: position.ID})
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("outbox: consumer %s could not be created", consumer)
+}
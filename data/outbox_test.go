@@ -0,0 +1,135 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutboxTestSuite checks that lifecycle writes append their events and that the poll/ack
+// cursor behaves. Events from other suites may share the outbox on a real database, so every
+// assertion is scoped to events after the suite's own starting cursor.
+type OutboxTestSuite struct {
+	suite.Suite
+	start EventPosition
+}
+
+func (suite *OutboxTestSuite) SetupTest() {
+	setupTestStorage()
+	previous := EventSettleDelay
+	EventSettleDelay = 0
+	suite.T().Cleanup(func() { EventSettleDelay = previous })
+	assert.NoError(suite.T(), EnsureOutboxIndexes(suite.T().Context()))
+	assert.NoError(suite.T(), EnsurePublisherVersioningIndexes(suite.T().Context()))
+
+	events, err := ReadEvents(suite.T().Context(), EventPosition{}, 0)
+	assert.NoError(suite.T(), err)
+	suite.start = EventPosition{}
+	if len(events) > 0 {
+		suite.start = events[len(events)-1].Position()
+	}
+}
+
+// eventsFor returns the events for recordID since the test started.
+func (suite *OutboxTestSuite) eventsFor(recordID string) []*Event {
+	events, err := ReadEvents(suite.T().Context(), suite.start, 0)
+	assert.NoError(suite.T(), err)
+	var out []*Event
+	for _, event := range events {
+		if event.RecordID == recordID {
+			out = append(out, event)
+		}
+	}
+	return out
+}
+
+func (suite *OutboxTestSuite) TestLifecycleWritesAppendEvents() {
+	ctx := suite.T().Context()
+	id, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Evented Publisher"})
+	assert.NoError(suite.T(), err)
+	submitted, err := UpdatePublisher(ctx, *id, &vo.PublisherVO{Name: "Proposed"}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), RejectPublisherVersion(ctx, *id, submitted.Version, "editor-1", nil))
+	assert.NoError(suite.T(), SoftDeletePublisher(ctx, *id, "admin-1"))
+	assert.NoError(suite.T(), RestorePublisher(ctx, *id))
+
+	// A failed write appends nothing.
+	assert.Error(suite.T(), RejectPublisherVersion(ctx, *id, submitted.Version, "editor-1", nil))
+
+	events := suite.eventsFor(*id)
+	types := make([]EventType, 0, len(events))
+	for _, event := range events {
+		assert.Equal(suite.T(), "publisher", event.Entity)
+		types = append(types, event.Type)
+	}
+	assert.Equal(suite.T(), []EventType{
+		EventRecordCreated, EventVersionCreated, EventVersionRejected, EventRecordSoftDeleted, EventRecordRestored,
+	}, types)
+	if assert.Len(suite.T(), events, 5) {
+		assert.Equal(suite.T(), 1, *events[0].CurrentVersion)
+		assert.Nil(suite.T(), events[1].CurrentVersion)
+		assert.Equal(suite.T(), models.VersionStateSubmitted, events[1].State)
+		assert.Equal(suite.T(), "admin-1", events[3].Actor)
+		for i := 1; i < len(events); i++ {
+			assert.False(suite.T(), events[i].OccurredAt.Before(events[i-1].OccurredAt))
+		}
+	}
+}
+
+func (suite *OutboxTestSuite) TestPollAndAckEvents() {
+	ctx := suite.T().Context()
+	consumer := "consumer-" + primitive.NewObjectID().Hex()
+	assert.NoError(suite.T(), AckEvents(ctx, consumer, suite.start))
+
+	id, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Polled Publisher"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePublisher(ctx, *id, &vo.PublisherVO{Name: "Renamed"}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	polled, err := PollEvents(ctx, consumer, 1)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), polled, 1) {
+		assert.Equal(suite.T(), EventRecordCreated, polled[0].Type)
+	}
+	// Unacknowledged events are redelivered.
+	again, err := PollEvents(ctx, consumer, 0)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), again, 2) {
+		assert.Equal(suite.T(), polled[0].ID, again[0].ID)
+		assert.Equal(suite.T(), EventVersionCreated, again[1].Type)
+		assert.Equal(suite.T(), 2, *again[1].CurrentVersion)
+
+		assert.NoError(suite.T(), AckEvents(ctx, consumer, again[1].Position()))
+		// A stale ack doesn't move the cursor back.
+		assert.NoError(suite.T(), AckEvents(ctx, consumer, again[0].Position()))
+		cursor, err := EventCursor(ctx, consumer)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), again[1].ID, cursor.ID)
+		assert.True(suite.T(), again[1].OccurredAt.Equal(cursor.OccurredAt))
+	}
+
+	remaining, err := PollEvents(ctx, consumer, 0)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), remaining)
+}
+
+func (suite *OutboxTestSuite) TestReadEventsWaitsOutSettleDelay() {
+	ctx := suite.T().Context()
+	id, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Unsettled Publisher"})
+	assert.NoError(suite.T(), err)
+
+	EventSettleDelay = time.Hour
+	assert.Empty(suite.T(), suite.eventsFor(*id))
+
+	EventSettleDelay = 0
+	assert.Len(suite.T(), suite.eventsFor(*id), 1)
+}
+
+func TestOutboxTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxTestSuite))
+}
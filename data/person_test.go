@@ -1,16 +1,12 @@
 package data
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
-	"github.com/sweetrpg/common.go/logging"
-	"github.com/sweetrpg/mongodb.go/constants"
-	"github.com/sweetrpg/mongodb.go/database"
 )
 
 type PersonDataTestSuite struct {
@@ -19,9 +15,7 @@ type PersonDataTestSuite struct {
 }
 
 func (suite *PersonDataTestSuite) SetupTest() {
-	_ = os.Setenv(constants.DB_URI, os.Getenv("TEST_DB_URI"))
-	logging.Init()
-	database.SetupDatabase()
+	setupTestStorage()
 	assert.NoError(suite.T(), EnsurePersonVersioningIndexes(suite.T().Context()))
 
 	id, err := AddPerson(suite.T().Context(), &vo.PersonVO{Name: "Test Person"})
@@ -33,7 +27,7 @@ func (suite *PersonDataTestSuite) SetupTest() {
 func (suite *PersonDataTestSuite) TestUpdatePersonLive() {
 	updated, err := UpdatePerson(suite.T().Context(), suite.seedPersonID, &vo.PersonVO{
 		Name: "Updated Person",
-	}, models.VersionStateLive)
+	}, models.VersionStateLive, nil)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updated)
 	assert.Equal(suite.T(), "Updated Person", updated.Name)
@@ -47,19 +41,19 @@ func (suite *PersonDataTestSuite) TestUpdatePersonLive() {
 func (suite *PersonDataTestSuite) TestUpdatePersonNotFound() {
 	updated, err := UpdatePerson(suite.T().Context(), "does-not-exist", &vo.PersonVO{
 		Name: "Doesn't Matter",
-	}, models.VersionStateLive)
-	assert.NoError(suite.T(), err)
+	}, models.VersionStateLive, nil)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
 	assert.Nil(suite.T(), updated)
 }
 
 func (suite *PersonDataTestSuite) TestAcceptPersonVersionFullAccept() {
 	submitted, err := UpdatePerson(suite.T().Context(), suite.seedPersonID, &vo.PersonVO{
 		Name: "Proposed Person",
-	}, models.VersionStateSubmitted)
+	}, models.VersionStateSubmitted, nil)
 	assert.NoError(suite.T(), err)
 
 	accepted, conflicts, err := AcceptPersonVersion(
-		suite.T().Context(), suite.seedPersonID, submitted.Version, nil, "editor-1", nil)
+		suite.T().Context(), suite.seedPersonID, submitted.Version, nil, nil, "editor-1", nil, nil)
 	assert.NoError(suite.T(), err)
 	assert.Empty(suite.T(), conflicts)
 	assert.Equal(suite.T(), string(models.VersionStateLive), string(accepted.State))
@@ -2,7 +2,6 @@ package data
 
 import (
 	"context"
-	"strings"
 	"time"
 
 	apiutil "github.com/sweetrpg/api-core.go/util"
@@ -11,7 +10,6 @@ import (
 	modelcore "github.com/sweetrpg/model-core.go/models"
 	modelcoreutil "github.com/sweetrpg/model-core.go/util"
 	modelcorevo "github.com/sweetrpg/model-core.go/vo"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -20,22 +18,39 @@ const (
 	personVersionCollection = "persons_versions"
 )
 
+// PersonApprovalPolicy is VolumeApprovalPolicy for persons.
+var PersonApprovalPolicy ApprovalPolicy
+
+// PersonSubmissionCap is VolumeSubmissionCap for persons.
+var PersonSubmissionCap SubmissionCap
+
 var personVersioning = entityVersioningConfig[models.PersonVersion]{
 	metaCollection:    personMetaCollection,
 	versionCollection: personVersionCollection,
 	typeName:          "person",
-	lifecycle:         func(v *models.PersonVersion) *models.VersionLifecycle { return &v.VersionLifecycle },
+	lifecycle:         func(v *models.PersonVersion) models.VersionLifecycle { return v.VersionLifecycle },
+	setLifecycle:      func(v *models.PersonVersion, lc models.VersionLifecycle) { v.VersionLifecycle = lc },
 	setID:             func(v *models.PersonVersion, id string) { v.ID = id },
 	setRecordID:       func(v *models.PersonVersion, id string) { v.RecordID = id },
 	setVersion:        func(v *models.PersonVersion, n int) { v.Version = n },
+	version:           func(v *models.PersonVersion) int { return v.Version },
 	recordID:          func(v *models.PersonVersion) string { return v.RecordID },
 	displayName:       func(v *models.PersonVersion) string { return v.Name },
 	fields: map[string]entityFieldAccessor[models.PersonVersion]{
 		"name":       {get: func(v *models.PersonVersion) any { return v.Name }, set: func(v *models.PersonVersion, val any) { v.Name = val.(string) }},
 		"notes":      {get: func(v *models.PersonVersion) any { return v.Notes }, set: func(v *models.PersonVersion, val any) { v.Notes = val.(string) }},
-		"properties": {get: func(v *models.PersonVersion) any { return v.Properties }, set: func(v *models.PersonVersion, val any) { v.Properties = val.([]modelcore.Property) }},
-		"tags":       {get: func(v *models.PersonVersion) any { return v.Tags }, set: func(v *models.PersonVersion, val any) { v.Tags = val.([]modelcore.Tag) }},
+		"properties": {get: func(v *models.PersonVersion) any { return v.Properties }, set: func(v *models.PersonVersion, val any) { v.Properties = val.([]modelcore.Property) }, setMerge: true},
+		"tags":       {get: func(v *models.PersonVersion) any { return v.Tags }, set: func(v *models.PersonVersion, val any) { v.Tags = val.([]modelcore.Tag) }, setMerge: true},
+	},
+	references: []entityReference{
+		{dependentType: "contribution", collection: "contributions", field: "person_id", fold: foldContributions},
+	},
+	search: func(v *models.PersonVersion) (string, []string) {
+		return v.Name, tagSearchText(v.Tags)
 	},
+	onPurge:        purgePersonCredits,
+	approvalPolicy: &PersonApprovalPolicy,
+	submissionCap:  &PersonSubmissionCap,
 }
 
 // EnsurePersonVersioningIndexes creates the indexes person version queries rely on. Safe to call
@@ -50,6 +65,12 @@ func SoftDeletePerson(c context.Context, id string, deletedBy string) error {
 	return personVersioning.softDelete(c, id, deletedBy)
 }
 
+// DeletePerson hard-deletes a person and its version history, handling contributions crediting it
+// per policy - see DeleteVolume.
+func DeletePerson(c context.Context, id string, policy CascadePolicy) error {
+	return personVersioning.purge(c, id, policy)
+}
+
 // RestorePerson clears a soft-deleted person's deletion, returning it to every normal read path.
 func RestorePerson(c context.Context, id string) error {
 	return personVersioning.restore(c, id)
@@ -97,30 +118,20 @@ func AddPerson(c context.Context, person *vo.PersonVO) (*string, error) {
 	return personVersioning.addEntity(c, &version, person.CreatedBy)
 }
 
-// GetPerson returns the flattened view of a person.
+// GetPerson returns the flattened view of a person, following merge redirects like GetPublisher.
 func GetPerson(c context.Context, id string) (*vo.PersonVO, error) {
-	meta, err := personVersioning.getMeta(c, id)
-	if err != nil {
-		return nil, err
-	}
-	if meta == nil {
-		return nil, nil
-	}
-	version, err := personVersioning.getVersion(c, id, meta.CurrentVersion)
+	meta, version, err := personVersioning.getCurrentFollowingMerges(c, id)
 	if err != nil {
 		return nil, err
 	}
-	if version == nil {
-		return nil, nil
-	}
 	return flattenPerson(meta, version), nil
 }
 
 // UpdatePerson creates a new version of the person rather than mutating in place.
-func UpdatePerson(c context.Context, id string, person *vo.PersonVO, state models.VersionState) (*vo.PersonVersionVO, error) {
+func UpdatePerson(c context.Context, id string, person *vo.PersonVO, state models.VersionState, expectedCurrentVersion *int) (*vo.PersonVersionVO, error) {
 	version := personVersionFields(person)
-	result, err := personVersioning.createVersion(c, id, &version, state, person.UpdatedBy)
-	if err != nil || result == nil {
+	result, err := personVersioning.createVersion(c, id, &version, state, person.UpdatedBy, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return personVersionToVO(result), nil
@@ -141,22 +152,41 @@ func ListPersonVersions(c context.Context, id string) ([]*vo.PersonVersionVO, er
 
 // GetPersonVersion returns one version's full snapshot.
 func GetPersonVersion(c context.Context, id string, version int) (*vo.PersonVersionVO, error) {
-	result, err := personVersioning.getVersion(c, id, version)
-	if err != nil || result == nil {
+	result, err := personVersioning.requireVersion(c, id, version)
+	if err != nil {
 		return nil, err
 	}
 	return personVersionToVO(result), nil
 }
 
+// DiffPersonVersions is DiffVolumeVersions for a person.
+func DiffPersonVersions(c context.Context, id string, from, to int) (*VersionDiff, error) {
+	return personVersioning.diffVersions(c, id, from, to)
+}
+
+// DiffPersonSubmission is DiffVolumeSubmission for a person, previewing AcceptPersonVersion.
+func DiffPersonSubmission(c context.Context, id string, version int) (*SubmissionDiff, error) {
+	return personVersioning.diffSubmission(c, id, version)
+}
+
 // AcceptPersonVersion reviews a submitted version - see AcceptVolumeVersion's doc comment.
-func AcceptPersonVersion(c context.Context, id string, version int, selectedFields []string, reviewedBy string, reviewNote *string) (*vo.PersonVersionVO, []string, error) {
-	result, conflicts, err := personVersioning.acceptVersion(c, id, version, selectedFields, reviewedBy, reviewNote)
-	if err != nil || result == nil {
+func AcceptPersonVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*vo.PersonVersionVO, []string, error) {
+	result, conflicts, err := personVersioning.acceptVersion(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, expectedCurrentVersion)
+	if err != nil {
 		return nil, conflicts, err
 	}
 	return personVersionToVO(result), conflicts, nil
 }
 
+// PreviewAcceptPersonVersion is PreviewAcceptVolumeVersion for a person.
+func PreviewAcceptPersonVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*AcceptPreview[vo.PersonVersionVO], error) {
+	plan, err := personVersioning.previewAccept(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, nil, expectedCurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	return newAcceptPreview(plan, personVersionToVO), nil
+}
+
 // RejectPersonVersion marks a submitted version rejected.
 func RejectPersonVersion(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
 	return personVersioning.rejectVersion(c, id, version, reviewedBy, reviewNote)
@@ -165,16 +195,16 @@ func RejectPersonVersion(c context.Context, id string, version int, reviewedBy s
 // RetractPersonVersion lets the original submitter withdraw their own pending submission.
 func RetractPersonVersion(c context.Context, id string, version int, submitterID string) (*vo.PersonVersionVO, error) {
 	result, err := personVersioning.retractVersion(c, id, version, submitterID)
-	if err != nil || result == nil {
+	if err != nil {
 		return nil, err
 	}
 	return personVersionToVO(result), nil
 }
 
 // SetCurrentPersonVersion rolls a person back (or forward) to an arbitrary existing version.
-func SetCurrentPersonVersion(c context.Context, id string, version int) (*vo.PersonVersionVO, error) {
-	result, err := personVersioning.setCurrentVersion(c, id, version)
-	if err != nil || result == nil {
+func SetCurrentPersonVersion(c context.Context, id string, version int, expectedCurrentVersion *int) (*vo.PersonVersionVO, error) {
+	result, err := personVersioning.setCurrentVersion(c, id, version, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return personVersionToVO(result), nil
@@ -189,7 +219,7 @@ func CountSubmittedPersonVersionsBySubmitter(c context.Context, submittedBy stri
 func QueryPersons(c context.Context, params apiutil.QueryParams) ([]*vo.PersonVO, error) {
 	filter, sort, projection := apiutil.ConvertQueryParams(params)
 	filter = append(filter, bson.E{Key: "deleted_at", Value: nil})
-	metas, err := database.Query[models.EntityMeta](personMetaCollection, filter, sort, projection, params.Start, params.Limit)
+	metas, err := storeQuery[models.EntityMeta](c, personMetaCollection, filter, sort, projection, params.Start, params.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -207,38 +237,18 @@ func QueryPersons(c context.Context, params apiutil.QueryParams) ([]*vo.PersonVO
 	return vos, nil
 }
 
-// searchScanLimit bounds how many live persons SearchPersons scans in memory - name isn't a
-// field on personMetaCollection (it only lives on the version document QueryPersons resolves
-// per-meta), so there's no index-backed way to push a substring match down to Mongo without a
-// denormalization this doesn't attempt yet. High enough to cover the whole collection at its
-// current size (low hundreds) with room to grow; revisit if persons ever reaches this scale.
-const searchScanLimit = 5000
-
-// SearchPersons finds live persons whose name contains query (case-insensitive), scanning the
-// full collection rather than just whatever page a plain QueryPersons call would return -
-// backs catalog-api's /persons/search route, used by autocomplete/picker inputs instead of the
-// page-capped list-and-filter-client-side approach every other picker used before this existed.
-func SearchPersons(c context.Context, query string) ([]*vo.PersonVO, error) {
-	all, err := QueryPersons(c, apiutil.QueryParams{Limit: searchScanLimit})
-	if err != nil {
-		return nil, err
-	}
-	needle := strings.ToLower(query)
-	matches := make([]*vo.PersonVO, 0, len(all))
-	for _, p := range all {
-		if strings.Contains(strings.ToLower(p.Name), needle) {
-			matches = append(matches, p)
-		}
-	}
-	return matches, nil
+// SearchPersons is SearchVolumes for persons, matching name and tags - backs catalog-api's
+// /persons/search route, used by autocomplete/picker inputs.
+func SearchPersons(c context.Context, query string, start, limit int) ([]*vo.PersonVO, error) {
+	return searchEntity(c, "person", query, start, limit, resolvePersons)
 }
 
 // CreateSubmittedPersonVersion creates a submitted version stamped with a caller-supplied
 // submittedBy/submittedAt - see CreateSubmittedPublisherVersion's doc comment.
 func CreateSubmittedPersonVersion(c context.Context, id string, person *vo.PersonVO, submittedBy string, submittedAt time.Time) (*vo.PersonVersionVO, error) {
 	version := personVersionFields(person)
-	result, err := personVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt)
-	if err != nil || result == nil {
+	result, err := personVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt, nil)
+	if err != nil {
 		return nil, err
 	}
 	return personVersionToVO(result), nil
@@ -1,7 +1,8 @@
 package data
 
 import (
-	"os"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,9 +10,6 @@ import (
 	apiutil "github.com/sweetrpg/api-core.go/util"
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
-	"github.com/sweetrpg/common.go/logging"
-	"github.com/sweetrpg/mongodb.go/constants"
-	"github.com/sweetrpg/mongodb.go/database"
 )
 
 type PublisherDataTestSuite struct {
@@ -20,9 +18,7 @@ type PublisherDataTestSuite struct {
 }
 
 func (suite *PublisherDataTestSuite) SetupTest() {
-	_ = os.Setenv(constants.DB_URI, os.Getenv("TEST_DB_URI"))
-	logging.Init()
-	database.SetupDatabase()
+	setupTestStorage()
 	assert.NoError(suite.T(), EnsurePublisherVersioningIndexes(suite.T().Context()))
 
 	id, err := AddPublisher(suite.T().Context(), &vo.PublisherVO{
@@ -36,7 +32,7 @@ func (suite *PublisherDataTestSuite) SetupTest() {
 func (suite *PublisherDataTestSuite) TestUpdatePublisherLive() {
 	updated, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
 		Name: "Updated Publisher", Address: "456 Updated Ave",
-	}, models.VersionStateLive)
+	}, models.VersionStateLive, nil)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updated)
 	assert.Equal(suite.T(), "Updated Publisher", updated.Name)
@@ -51,7 +47,7 @@ func (suite *PublisherDataTestSuite) TestUpdatePublisherLive() {
 func (suite *PublisherDataTestSuite) TestUpdatePublisherSubmittedLeavesCurrentVersionUnchanged() {
 	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
 		Name: "Proposed Publisher",
-	}, models.VersionStateSubmitted)
+	}, models.VersionStateSubmitted, nil)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), submitted)
 	assert.Equal(suite.T(), string(models.VersionStateSubmitted), string(submitted.State))
@@ -64,19 +60,24 @@ func (suite *PublisherDataTestSuite) TestUpdatePublisherSubmittedLeavesCurrentVe
 func (suite *PublisherDataTestSuite) TestUpdatePublisherNotFound() {
 	updated, err := UpdatePublisher(suite.T().Context(), "does-not-exist", &vo.PublisherVO{
 		Name: "Doesn't Matter",
-	}, models.VersionStateLive)
-	assert.NoError(suite.T(), err)
+	}, models.VersionStateLive, nil)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
 	assert.Nil(suite.T(), updated)
 }
 
+func (suite *PublisherDataTestSuite) TestNextVersionNumberWithoutMetaIsNotFound() {
+	_, err := publisherVersioning.nextVersionNumber(suite.T().Context(), "does-not-exist")
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+}
+
 func (suite *PublisherDataTestSuite) TestAcceptPublisherVersionFullAccept() {
 	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
 		Name: "Proposed Publisher",
-	}, models.VersionStateSubmitted)
+	}, models.VersionStateSubmitted, nil)
 	assert.NoError(suite.T(), err)
 
 	accepted, conflicts, err := AcceptPublisherVersion(
-		suite.T().Context(), suite.seedPublisherID, submitted.Version, nil, "editor-1", nil)
+		suite.T().Context(), suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, nil)
 	assert.NoError(suite.T(), err)
 	assert.Empty(suite.T(), conflicts)
 	assert.Equal(suite.T(), string(models.VersionStateLive), string(accepted.State))
@@ -97,7 +98,7 @@ func (suite *PublisherDataTestSuite) TestAcceptPublisherVersionFullAccept() {
 func (suite *PublisherDataTestSuite) TestRejectPublisherVersion() {
 	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
 		Name: "Proposed Publisher",
-	}, models.VersionStateSubmitted)
+	}, models.VersionStateSubmitted, nil)
 	assert.NoError(suite.T(), err)
 
 	note := "not good"
@@ -114,11 +115,98 @@ func (suite *PublisherDataTestSuite) TestRejectPublisherVersion() {
 	assert.Equal(suite.T(), &note, refetched.ReviewNote)
 }
 
+func (suite *PublisherDataTestSuite) TestDiffPublisherSubmissionMatchesAccept() {
+	ctx := suite.T().Context()
+	submitted, err := UpdatePublisher(ctx, suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Proposed Publisher", Address: "123 Test St", Notes: "proposed notes",
+	}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	live, err := UpdatePublisher(ctx, suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Live Edit", Address: "123 Test St",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	diff, err := DiffPublisherSubmission(ctx, suite.seedPublisherID, submitted.Version)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, diff.BaseVersion)
+	assert.Equal(suite.T(), live.Version, diff.CurrentVersion)
+	assert.Equal(suite.T(), []FieldChange{
+		{Field: "name", Old: "Test Publisher", New: "Proposed Publisher"},
+		{Field: "notes", Old: "", New: "proposed notes"},
+	}, diff.Proposed.Changes)
+	assert.Equal(suite.T(), []FieldChange{
+		{Field: "name", Old: "Live Edit", New: "Proposed Publisher"},
+		{Field: "notes", Old: "", New: "proposed notes"},
+	}, diff.AgainstCurrent.Changes)
+	assert.Equal(suite.T(), []string{"name"}, diff.Conflicts)
+
+	_, conflicts, err := AcceptPublisherVersion(ctx, suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), diff.Conflicts, conflicts)
+
+	_, err = DiffPublisherSubmission(ctx, suite.seedPublisherID, submitted.Version)
+	assert.ErrorIs(suite.T(), err, ErrInvalidState)
+}
+
+func (suite *PublisherDataTestSuite) TestPreviewAcceptPublisherVersionWritesNothing() {
+	ctx := suite.T().Context()
+	submitted, err := UpdatePublisher(ctx, suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Proposed Publisher", Address: "123 Test St", Notes: "proposed notes",
+	}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	live, err := UpdatePublisher(ctx, suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Live Edit", Address: "123 Test St",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+	before, err := GetPublisher(ctx, suite.seedPublisherID)
+	assert.NoError(suite.T(), err)
+
+	preview, err := PreviewAcceptPublisherVersion(ctx, suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"notes"}, preview.Accepted)
+	assert.Equal(suite.T(), []string{"name"}, preview.Conflicts)
+	assert.Equal(suite.T(), "Live Edit", preview.Version.Name)
+	assert.Equal(suite.T(), "proposed notes", preview.Version.Notes)
+	assert.Equal(suite.T(), live.Version, preview.CurrentVersion)
+
+	after, err := GetPublisher(ctx, suite.seedPublisherID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), before.Name, after.Name)
+	assert.Equal(suite.T(), before.Notes, after.Notes)
+	stillSubmitted, err := GetPublisherVersion(ctx, suite.seedPublisherID, submitted.Version)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), string(models.VersionStateSubmitted), string(stillSubmitted.State))
+
+	accepted, conflicts, err := AcceptPublisherVersion(ctx, suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, &preview.CurrentVersion)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), preview.Conflicts, conflicts)
+	assert.Equal(suite.T(), preview.Version.Name, accepted.Name)
+	assert.Equal(suite.T(), preview.Version.Notes, accepted.Notes)
+
+	_, err = PreviewAcceptPublisherVersion(ctx, suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrInvalidState)
+}
+
+func (suite *PublisherDataTestSuite) TestDiffPublisherVersions() {
+	ctx := suite.T().Context()
+	live, err := UpdatePublisher(ctx, suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Renamed Publisher", Address: "123 Test St",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	diff, err := DiffPublisherVersions(ctx, suite.seedPublisherID, 1, live.Version)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []FieldChange{{Field: "name", Old: "Test Publisher", New: "Renamed Publisher"}}, diff.Changes)
+
+	_, err = DiffPublisherVersions(ctx, suite.seedPublisherID, 1, 99)
+	assert.ErrorIs(suite.T(), err, ErrVersionNotFound)
+}
+
 func (suite *PublisherDataTestSuite) TestRetractPublisherVersion() {
 	proposed := &vo.PublisherVO{Name: "Proposed Publisher"}
 	proposed.UpdatedBy = "submitter-1"
 	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, proposed,
-		models.VersionStateSubmitted)
+		models.VersionStateSubmitted, nil)
 	assert.NoError(suite.T(), err)
 
 	retracted, err := RetractPublisherVersion(suite.T().Context(), suite.seedPublisherID, submitted.Version, "submitter-1")
@@ -133,10 +221,10 @@ func (suite *PublisherDataTestSuite) TestRetractPublisherVersion() {
 func (suite *PublisherDataTestSuite) TestSetCurrentPublisherVersion() {
 	_, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
 		Name: "Updated Publisher",
-	}, models.VersionStateLive)
+	}, models.VersionStateLive, nil)
 	assert.NoError(suite.T(), err)
 
-	rolledBack, err := SetCurrentPublisherVersion(suite.T().Context(), suite.seedPublisherID, 1)
+	rolledBack, err := SetCurrentPublisherVersion(suite.T().Context(), suite.seedPublisherID, 1, nil)
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), "Test Publisher", rolledBack.Name)
 
@@ -152,7 +240,7 @@ func (suite *PublisherDataTestSuite) TestSetCurrentPublisherVersion() {
 func (suite *PublisherDataTestSuite) TestWebsiteRoundTripsAsPlainString() {
 	updated, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
 		Name: "Test Publisher", Website: "https://example.com/kobold",
-	}, models.VersionStateLive)
+	}, models.VersionStateLive, nil)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updated)
 	assert.Equal(suite.T(), "https://example.com/kobold", updated.Website)
@@ -163,9 +251,161 @@ func (suite *PublisherDataTestSuite) TestWebsiteRoundTripsAsPlainString() {
 	assert.Equal(suite.T(), "https://example.com/kobold", fetched.Website)
 }
 
+func (suite *PublisherDataTestSuite) TestGetPublisherNotFound() {
+	fetched, err := GetPublisher(suite.T().Context(), "does-not-exist")
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+	assert.Nil(suite.T(), fetched)
+
+	version, err := GetPublisherVersion(suite.T().Context(), suite.seedPublisherID, 99)
+	assert.ErrorIs(suite.T(), err, ErrVersionNotFound)
+	assert.Nil(suite.T(), version)
+}
+
+func (suite *PublisherDataTestSuite) TestReviewingNonSubmittedPublisherVersionIsInvalidState() {
+	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Proposed Publisher",
+	}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), RejectPublisherVersion(suite.T().Context(), suite.seedPublisherID, submitted.Version, "editor-1", nil))
+
+	_, _, err = AcceptPublisherVersion(
+		suite.T().Context(), suite.seedPublisherID, submitted.Version, nil, nil, "editor-2", nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrInvalidState)
+	var invalid *InvalidStateError
+	if assert.ErrorAs(suite.T(), err, &invalid) {
+		assert.Equal(suite.T(), models.VersionStateRejected, invalid.State)
+	}
+}
+
+func (suite *PublisherDataTestSuite) TestRetractPublisherVersionByOtherUserIsNotSubmitter() {
+	proposed := &vo.PublisherVO{Name: "Proposed Publisher"}
+	proposed.UpdatedBy = "submitter-1"
+	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, proposed,
+		models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+
+	retracted, err := RetractPublisherVersion(suite.T().Context(), suite.seedPublisherID, submitted.Version, "someone-else")
+	assert.ErrorIs(suite.T(), err, ErrNotSubmitter)
+	assert.Nil(suite.T(), retracted)
+}
+
+func (suite *PublisherDataTestSuite) TestUpdatePublisherStaleExpectedVersionConflicts() {
+	_, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Concurrent Edit",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	stale := 1
+	updated, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Stale Edit",
+	}, models.VersionStateLive, &stale)
+	assert.Nil(suite.T(), updated)
+	var conflict *ConflictError
+	if assert.ErrorAs(suite.T(), err, &conflict) {
+		assert.Equal(suite.T(), 1, conflict.ExpectedVersion)
+		assert.Equal(suite.T(), 2, conflict.CurrentVersion)
+	}
+
+	fetched, err := GetPublisher(suite.T().Context(), suite.seedPublisherID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Concurrent Edit", fetched.Name)
+
+	current := 2
+	updated, err = UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Fresh Edit",
+	}, models.VersionStateLive, &current)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, updated.Version)
+}
+
+func (suite *PublisherDataTestSuite) TestAcceptAndSetCurrentPublisherVersionStaleExpectedVersionConflict() {
+	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Proposed Publisher",
+	}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Moved On",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	stale := 1
+	_, _, err = AcceptPublisherVersion(
+		suite.T().Context(), suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, &stale)
+	assert.ErrorIs(suite.T(), err, ErrConflict)
+
+	refetched, err := GetPublisherVersion(suite.T().Context(), suite.seedPublisherID, submitted.Version)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), string(models.VersionStateSubmitted), string(refetched.State))
+
+	_, err = SetCurrentPublisherVersion(suite.T().Context(), suite.seedPublisherID, 1, &stale)
+	assert.ErrorIs(suite.T(), err, ErrConflict)
+}
+
+// TestConcurrentUpdatePublisherAllocatesDistinctVersions races several submissions for the same
+// record - each must get its own version number from the meta counter rather than colliding on
+// the unique (record_id, version) index.
+func (suite *PublisherDataTestSuite) TestConcurrentUpdatePublisherAllocatesDistinctVersions() {
+	const writers = 8
+	versions := make(chan int, writers)
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+				Name: fmt.Sprintf("Concurrent Proposal %d", i),
+			}, models.VersionStateSubmitted, nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			versions <- submitted.Version
+		}(i)
+	}
+	wg.Wait()
+	close(versions)
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(suite.T(), err)
+	}
+	seen := map[int]bool{}
+	for v := range versions {
+		assert.False(suite.T(), seen[v], "version %d allocated twice", v)
+		seen[v] = true
+	}
+	assert.Len(suite.T(), seen, writers)
+}
+
+// TestDeletePublisherCascadesToVolumeReferences covers the engine's purge for a listed
+// reference - a publisher id inside volume versions' publisher_ids.
+func (suite *PublisherDataTestSuite) TestDeletePublisherCascadesToVolumeReferences() {
+	ctx := suite.T().Context()
+	volumeID, err := AddVolume(ctx, &vo.VolumeVO{
+		Title: "Published Volume", Publishers: []*vo.PublisherVO{{ID: suite.seedPublisherID}},
+	})
+	assert.NoError(suite.T(), err)
+
+	err = DeletePublisher(ctx, suite.seedPublisherID, CascadeRefuse)
+	var dependencies *DependencyError
+	if assert.ErrorAs(suite.T(), err, &dependencies) {
+		assert.Equal(suite.T(), []Dependent{{Type: "volume", ID: *volumeID}}, dependencies.Dependents)
+	}
+
+	assert.NoError(suite.T(), DeletePublisher(ctx, suite.seedPublisherID, CascadeDelete))
+	_, err = GetPublisher(ctx, suite.seedPublisherID)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+
+	version, err := volumeVersioning.getVersion(ctx, *volumeID, 1)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), version.PublisherIds)
+}
+
 // TestSoftDeletePublisherLifecycle exercises entityVersioningConfig's shared soft-delete engine -
-// publisher, studio, person, license, and system all route through the identical generic code,
-// so this one test covers that engine; Volume gets its own test since its path is bespoke.
+// volume, publisher, studio, person, license, and system all route through the identical generic
+// code, so this one test covers that engine; Volume keeps its own test from before it moved onto
+// the engine.
 func (suite *PublisherDataTestSuite) TestSoftDeletePublisherLifecycle() {
 	err := SoftDeletePublisher(suite.T().Context(), suite.seedPublisherID, "admin-1")
 	assert.NoError(suite.T(), err)
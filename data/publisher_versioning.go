@@ -3,7 +3,6 @@ package data
 import (
 	"context"
 	"net/url"
-	"strings"
 	"time"
 
 	apiutil "github.com/sweetrpg/api-core.go/util"
@@ -12,7 +11,6 @@ import (
 	modelcore "github.com/sweetrpg/model-core.go/models"
 	modelcoreutil "github.com/sweetrpg/model-core.go/util"
 	modelcorevo "github.com/sweetrpg/model-core.go/vo"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -21,14 +19,22 @@ const (
 	publisherVersionCollection = "publishers_versions"
 )
 
+// PublisherApprovalPolicy is VolumeApprovalPolicy for publishers.
+var PublisherApprovalPolicy ApprovalPolicy
+
+// PublisherSubmissionCap is VolumeSubmissionCap for publishers.
+var PublisherSubmissionCap SubmissionCap
+
 var publisherVersioning = entityVersioningConfig[models.PublisherVersion]{
 	metaCollection:    publisherMetaCollection,
 	versionCollection: publisherVersionCollection,
 	typeName:          "publisher",
-	lifecycle:         func(v *models.PublisherVersion) *models.VersionLifecycle { return &v.VersionLifecycle },
+	lifecycle:         func(v *models.PublisherVersion) models.VersionLifecycle { return v.VersionLifecycle },
+	setLifecycle:      func(v *models.PublisherVersion, lc models.VersionLifecycle) { v.VersionLifecycle = lc },
 	setID:             func(v *models.PublisherVersion, id string) { v.ID = id },
 	setRecordID:       func(v *models.PublisherVersion, id string) { v.RecordID = id },
 	setVersion:        func(v *models.PublisherVersion, n int) { v.Version = n },
+	version:           func(v *models.PublisherVersion) int { return v.Version },
 	recordID:          func(v *models.PublisherVersion) string { return v.RecordID },
 	displayName:       func(v *models.PublisherVersion) string { return v.Name },
 	fields: map[string]entityFieldAccessor[models.PublisherVersion]{
@@ -36,9 +42,17 @@ var publisherVersioning = entityVersioningConfig[models.PublisherVersion]{
 		"address":    {get: func(v *models.PublisherVersion) any { return v.Address }, set: func(v *models.PublisherVersion, val any) { v.Address = val.(string) }},
 		"website":    {get: func(v *models.PublisherVersion) any { return v.Website }, set: func(v *models.PublisherVersion, val any) { v.Website = val.(url.URL) }},
 		"notes":      {get: func(v *models.PublisherVersion) any { return v.Notes }, set: func(v *models.PublisherVersion, val any) { v.Notes = val.(string) }},
-		"properties": {get: func(v *models.PublisherVersion) any { return v.Properties }, set: func(v *models.PublisherVersion, val any) { v.Properties = val.([]modelcore.Property) }},
-		"tags":       {get: func(v *models.PublisherVersion) any { return v.Tags }, set: func(v *models.PublisherVersion, val any) { v.Tags = val.([]modelcore.Tag) }},
+		"properties": {get: func(v *models.PublisherVersion) any { return v.Properties }, set: func(v *models.PublisherVersion, val any) { v.Properties = val.([]modelcore.Property) }, setMerge: true},
+		"tags":       {get: func(v *models.PublisherVersion) any { return v.Tags }, set: func(v *models.PublisherVersion, val any) { v.Tags = val.([]modelcore.Tag) }, setMerge: true},
+	},
+	references: []entityReference{
+		{dependentType: "volume", collection: volumeVersionCollection, field: "publisher_ids", listed: true},
+	},
+	search: func(v *models.PublisherVersion) (string, []string) {
+		return v.Name, tagSearchText(v.Tags)
 	},
+	approvalPolicy: &PublisherApprovalPolicy,
+	submissionCap:  &PublisherSubmissionCap,
 }
 
 // EnsurePublisherVersioningIndexes creates the indexes publisher version queries rely on. Safe to
@@ -53,6 +67,12 @@ func SoftDeletePublisher(c context.Context, id string, deletedBy string) error {
 	return publisherVersioning.softDelete(c, id, deletedBy)
 }
 
+// DeletePublisher hard-deletes a publisher and its version history, handling volume versions still
+// listing it in publisher_ids per policy - see DeleteVolume.
+func DeletePublisher(c context.Context, id string, policy CascadePolicy) error {
+	return publisherVersioning.purge(c, id, policy)
+}
+
 // RestorePublisher clears a soft-deleted publisher's deletion, returning it to every normal read
 // path.
 func RestorePublisher(c context.Context, id string) error {
@@ -102,32 +122,23 @@ func AddPublisher(c context.Context, publisher *vo.PublisherVO) (*string, error)
 }
 
 // GetPublisher returns the flattened view of a publisher - matching the shape this function
-// returned before meta/version were split.
+// returned before meta/version were split. The id of a publisher merged into another (see
+// MergeRecords) returns the one it was merged into.
 func GetPublisher(c context.Context, id string) (*vo.PublisherVO, error) {
-	meta, err := publisherVersioning.getMeta(c, id)
-	if err != nil {
-		return nil, err
-	}
-	if meta == nil {
-		return nil, nil
-	}
-	version, err := publisherVersioning.getVersion(c, id, meta.CurrentVersion)
+	meta, version, err := publisherVersioning.getCurrentFollowingMerges(c, id)
 	if err != nil {
 		return nil, err
 	}
-	if version == nil {
-		return nil, nil
-	}
 	return flattenPublisher(meta, version), nil
 }
 
 // UpdatePublisher creates a new version of the publisher rather than mutating in place - state
 // VersionStateLive goes current immediately (editor/admin); VersionStateSubmitted leaves the
 // current pointer untouched (submitter).
-func UpdatePublisher(c context.Context, id string, publisher *vo.PublisherVO, state models.VersionState) (*vo.PublisherVersionVO, error) {
+func UpdatePublisher(c context.Context, id string, publisher *vo.PublisherVO, state models.VersionState, expectedCurrentVersion *int) (*vo.PublisherVersionVO, error) {
 	version := publisherVersionFields(publisher)
-	result, err := publisherVersioning.createVersion(c, id, &version, state, publisher.UpdatedBy)
-	if err != nil || result == nil {
+	result, err := publisherVersioning.createVersion(c, id, &version, state, publisher.UpdatedBy, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return publisherVersionToVO(result), nil
@@ -148,22 +159,42 @@ func ListPublisherVersions(c context.Context, id string) ([]*vo.PublisherVersion
 
 // GetPublisherVersion returns one version's full snapshot, regardless of whether it's current.
 func GetPublisherVersion(c context.Context, id string, version int) (*vo.PublisherVersionVO, error) {
-	result, err := publisherVersioning.getVersion(c, id, version)
-	if err != nil || result == nil {
+	result, err := publisherVersioning.requireVersion(c, id, version)
+	if err != nil {
 		return nil, err
 	}
 	return publisherVersionToVO(result), nil
 }
 
+// DiffPublisherVersions is DiffVolumeVersions for a publisher.
+func DiffPublisherVersions(c context.Context, id string, from, to int) (*VersionDiff, error) {
+	return publisherVersioning.diffVersions(c, id, from, to)
+}
+
+// DiffPublisherSubmission is DiffVolumeSubmission for a publisher, previewing
+// AcceptPublisherVersion.
+func DiffPublisherSubmission(c context.Context, id string, version int) (*SubmissionDiff, error) {
+	return publisherVersioning.diffSubmission(c, id, version)
+}
+
 // AcceptPublisherVersion reviews a submitted version - see AcceptVolumeVersion's doc comment.
-func AcceptPublisherVersion(c context.Context, id string, version int, selectedFields []string, reviewedBy string, reviewNote *string) (*vo.PublisherVersionVO, []string, error) {
-	result, conflicts, err := publisherVersioning.acceptVersion(c, id, version, selectedFields, reviewedBy, reviewNote)
-	if err != nil || result == nil {
+func AcceptPublisherVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*vo.PublisherVersionVO, []string, error) {
+	result, conflicts, err := publisherVersioning.acceptVersion(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, expectedCurrentVersion)
+	if err != nil {
 		return nil, conflicts, err
 	}
 	return publisherVersionToVO(result), conflicts, nil
 }
 
+// PreviewAcceptPublisherVersion is PreviewAcceptVolumeVersion for a publisher.
+func PreviewAcceptPublisherVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*AcceptPreview[vo.PublisherVersionVO], error) {
+	plan, err := publisherVersioning.previewAccept(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, nil, expectedCurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	return newAcceptPreview(plan, publisherVersionToVO), nil
+}
+
 // RejectPublisherVersion marks a submitted version rejected.
 func RejectPublisherVersion(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
 	return publisherVersioning.rejectVersion(c, id, version, reviewedBy, reviewNote)
@@ -172,16 +203,16 @@ func RejectPublisherVersion(c context.Context, id string, version int, reviewedB
 // RetractPublisherVersion lets the original submitter withdraw their own pending submission.
 func RetractPublisherVersion(c context.Context, id string, version int, submitterID string) (*vo.PublisherVersionVO, error) {
 	result, err := publisherVersioning.retractVersion(c, id, version, submitterID)
-	if err != nil || result == nil {
+	if err != nil {
 		return nil, err
 	}
 	return publisherVersionToVO(result), nil
 }
 
 // SetCurrentPublisherVersion rolls a publisher back (or forward) to an arbitrary existing version.
-func SetCurrentPublisherVersion(c context.Context, id string, version int) (*vo.PublisherVersionVO, error) {
-	result, err := publisherVersioning.setCurrentVersion(c, id, version)
-	if err != nil || result == nil {
+func SetCurrentPublisherVersion(c context.Context, id string, version int, expectedCurrentVersion *int) (*vo.PublisherVersionVO, error) {
+	result, err := publisherVersioning.setCurrentVersion(c, id, version, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return publisherVersionToVO(result), nil
@@ -196,7 +227,7 @@ func CountSubmittedPublisherVersionsBySubmitter(c context.Context, submittedBy s
 func QueryPublishers(c context.Context, params apiutil.QueryParams) ([]*vo.PublisherVO, error) {
 	filter, sort, projection := apiutil.ConvertQueryParams(params)
 	filter = append(filter, bson.E{Key: "deleted_at", Value: nil})
-	metas, err := database.Query[models.EntityMeta](publisherMetaCollection, filter, sort, projection, params.Start, params.Limit)
+	metas, err := storeQuery[models.EntityMeta](c, publisherMetaCollection, filter, sort, projection, params.Start, params.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -214,22 +245,9 @@ func QueryPublishers(c context.Context, params apiutil.QueryParams) ([]*vo.Publi
 	return vos, nil
 }
 
-// SearchPublishers finds live publishers whose name contains query (case-insensitive), scanning
-// the full collection - see data.SearchPersons for why this scans in memory rather than pushing
-// the match down to Mongo.
-func SearchPublishers(c context.Context, query string) ([]*vo.PublisherVO, error) {
-	all, err := QueryPublishers(c, apiutil.QueryParams{Limit: searchScanLimit})
-	if err != nil {
-		return nil, err
-	}
-	needle := strings.ToLower(query)
-	matches := make([]*vo.PublisherVO, 0, len(all))
-	for _, p := range all {
-		if strings.Contains(strings.ToLower(p.Name), needle) {
-			matches = append(matches, p)
-		}
-	}
-	return matches, nil
+// SearchPublishers is SearchVolumes for publishers, matching name and tags.
+func SearchPublishers(c context.Context, query string, start, limit int) ([]*vo.PublisherVO, error) {
+	return searchEntity(c, "publisher", query, start, limit, resolvePublishers)
 }
 
 // CreateSubmittedPublisherVersion creates a submitted version stamped with a caller-supplied
@@ -238,8 +256,8 @@ func SearchPublishers(c context.Context, query string) ([]*vo.PublisherVO, error
 // data.CreateSubmittedVolumeVersion, the bespoke original this mirrors).
 func CreateSubmittedPublisherVersion(c context.Context, id string, publisher *vo.PublisherVO, submittedBy string, submittedAt time.Time) (*vo.PublisherVersionVO, error) {
 	version := publisherVersionFields(publisher)
-	result, err := publisherVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt)
-	if err != nil || result == nil {
+	result, err := publisherVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt, nil)
+	if err != nil {
 		return nil, err
 	}
 	return publisherVersionToVO(result), nil
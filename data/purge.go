@@ -0,0 +1,173 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CascadePolicy selects what a hard delete (DeleteVolume, DeletePublisher, ...) does with
+// documents that still reference the record being purged.
+type CascadePolicy int
+
+const (
+	// CascadeRefuse deletes nothing if anything still references the record, failing with a
+	// *DependencyError that lists every dependent instead. The zero value, so a caller has to
+	// opt in to destroying or orphaning data.
+	CascadeRefuse CascadePolicy = iota
+	// CascadeDelete deletes dependents that can't exist without the record (a volume's
+	// contributions and reviews, a person's contributions) and strips the record's id from list
+	// references (a publisher id in a volume version's publisher_ids).
+	CascadeDelete
+	// CascadeOrphan leaves dependents in place, stamping owned dependents with orphaned_at so
+	// they can be found and cleaned up later. List references are left as they are - read paths
+	// already skip an id that no longer resolves.
+	CascadeOrphan
+)
+
+// entityReference describes one place another collection points at an engine entity's record
+// id - the dependents a purge has to account for.
+type entityReference struct {
+	dependentType string // reported in a DependencyError, e.g. "contribution"
+	collection    string
+	field         string // bson key holding the referenced id
+	// listed is true for an array-of-ids field on version documents (e.g. publisher_ids on
+	// volumes_versions), where the dependent is the version's record rather than the document,
+	// and a cascade pulls the id out of the array instead of deleting the document.
+	listed bool
+	// fold, if set, replaces the plain id rewrite MergeRecords does for this reference - for a
+	// collection where the survivor and the loser can't both hold the same slot.
+	fold func(c context.Context, survivorID, loserID, mergedBy string) error
+}
+
+// dependencyDocument is the projection a purge reads dependents through - _id for owned
+// dependents, record_id for listed ones.
+type dependencyDocument struct {
+	ID       string `bson:"_id"`
+	RecordID string `bson:"record_id"`
+}
+
+// dependents lists every document referencing id across cfg.references, in a stable order.
+func (cfg entityVersioningConfig[T]) dependents(c context.Context, id string) ([]Dependent, error) {
+	var found []Dependent
+	for _, ref := range cfg.references {
+		projection := bson.D{{Key: "_id", Value: 1}, {Key: "record_id", Value: 1}}
+		docs, err := storeQuery[dependencyDocument](c, ref.collection, bson.D{{Key: ref.field, Value: id}}, nil, projection, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		seen := map[string]bool{}
+		for _, doc := range docs {
+			dependentID := doc.ID
+			if ref.listed {
+				dependentID = doc.RecordID
+			}
+			if seen[dependentID] {
+				continue
+			}
+			seen[dependentID] = true
+			found = append(found, Dependent{Type: ref.dependentType, ID: dependentID})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Type != found[j].Type {
+			return found[i].Type < found[j].Type
+		}
+		return found[i].ID < found[j].ID
+	})
+	return found, nil
+}
+
+// purge hard-deletes a record - its meta record and every version - handling dependents per
+// policy, all in one transaction. Unlike softDelete this can't be undone.
+func (cfg entityVersioningConfig[T]) purge(c context.Context, id string, policy CascadePolicy) error {
+	return withTransaction(c, func(tc context.Context) error {
+		return cfg.purgeTx(tc, id, policy)
+	})
+}
+
+// purgeTx is purge's body, run inside its transaction.
+func (cfg entityVersioningConfig[T]) purgeTx(c context.Context, id string, policy CascadePolicy) error {
+	if _, err := cfg.requireMeta(c, id); err != nil {
+		return err
+	}
+
+	dependents, err := cfg.dependents(c, id)
+	if err != nil {
+		return err
+	}
+	if len(dependents) > 0 && policy == CascadeRefuse {
+		return &DependencyError{Type: cfg.typeName, ID: id, Dependents: dependents}
+	}
+	if cfg.onPurge != nil {
+		if err := cfg.onPurge(c, id, policy); err != nil {
+			return err
+		}
+	}
+	if len(dependents) > 0 {
+		switch policy {
+		case CascadeDelete:
+			if err := cfg.cascadeDelete(c, id); err != nil {
+				return err
+			}
+		case CascadeOrphan:
+			if err := cfg.orphanDependents(c, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := Storage.DeleteMany(c, cfg.versionCollection, bson.D{{Key: "record_id", Value: id}}); err != nil {
+		return err
+	}
+	if _, err := Storage.DeleteOne(c, cfg.metaCollection, bson.D{{Key: "_id", Value: id}}); err != nil {
+		return err
+	}
+	if err := cfg.unindexSearch(c, id); err != nil {
+		return err
+	}
+	if err := appendEvent(c, Event{Type: EventRecordPurged, Entity: cfg.typeName, RecordID: id}); err != nil {
+		return err
+	}
+
+	logging.Logger.Info("purge", "type", cfg.typeName, "id", id, "policy", policy, "dependents", len(dependents))
+	return nil
+}
+
+func (cfg entityVersioningConfig[T]) cascadeDelete(c context.Context, id string) error {
+	for _, ref := range cfg.references {
+		filter := bson.D{{Key: ref.field, Value: id}}
+		var err error
+		if ref.listed {
+			_, err = Storage.UpdateMany(c, ref.collection, filter, bson.D{{Key: "$pull", Value: bson.D{{Key: ref.field, Value: id}}}})
+		} else {
+			_, err = Storage.DeleteMany(c, ref.collection, filter)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg entityVersioningConfig[T]) orphanDependents(c context.Context, id string) error {
+	now := time.Now()
+	for _, ref := range cfg.references {
+		if ref.listed {
+			continue
+		}
+		_, err := Storage.UpdateMany(
+			c,
+			ref.collection,
+			bson.D{{Key: ref.field, Value: id}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "orphaned_at", Value: now}}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
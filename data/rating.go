@@ -0,0 +1,235 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// A review's rating is a whole number of stars from MinRating to MaxRating, or 0 for a review
+// left unrated - which counts toward nothing in the volume's aggregates.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// volumeRatingCollection holds one aggregate document per rated volume, keyed by volume id.
+const volumeRatingCollection = "volume_ratings"
+
+// Version-document fields carrying a volume's rating aggregates, for QueryVolumes to sort by -
+// pass either as a params.Sort field. Kept on every version of the volume so whichever goes live
+// already has them. A volume never rated has neither field.
+const (
+	RatingMeanField  = "rating_mean"
+	RatingCountField = "rating_count"
+)
+
+// ratingField is the review-document field holding the review's rating.
+const ratingField = "rating"
+
+// volumeRatingAggregate is the stored form of a volume's rating aggregates. Histogram is keyed by
+// the rating as a string ("1" to "5"), since bson document keys are strings.
+type volumeRatingAggregate struct {
+	ID        string         `bson:"_id"`
+	Count     int            `bson:"count"`
+	Sum       int            `bson:"sum"`
+	Histogram map[string]int `bson:"histogram"`
+}
+
+// VolumeRatingSummary is a volume's rating aggregates over its live, rated reviews. Histogram
+// has an entry for every rating from MinRating to MaxRating, zero where no review gave it; Mean
+// is 0 when Count is.
+type VolumeRatingSummary struct {
+	VolumeID  string      `json:"volumeId"`
+	Count     int         `json:"count"`
+	Mean      float64     `json:"mean"`
+	Histogram map[int]int `json:"histogram"`
+}
+
+// checkRating returns a *RatingError unless rating is 0 or within MinRating..MaxRating.
+func checkRating(rating int) error {
+	if rating != 0 && (rating < MinRating || rating > MaxRating) {
+		return &RatingError{Rating: rating}
+	}
+	return nil
+}
+
+// applyRatingChange moves volumeID's aggregates from a review rated before to one rated after (0
+// for unrated, or for no review at all - so an add is (0, r) and a delete (r, 0)), then copies
+// the new mean and count onto the volume's versions. Runs inside the caller's transaction.
+func applyRatingChange(c context.Context, volumeID string, before, after int) error {
+	if before == after {
+		return nil
+	}
+	inc := bson.D{{Key: "sum", Value: after - before}}
+	switch {
+	case before == 0:
+		inc = append(inc, bson.E{Key: "count", Value: 1}, bson.E{Key: "histogram." + strconv.Itoa(after), Value: 1})
+	case after == 0:
+		inc = append(inc, bson.E{Key: "count", Value: -1}, bson.E{Key: "histogram." + strconv.Itoa(before), Value: -1})
+	default:
+		// A re-rating moves one review between buckets; the count stays put.
+		inc = append(inc, bson.E{Key: "histogram." + strconv.Itoa(before), Value: -1}, bson.E{Key: "histogram." + strconv.Itoa(after), Value: 1})
+	}
+
+	// Created on first use, the way lockSubmitter creates its lock document: a racing creator's
+	// insert fails on the duplicate _id and it retries the increment.
+	var raw bson.Raw
+	for attempt := 0; attempt < 2 && raw == nil; attempt++ {
+		var err error
+		raw, err = Storage.FindOneAndUpdate(c, volumeRatingCollection, bson.D{{Key: "_id", Value: volumeID}}, bson.D{{Key: "$inc", Value: inc}})
+		if err != nil {
+			return err
+		}
+		if raw == nil {
+			seed := volumeRatingAggregate{ID: volumeID, Histogram: map[string]int{}}
+			if err := Storage.Insert(c, volumeRatingCollection, seed); err != nil && !mongo.IsDuplicateKeyError(err) {
+				return err
+			}
+		}
+	}
+	if raw == nil {
+		return fmt.Errorf("volume %s: rating aggregate could not be created", volumeID)
+	}
+	var aggregate volumeRatingAggregate
+	if err := bson.Unmarshal(raw, &aggregate); err != nil {
+		return err
+	}
+
+	_, err := Storage.UpdateMany(c, volumeVersionCollection, bson.D{{Key: "record_id", Value: volumeID}}, bson.D{{Key: "$set", Value: aggregate.versionFields()}})
+	return err
+}
+
+// purgeVolumeRatings is purgeVolume's rating half: it drops the purged volume's rating
+// aggregate, and when the purge cascades to the volume's reviews, records each live one's
+// deletion in the outbox as DeleteReview would.
+func purgeVolumeRatings(c context.Context, volumeID string, policy CascadePolicy) error {
+	if policy == CascadeDelete {
+		filter := bson.D{{Key: "volume_id", Value: volumeID}, {Key: "deleted_at", Value: nil}}
+		reviews, err := storeQuery[reviewDocument](c, reviewCollection, filter, nil, bson.D{{Key: "_id", Value: 1}}, 0, 0)
+		if err != nil {
+			return err
+		}
+		for _, review := range reviews {
+			if err := appendEvent(c, Event{Type: EventReviewDeleted, Entity: "review", RecordID: review.ID}); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := Storage.DeleteOne(c, volumeRatingCollection, bson.D{{Key: "_id", Value: volumeID}})
+	return err
+}
+
+// mean is the average rating, 0 with no rated reviews.
+func (a volumeRatingAggregate) mean() float64 {
+	if a.Count == 0 {
+		return 0
+	}
+	return float64(a.Sum) / float64(a.Count)
+}
+
+// versionFields are the rating_mean/rating_count fields a's volume's versions carry.
+func (a volumeRatingAggregate) versionFields() bson.D {
+	return bson.D{{Key: RatingMeanField, Value: a.mean()}, {Key: RatingCountField, Value: a.Count}}
+}
+
+// getVolumeRatingAggregate reads volumeID's aggregate, or nil if it has never been rated.
+func getVolumeRatingAggregate(c context.Context, volumeID string) (*volumeRatingAggregate, error) {
+	results, err := storeQuery[volumeRatingAggregate](c, volumeRatingCollection, bson.D{{Key: "_id", Value: volumeID}}, nil, nil, 0, 1)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// volumeRatingFields is the volume config's extraFields: the current rating fields, so a new
+// version doesn't drop out of a rating sort until the next review.
+func volumeRatingFields(c context.Context, volumeID string) (bson.D, error) {
+	aggregate, err := getVolumeRatingAggregate(c, volumeID)
+	if err != nil || aggregate == nil {
+		return nil, err
+	}
+	return aggregate.versionFields(), nil
+}
+
+// GetVolumeRatingSummary returns volumeID's rating aggregates - all zero for a volume without
+// rated reviews. A missing or soft-deleted volume is a *NotFoundError.
+func GetVolumeRatingSummary(c context.Context, volumeID string) (*VolumeRatingSummary, error) {
+	_, span := otel.Tracer("volume").Start(c, "db-get-volume-rating-summary", oteltrace.WithAttributes(attribute.String("volumeId", volumeID)))
+	defer span.End()
+
+	meta, err := volumeVersioning.requireMeta(c, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if meta.DeletedAt != nil {
+		return nil, &NotFoundError{Type: "volume", ID: volumeID}
+	}
+	aggregate, err := getVolumeRatingAggregate(c, volumeID)
+	if err != nil {
+		logging.Logger.Error("Error while querying database for volume ratings", "error", err)
+		return nil, err
+	}
+	if aggregate == nil {
+		aggregate = &volumeRatingAggregate{ID: volumeID}
+	}
+
+	summary := &VolumeRatingSummary{VolumeID: volumeID, Count: aggregate.Count, Mean: aggregate.mean(), Histogram: map[int]int{}}
+	for rating := MinRating; rating <= MaxRating; rating++ {
+		summary.Histogram[rating] = aggregate.Histogram[strconv.Itoa(rating)]
+	}
+	return summary, nil
+}
+
+// RebuildVolumeRatings recomputes every volume's aggregates from its live reviews, replacing the
+// incrementally maintained ones - for recovery should they ever drift. Returns how many volumes
+// have rated reviews.
+func RebuildVolumeRatings(c context.Context) (int, error) {
+	filter := bson.D{{Key: "deleted_at", Value: nil}, {Key: ratingField, Value: bson.D{{Key: "$gte", Value: MinRating}}}}
+	reviews, err := storeQuery[reviewDocument](c, reviewCollection, filter, nil, nil, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	aggregates := map[string]*volumeRatingAggregate{}
+	for _, review := range reviews {
+		aggregate, ok := aggregates[review.VolumeId]
+		if !ok {
+			aggregate = &volumeRatingAggregate{ID: review.VolumeId, Histogram: map[string]int{}}
+			aggregates[review.VolumeId] = aggregate
+		}
+		aggregate.Count++
+		aggregate.Sum += review.Rating
+		aggregate.Histogram[strconv.Itoa(review.Rating)]++
+	}
+
+	err = withTransaction(c, func(tc context.Context) error {
+		if _, err := Storage.DeleteMany(tc, volumeRatingCollection, bson.D{}); err != nil {
+			return err
+		}
+		unset := bson.D{{Key: "$unset", Value: bson.D{{Key: RatingMeanField, Value: ""}, {Key: RatingCountField, Value: ""}}}}
+		if _, err := Storage.UpdateMany(tc, volumeVersionCollection, bson.D{}, unset); err != nil {
+			return err
+		}
+		for volumeID, aggregate := range aggregates {
+			if err := Storage.Insert(tc, volumeRatingCollection, aggregate); err != nil {
+				return err
+			}
+			_, err := Storage.UpdateMany(tc, volumeVersionCollection, bson.D{{Key: "record_id", Value: volumeID}}, bson.D{{Key: "$set", Value: aggregate.versionFields()}})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("rebuild volume ratings: %w", err)
+	}
+	return len(aggregates), nil
+}
@@ -0,0 +1,179 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// getMetas is getMeta for many records at once - one $in query, keyed by record id. Ids with no
+// meta record are simply absent from the map.
+func (cfg entityVersioningConfig[T]) getMetas(c context.Context, ids []string) (map[string]*models.EntityMeta, error) {
+	metas := map[string]*models.EntityMeta{}
+	if len(ids) == 0 {
+		return metas, nil
+	}
+	results, err := storeQuery[models.EntityMeta](c, cfg.metaCollection, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}, nil, nil, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, meta := range results {
+		metas[meta.ID] = meta
+	}
+	return metas, nil
+}
+
+// getCurrentMany is getCurrent for many records at once: one query for the metas and one for
+// their current versions, both keyed by record id. A record missing either half is left out of
+// both maps rather than failing the batch.
+func (cfg entityVersioningConfig[T]) getCurrentMany(c context.Context, ids []string) (map[string]*models.EntityMeta, map[string]*T, error) {
+	metas, err := cfg.getMetas(c, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	versions := map[string]*T{}
+	if len(metas) == 0 {
+		return metas, versions, nil
+	}
+
+	current := make(bson.A, 0, len(metas))
+	for id, meta := range metas {
+		current = append(current, bson.D{{Key: "record_id", Value: id}, {Key: "version", Value: meta.CurrentVersion}})
+	}
+	results, err := storeQuery[T](c, cfg.versionCollection, bson.D{{Key: "$or", Value: current}}, nil, nil, 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, version := range results {
+		versions[cfg.recordID(version)] = version
+	}
+	for id := range metas {
+		if _, ok := versions[id]; !ok {
+			delete(metas, id)
+		}
+	}
+	return metas, versions, nil
+}
+
+// resolveCurrent batch-loads ids through cfg and flattens each into its VO, keyed by id. Like
+// the per-id Get* lookups it replaces, a failed or missing lookup is logged and skipped rather
+// than failing the read that needed it.
+func resolveCurrent[T any, V any](c context.Context, cfg entityVersioningConfig[T], ids []string, flatten func(*models.EntityMeta, *T) *V) map[string]*V {
+	resolved := map[string]*V{}
+	metas, versions, err := cfg.getCurrentMany(c, uniqueIDs(ids))
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while resolving %s relations: %+v", cfg.typeName, err))
+		return resolved
+	}
+	for id, meta := range metas {
+		resolved[id] = flatten(meta, versions[id])
+	}
+	return resolved
+}
+
+// uniqueIDs drops duplicate and empty ids, keeping first-seen order.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
+// volumeRelations holds every relation a set of volume versions references, resolved up front so
+// flattening a page of volumes costs a fixed number of queries rather than several per volume.
+type volumeRelations struct {
+	systems    map[string]*vo.SystemVO
+	publishers map[string]*vo.PublisherVO
+	studios    map[string]*vo.StudioVO
+	licenses   map[string]*vo.LicenseVO
+}
+
+// systemsMapThreshold is how many distinct system ids a batch needs before loadVolumeRelations
+// fetches gamesystems-api's whole list in one call instead of looking each id up on its own - a
+// single-volume read references a system or two and shouldn't pay for the whole list, while a
+// page of volumes was measured taking 10+ seconds under the old per-volume lookups.
+const systemsMapThreshold = 5
+
+// loadVolumeRelations resolves the systems, publishers, studios and licenses referenced across
+// versions - two $in queries per type, however many versions and ids there are, and for the
+// systems either a GetSystem per distinct id or, from systemsMapThreshold ids up, a single
+// GetSystemsMap call. No system ids, no gamesystems-api call.
+func loadVolumeRelations(c context.Context, versions []*models.VolumeVersion) *volumeRelations {
+	var systemIds, publisherIds, studioIds, licenseIds []string
+	for _, version := range versions {
+		systemIds = append(systemIds, version.SystemIds...)
+		publisherIds = append(publisherIds, version.PublisherIds...)
+		studioIds = append(studioIds, version.StudioIds...)
+		licenseIds = append(licenseIds, version.LicenseIds...)
+	}
+	return &volumeRelations{
+		systems:    resolveSystems(c, uniqueIDs(systemIds)),
+		publishers: resolveCurrent(c, publisherVersioning, publisherIds, flattenPublisher),
+		studios:    resolveCurrent(c, studioVersioning, studioIds, flattenStudio),
+		licenses:   resolveCurrent(c, licenseVersioning, licenseIds, flattenLicense),
+	}
+}
+
+// resolveSystems looks up ids against gamesystems-api, keyed by id - see loadVolumeRelations for
+// when it fetches the whole list instead. A failed or missing lookup is logged and skipped, like
+// resolveCurrent's.
+func resolveSystems(c context.Context, ids []string) map[string]*vo.SystemVO {
+	if len(ids) == 0 || GameSystemsClient == nil {
+		return map[string]*vo.SystemVO{}
+	}
+	if len(ids) >= systemsMapThreshold {
+		systemsMap, err := GetSystemsMap(c)
+		if err != nil {
+			logging.Logger.Error(fmt.Sprintf("Error while fetching systems map for Volumes: %+v", err))
+			return map[string]*vo.SystemVO{}
+		}
+		return systemsMap
+	}
+
+	systems := make(map[string]*vo.SystemVO, len(ids))
+	for _, id := range ids {
+		system, err := GetSystem(c, id)
+		if err != nil {
+			logging.Logger.Error(fmt.Sprintf("Error while fetching system %s for Volumes: %+v", id, err))
+			continue
+		}
+		systems[id] = system
+	}
+	return systems
+}
+
+// loadVolumes flattens the current version of every volume in ids, keyed by id, for the reads
+// that embed a volume per row (contributions, reviews). Every relation across the batch is
+// resolved together (see loadVolumeRelations).
+func loadVolumes(c context.Context, ids []string) map[string]*vo.VolumeVO {
+	volumes := map[string]*vo.VolumeVO{}
+	metas, versions, err := volumeVersioning.getCurrentMany(c, uniqueIDs(ids))
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while resolving volume relations: %+v", err))
+		return volumes
+	}
+	if len(metas) == 0 {
+		return volumes
+	}
+
+	page := make([]*models.VolumeVersion, 0, len(versions))
+	for _, version := range versions {
+		page = append(page, version)
+	}
+	relations := loadVolumeRelations(c, page)
+
+	for id, meta := range metas {
+		volumes[id] = flattenVolume(c, meta, versions[id], relations)
+	}
+	return volumes
+}
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Repository is the storage this package reads and writes through - the handful of
+// collection-level operations every data function is built from. MongoRepository is the
+// production implementation; MemoryRepository stands in for it where no MongoDB is available
+// (the test suites without TEST_DB_URI).
+//
+// Filters, sorts, projections and updates are Mongo-shaped bson.D documents whichever
+// implementation is behind it, so callers don't branch on the backend.
+type Repository interface {
+	// Find returns the documents in collection matching filter, ordered by sort (nil for
+	// natural order), trimmed by projection (nil for whole documents), skipping start and
+	// returning at most limit (0 for no limit).
+	Find(c context.Context, collection string, filter, sort, projection bson.D, start, limit int) ([]bson.Raw, error)
+	// Count returns how many documents in collection match filter.
+	Count(c context.Context, collection string, filter bson.D) (int64, error)
+	// Insert adds document to collection.
+	Insert(c context.Context, collection string, document any) error
+	// UpdateOne applies update to the first document matching filter, returning how many
+	// documents matched (0 or 1).
+	UpdateOne(c context.Context, collection string, filter, update bson.D) (int64, error)
+	// UpdateMany applies update to every document matching filter, returning how many matched.
+	UpdateMany(c context.Context, collection string, filter, update bson.D) (int64, error)
+	// FindOneAndUpdate applies update to the first document matching filter and returns it as
+	// updated - nil (with a nil error) when nothing matched.
+	FindOneAndUpdate(c context.Context, collection string, filter, update bson.D) (bson.Raw, error)
+	// DeleteOne removes the first document matching filter, returning how many were removed.
+	DeleteOne(c context.Context, collection string, filter bson.D) (int64, error)
+	// DeleteMany removes every document matching filter, returning how many were removed.
+	DeleteMany(c context.Context, collection string, filter bson.D) (int64, error)
+	// EnsureIndex creates an index on keys if it doesn't exist yet.
+	EnsureIndex(c context.Context, collection string, keys bson.D, unique bool) error
+	// WithTransaction runs fn atomically, passing it the context its reads and writes must use
+	// to take part. Callers go through withTransaction, which applies Transactions on top.
+	WithTransaction(c context.Context, fn func(tc context.Context) error) error
+}
+
+// Storage is the Repository every data function uses. Set once at startup (see catalog-api's
+// cmd/catalog-api/main.go) or in a suite's SetupTest, before any data function is called.
+var Storage Repository = MongoRepository{}
+
+// storeQuery runs Storage.Find and decodes each document into a T.
+func storeQuery[T any](c context.Context, collection string, filter, sort, projection bson.D, start, limit int) ([]*T, error) {
+	docs, err := Storage.Find(c, collection, filter, sort, projection, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*T, 0, len(docs))
+	for _, doc := range docs {
+		result := new(T)
+		if err := bson.Unmarshal(doc, result); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// storeInsert is Storage.Insert, typed like storeQuery for symmetry at call sites.
+func storeInsert[T any](c context.Context, collection string, document T) error {
+	return Storage.Insert(c, collection, document)
+}
@@ -0,0 +1,768 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MemoryRepository is a Repository held entirely in process memory, for running this package's
+// suites without a MongoDB. It understands the subset of Mongo's query language this package
+// uses: equality (including array membership), $eq/$ne/$gt/$gte/$lt/$lte/$in/$nin/$exists/
+// $regex/$size, $and/$or/$nor, dotted paths, include/exclude projections, and the
+// $set/$unset/$inc/$push/$addToSet/$pull update operators. Unique indexes are enforced, with
+// the same duplicate-key error Mongo returns.
+//
+// WithTransaction serializes transactions and rolls their writes back on error; a write made
+// outside a transaction while one is open is lost if that transaction rolls back.
+type MemoryRepository struct {
+	mu          sync.Mutex
+	txMu        sync.Mutex
+	collections map[string][]bson.D
+	unique      map[string][][]string
+}
+
+// NewMemoryRepository returns an empty MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		collections: map[string][]bson.D{},
+		unique:      map[string][][]string{},
+	}
+}
+
+// memoryTransactionKey marks a context already inside a MemoryRepository transaction, so a
+// nested withTransaction joins it rather than deadlocking on txMu.
+type memoryTransactionKey struct{}
+
+func (r *MemoryRepository) Find(_ context.Context, collection string, filter, sortOrder, projection bson.D, start, limit int) ([]bson.Raw, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	filter, err := normalizeDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+	var matched []bson.D
+	for _, doc := range r.collections[collection] {
+		ok, err := matchDocument(doc, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, doc)
+		}
+	}
+
+	if len(sortOrder) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			for _, key := range sortOrder {
+				a, _ := lookupPath(matched[i], key.Key)
+				b, _ := lookupPath(matched[j], key.Key)
+				cmp := compareValues(a, b)
+				if cmp == 0 {
+					continue
+				}
+				if direction, _ := toFloat(key.Value); direction < 0 {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+			return false
+		})
+	}
+
+	if start > 0 {
+		if start >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[start:]
+		}
+	}
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+
+	docs := make([]bson.Raw, 0, len(matched))
+	for _, doc := range matched {
+		raw, err := bson.Marshal(project(doc, projection))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, raw)
+	}
+	return docs, nil
+}
+
+func (r *MemoryRepository) Count(c context.Context, collection string, filter bson.D) (int64, error) {
+	docs, err := r.Find(c, collection, filter, nil, bson.D{{Key: "_id", Value: 1}}, 0, 0)
+	return int64(len(docs)), err
+}
+
+func (r *MemoryRepository) Insert(_ context.Context, collection string, document any) error {
+	doc, err := normalizeDocument(document)
+	if err != nil {
+		return err
+	}
+	if _, ok := lookupPath(doc, "_id"); !ok {
+		doc = append(bson.D{{Key: "_id", Value: primitive.NewObjectID()}}, doc...)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.checkUnique(collection, doc, -1); err != nil {
+		return err
+	}
+	r.collections[collection] = append(r.collections[collection], doc)
+	return nil
+}
+
+func (r *MemoryRepository) UpdateOne(_ context.Context, collection string, filter, update bson.D) (int64, error) {
+	matched, _, err := r.update(collection, filter, update, false)
+	return matched, err
+}
+
+func (r *MemoryRepository) UpdateMany(_ context.Context, collection string, filter, update bson.D) (int64, error) {
+	matched, _, err := r.update(collection, filter, update, true)
+	return matched, err
+}
+
+func (r *MemoryRepository) FindOneAndUpdate(_ context.Context, collection string, filter, update bson.D) (bson.Raw, error) {
+	_, updated, err := r.update(collection, filter, update, false)
+	if err != nil || updated == nil {
+		return nil, err
+	}
+	return bson.Marshal(updated)
+}
+
+func (r *MemoryRepository) DeleteOne(_ context.Context, collection string, filter bson.D) (int64, error) {
+	return r.delete(collection, filter, false)
+}
+
+func (r *MemoryRepository) DeleteMany(_ context.Context, collection string, filter bson.D) (int64, error) {
+	return r.delete(collection, filter, true)
+}
+
+func (r *MemoryRepository) EnsureIndex(_ context.Context, collection string, keys bson.D, unique bool) error {
+	if !unique {
+		return nil
+	}
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, key.Key)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.unique[collection] {
+		if strings.Join(existing, ",") == strings.Join(fields, ",") {
+			return nil
+		}
+	}
+	r.unique[collection] = append(r.unique[collection], fields)
+	return nil
+}
+
+func (r *MemoryRepository) WithTransaction(c context.Context, fn func(tc context.Context) error) error {
+	if c.Value(memoryTransactionKey{}) == r {
+		return fn(c)
+	}
+
+	r.txMu.Lock()
+	defer r.txMu.Unlock()
+
+	r.mu.Lock()
+	snapshot := make(map[string][]bson.D, len(r.collections))
+	for name, docs := range r.collections {
+		snapshot[name] = append([]bson.D(nil), docs...)
+	}
+	r.mu.Unlock()
+
+	if err := fn(context.WithValue(c, memoryTransactionKey{}, r)); err != nil {
+		r.mu.Lock()
+		r.collections = snapshot
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// update applies update to the first (or, with many, every) document matching filter. Documents
+// are replaced rather than edited in place, so a transaction snapshot never sees a later write.
+func (r *MemoryRepository) update(collection string, filter, update bson.D, many bool) (int64, bson.D, error) {
+	filter, err := normalizeDocument(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	update, err = normalizeDocument(update)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched int64
+	var last bson.D
+	docs := r.collections[collection]
+	for i, doc := range docs {
+		ok, err := matchDocument(doc, filter)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !ok {
+			continue
+		}
+		updated, err := applyUpdate(doc, update)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := r.checkUnique(collection, updated, i); err != nil {
+			return 0, nil, err
+		}
+		docs[i] = updated
+		matched++
+		last = updated
+		if !many {
+			break
+		}
+	}
+	return matched, last, nil
+}
+
+func (r *MemoryRepository) delete(collection string, filter bson.D, many bool) (int64, error) {
+	filter, err := normalizeDocument(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	kept := make([]bson.D, 0, len(r.collections[collection]))
+	for _, doc := range r.collections[collection] {
+		if many || deleted == 0 {
+			ok, err := matchDocument(doc, filter)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				deleted++
+				continue
+			}
+		}
+		kept = append(kept, doc)
+	}
+	r.collections[collection] = kept
+	return deleted, nil
+}
+
+// checkUnique fails with a duplicate-key error if doc collides with another document (any but
+// the one at index self) on _id or on one of collection's unique indexes.
+func (r *MemoryRepository) checkUnique(collection string, doc bson.D, self int) error {
+	indexes := append([][]string{{"_id"}}, r.unique[collection]...)
+	for i, other := range r.collections[collection] {
+		if i == self {
+			continue
+		}
+		for _, fields := range indexes {
+			same := true
+			for _, field := range fields {
+				a, _ := lookupPath(doc, field)
+				b, _ := lookupPath(other, field)
+				if compareValues(a, b) != 0 {
+					same = false
+					break
+				}
+			}
+			if same {
+				return mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+					Code:    11000,
+					Message: fmt.Sprintf("E11000 duplicate key error collection: %s index: %s", collection, strings.Join(fields, "_")),
+				}}}
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeDocument round-trips v through bson, so stored documents and the filters matched
+// against them share one representation (int32/int64/float64 numbers, primitive.DateTime times,
+// primitive.A arrays, primitive.D sub-documents).
+func normalizeDocument(v any) (bson.D, error) {
+	if v == nil {
+		return bson.D{}, nil
+	}
+	if d, ok := v.(bson.D); ok && d == nil {
+		return bson.D{}, nil
+	}
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// lookupPath resolves a dotted path into doc, reporting whether it was present.
+func lookupPath(doc bson.D, path string) (any, bool) {
+	head, rest, nested := strings.Cut(path, ".")
+	for _, e := range doc {
+		if e.Key != head {
+			continue
+		}
+		if !nested {
+			return e.Value, true
+		}
+		if sub, ok := e.Value.(bson.D); ok {
+			return lookupPath(sub, rest)
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// setPath returns doc with path set to value, creating intermediate sub-documents as needed.
+func setPath(doc bson.D, path string, value any) bson.D {
+	head, rest, nested := strings.Cut(path, ".")
+	out := append(bson.D(nil), doc...)
+	for i, e := range out {
+		if e.Key != head {
+			continue
+		}
+		if nested {
+			sub, _ := e.Value.(bson.D)
+			out[i].Value = setPath(sub, rest, value)
+		} else {
+			out[i].Value = value
+		}
+		return out
+	}
+	if nested {
+		return append(out, bson.E{Key: head, Value: setPath(nil, rest, value)})
+	}
+	return append(out, bson.E{Key: head, Value: value})
+}
+
+// unsetPath returns doc with path removed.
+func unsetPath(doc bson.D, path string) bson.D {
+	head, rest, nested := strings.Cut(path, ".")
+	out := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if e.Key != head {
+			out = append(out, e)
+			continue
+		}
+		if nested {
+			if sub, ok := e.Value.(bson.D); ok {
+				out = append(out, bson.E{Key: e.Key, Value: unsetPath(sub, rest)})
+				continue
+			}
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func matchDocument(doc bson.D, filter bson.D) (bool, error) {
+	for _, e := range filter {
+		var ok bool
+		var err error
+		switch e.Key {
+		case "$and", "$or", "$nor":
+			ok, err = matchLogical(doc, e.Key, e.Value)
+		default:
+			value, found := lookupPath(doc, e.Key)
+			ok, err = matchCondition(value, found, e.Value)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchLogical(doc bson.D, op string, clauses any) (bool, error) {
+	list, ok := clauses.(bson.A)
+	if !ok {
+		return false, fmt.Errorf("memory repository: %s needs an array", op)
+	}
+	for _, clause := range list {
+		sub, ok := clause.(bson.D)
+		if !ok {
+			return false, fmt.Errorf("memory repository: %s clause must be a document", op)
+		}
+		matched, err := matchDocument(doc, sub)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case op == "$and" && !matched:
+			return false, nil
+		case op == "$or" && matched:
+			return true, nil
+		case op == "$nor" && matched:
+			return false, nil
+		}
+	}
+	return op != "$or", nil
+}
+
+// matchCondition tests one field's value against a filter condition - an operator document, or
+// a plain value to match by equality.
+func matchCondition(value any, found bool, condition any) (bool, error) {
+	ops, ok := condition.(bson.D)
+	if !ok || len(ops) == 0 || !strings.HasPrefix(ops[0].Key, "$") {
+		return matchEqual(value, found, condition), nil
+	}
+
+	for _, op := range ops {
+		var ok bool
+		switch op.Key {
+		case "$eq":
+			ok = matchEqual(value, found, op.Value)
+		case "$ne":
+			ok = !matchEqual(value, found, op.Value)
+		case "$in", "$nin":
+			list, isList := op.Value.(bson.A)
+			if !isList {
+				return false, fmt.Errorf("memory repository: %s needs an array", op.Key)
+			}
+			for _, candidate := range list {
+				if matchEqual(value, found, candidate) {
+					ok = true
+					break
+				}
+			}
+			if op.Key == "$nin" {
+				ok = !ok
+			}
+		case "$exists":
+			want, _ := op.Value.(bool)
+			ok = found == want
+		case "$gt", "$gte", "$lt", "$lte":
+			ok = found && anyElement(value, func(v any) bool {
+				if v == nil || op.Value == nil || typeRank(v) != typeRank(op.Value) {
+					return false
+				}
+				cmp := compareValues(v, op.Value)
+				switch op.Key {
+				case "$gt":
+					return cmp > 0
+				case "$gte":
+					return cmp >= 0
+				case "$lt":
+					return cmp < 0
+				}
+				return cmp <= 0
+			})
+		case "$regex":
+			pattern, _ := op.Value.(string)
+			if regex, isRegex := op.Value.(primitive.Regex); isRegex {
+				pattern = regex.Pattern
+				if regex.Options != "" {
+					pattern = "(?" + regex.Options + ")" + pattern
+				}
+			}
+			if options, hasOptions := lookupPath(ops, "$options"); hasOptions {
+				pattern = "(?" + fmt.Sprint(options) + ")" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return false, err
+			}
+			ok = found && anyElement(value, func(v any) bool {
+				s, isString := v.(string)
+				return isString && re.MatchString(s)
+			})
+		case "$options":
+			ok = true
+		case "$size":
+			list, isList := value.(bson.A)
+			size, _ := toFloat(op.Value)
+			ok = isList && float64(len(list)) == size
+		default:
+			return false, fmt.Errorf("memory repository: unsupported query operator %s", op.Key)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchEqual is Mongo equality: nil matches a missing or null field, and a scalar matches an
+// array containing it.
+func matchEqual(value any, found bool, want any) bool {
+	if want == nil {
+		return !found || value == nil
+	}
+	if !found {
+		return false
+	}
+	if compareValues(value, want) == 0 {
+		return true
+	}
+	if list, ok := value.(bson.A); ok {
+		for _, element := range list {
+			if compareValues(element, want) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func anyElement(value any, test func(any) bool) bool {
+	if list, ok := value.(bson.A); ok {
+		for _, element := range list {
+			if test(element) {
+				return true
+			}
+		}
+		return false
+	}
+	return test(value)
+}
+
+func applyUpdate(doc bson.D, update bson.D) (bson.D, error) {
+	for _, op := range update {
+		fields, ok := op.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("memory repository: %s needs a document", op.Key)
+		}
+		for _, field := range fields {
+			current, found := lookupPath(doc, field.Key)
+			switch op.Key {
+			case "$set":
+				doc = setPath(doc, field.Key, field.Value)
+			case "$unset":
+				doc = unsetPath(doc, field.Key)
+			case "$inc":
+				if !found || current == nil {
+					doc = setPath(doc, field.Key, field.Value)
+					continue
+				}
+				sum, err := addNumbers(current, field.Value)
+				if err != nil {
+					return nil, err
+				}
+				doc = setPath(doc, field.Key, sum)
+			case "$push", "$addToSet":
+				list, _ := current.(bson.A)
+				list = append(bson.A(nil), list...)
+				for _, item := range eachValues(field.Value) {
+					if op.Key == "$addToSet" && matchEqual(list, true, item) {
+						continue
+					}
+					list = append(list, item)
+				}
+				doc = setPath(doc, field.Key, list)
+			case "$pull":
+				list, isList := current.(bson.A)
+				if !isList {
+					continue
+				}
+				kept := bson.A{}
+				for _, item := range list {
+					pull, err := matchCondition(item, true, field.Value)
+					if err != nil {
+						return nil, err
+					}
+					if !pull {
+						kept = append(kept, item)
+					}
+				}
+				doc = setPath(doc, field.Key, kept)
+			default:
+				return nil, fmt.Errorf("memory repository: unsupported update operator %s", op.Key)
+			}
+		}
+	}
+	return doc, nil
+}
+
+// eachValues unpacks a $push/$addToSet value - a {$each: [...]} document or a single item.
+func eachValues(value any) bson.A {
+	if d, ok := value.(bson.D); ok {
+		if each, found := lookupPath(d, "$each"); found {
+			if list, ok := each.(bson.A); ok {
+				return list
+			}
+		}
+	}
+	return bson.A{value}
+}
+
+func addNumbers(a, b any) (any, error) {
+	x, okA := toFloat(a)
+	y, okB := toFloat(b)
+	if !okA || !okB {
+		return nil, fmt.Errorf("memory repository: $inc on a non-numeric value")
+	}
+	_, floatA := a.(float64)
+	_, floatB := b.(float64)
+	_, longA := a.(int64)
+	_, longB := b.(int64)
+	switch {
+	case floatA || floatB:
+		return x + y, nil
+	case longA || longB:
+		return int64(x + y), nil
+	}
+	return int32(x + y), nil
+}
+
+// project applies an include (field: 1) or exclude (field: 0) projection to doc's top-level
+// fields. _id is kept unless excluded explicitly.
+func project(doc bson.D, projection bson.D) bson.D {
+	if len(projection) == 0 {
+		return doc
+	}
+	include := false
+	listed := map[string]bool{}
+	idExcluded := false
+	for _, p := range projection {
+		field, _, _ := strings.Cut(p.Key, ".")
+		on := truthy(p.Value)
+		if field == "_id" {
+			idExcluded = !on
+			continue
+		}
+		listed[field] = true
+		include = include || on
+	}
+
+	out := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		switch {
+		case e.Key == "_id":
+			if !idExcluded {
+				out = append(out, e)
+			}
+		case include == listed[e.Key]:
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func truthy(v any) bool {
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	f, ok := toFloat(v)
+	return ok && f != 0
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// typeRank orders bson types the way Mongo's sort does, for comparing values of different types.
+func typeRank(v any) int {
+	switch v.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 0
+	case int, int32, int64, float64:
+		return 1
+	case string, primitive.Symbol:
+		return 2
+	case bson.D:
+		return 3
+	case bson.A:
+		return 4
+	case primitive.Binary:
+		return 5
+	case primitive.ObjectID:
+		return 6
+	case bool:
+		return 7
+	case primitive.DateTime:
+		return 8
+	case primitive.Timestamp:
+		return 9
+	}
+	return 10
+}
+
+// compareValues orders two normalized bson values: negative, zero or positive as a sorts
+// before, with or after b.
+func compareValues(a, b any) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch x := a.(type) {
+	case int, int32, int64, float64:
+		fa, _ := toFloat(x)
+		fb, _ := toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case primitive.DateTime:
+		y := b.(primitive.DateTime)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case primitive.ObjectID:
+		y := b.(primitive.ObjectID)
+		return bytes.Compare(x[:], y[:])
+	case bson.A:
+		y := b.(bson.A)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if cmp := compareValues(x[i], y[i]); cmp != 0 {
+				return cmp
+			}
+		}
+		return len(x) - len(y)
+	case nil:
+		return 0
+	}
+	ma, errA := bson.Marshal(bson.D{{Key: "v", Value: a}})
+	mb, errB := bson.Marshal(bson.D{{Key: "v", Value: b}})
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return bytes.Compare(ma, mb)
+}
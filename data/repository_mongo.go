@@ -0,0 +1,124 @@
+package data
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sweetrpg/mongodb.go/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoRepository is the Repository backed by mongodb.go's shared database.Db connection
+// (opened by database.SetupDatabase). Every operation is issued with its context, so inside
+// WithTransaction it joins the transaction's session.
+type MongoRepository struct{}
+
+func (MongoRepository) Find(c context.Context, collection string, filter, sort, projection bson.D, start, limit int) ([]bson.Raw, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	opts := options.Find()
+	if len(sort) > 0 {
+		opts.SetSort(sort)
+	}
+	if len(projection) > 0 {
+		opts.SetProjection(projection)
+	}
+	if start > 0 {
+		opts.SetSkip(int64(start))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := database.Db.Collection(collection).Find(c, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	docs := make([]bson.Raw, 0)
+	for cursor.Next(c) {
+		docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+	}
+	return docs, cursor.Err()
+}
+
+func (MongoRepository) Count(c context.Context, collection string, filter bson.D) (int64, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	return database.Db.Collection(collection).CountDocuments(c, filter)
+}
+
+func (MongoRepository) Insert(c context.Context, collection string, document any) error {
+	_, err := database.Db.Collection(collection).InsertOne(c, document)
+	return err
+}
+
+func (MongoRepository) UpdateOne(c context.Context, collection string, filter, update bson.D) (int64, error) {
+	result, err := database.Db.Collection(collection).UpdateOne(c, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+func (MongoRepository) UpdateMany(c context.Context, collection string, filter, update bson.D) (int64, error) {
+	result, err := database.Db.Collection(collection).UpdateMany(c, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+func (MongoRepository) FindOneAndUpdate(c context.Context, collection string, filter, update bson.D) (bson.Raw, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	doc, err := database.Db.Collection(collection).FindOneAndUpdate(c, filter, update, opts).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return doc, err
+}
+
+func (MongoRepository) DeleteOne(c context.Context, collection string, filter bson.D) (int64, error) {
+	result, err := database.Db.Collection(collection).DeleteOne(c, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (MongoRepository) DeleteMany(c context.Context, collection string, filter bson.D) (int64, error) {
+	result, err := database.Db.Collection(collection).DeleteMany(c, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (MongoRepository) EnsureIndex(c context.Context, collection string, keys bson.D, unique bool) error {
+	model := mongo.IndexModel{Keys: keys}
+	if unique {
+		model.Options = options.Index().SetUnique(true)
+	}
+	_, err := database.Db.Collection(collection).Indexes().CreateOne(c, model)
+	return err
+}
+
+// WithTransaction runs fn in a Mongo session transaction. fn may be called more than once (the
+// driver retries transient transaction errors), so it must not accumulate state across calls.
+func (MongoRepository) WithTransaction(c context.Context, fn func(tc context.Context) error) error {
+	session, err := database.Db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(c)
+
+	_, err = session.WithTransaction(c, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
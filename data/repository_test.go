@@ -0,0 +1,129 @@
+package data
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/sweetrpg/common.go/logging"
+	"github.com/sweetrpg/mongodb.go/constants"
+	"github.com/sweetrpg/mongodb.go/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// setupTestStorage points Storage at the MongoDB named by TEST_DB_URI (see scripts/test-atlas.sh
+// and test-local.sh), or at a fresh MemoryRepository when it's unset, so every suite runs with
+// or without a database.
+func setupTestStorage() {
+	logging.Init()
+	uri := os.Getenv("TEST_DB_URI")
+	if uri == "" {
+		Storage = NewMemoryRepository()
+		return
+	}
+	_ = os.Setenv(constants.DB_URI, uri)
+	database.SetupDatabase()
+	Storage = MongoRepository{}
+}
+
+// MemoryRepositoryTestSuite pins MemoryRepository's query and update semantics to Mongo's for
+// the operators this package relies on - always in memory, whatever TEST_DB_URI says.
+type MemoryRepositoryTestSuite struct {
+	suite.Suite
+	repo *MemoryRepository
+}
+
+type memoryTestDoc struct {
+	ID      string   `bson:"_id"`
+	Name    string   `bson:"name"`
+	Rank    int      `bson:"rank"`
+	Tags    []string `bson:"tags"`
+	Deleted *bool    `bson:"deleted,omitempty"`
+}
+
+func (suite *MemoryRepositoryTestSuite) SetupTest() {
+	suite.repo = NewMemoryRepository()
+	ctx := suite.T().Context()
+	deleted := true
+	for _, doc := range []memoryTestDoc{
+		{ID: "a", Name: "Alpha", Rank: 3, Tags: []string{"red", "blue"}},
+		{ID: "b", Name: "Bravo", Rank: 1, Tags: []string{"blue"}},
+		{ID: "c", Name: "Charlie", Rank: 2, Tags: []string{}, Deleted: &deleted},
+	} {
+		assert.NoError(suite.T(), suite.repo.Insert(ctx, "docs", doc))
+	}
+}
+
+func (suite *MemoryRepositoryTestSuite) ids(filter, sort bson.D, start, limit int) []string {
+	docs, err := suite.repo.Find(suite.T().Context(), "docs", filter, sort, nil, start, limit)
+	assert.NoError(suite.T(), err)
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.Lookup("_id").StringValue())
+	}
+	return ids
+}
+
+func (suite *MemoryRepositoryTestSuite) TestFindFilters() {
+	byRank := bson.D{{Key: "rank", Value: 1}}
+	assert.Equal(suite.T(), []string{"b", "c", "a"}, suite.ids(nil, byRank, 0, 0))
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.ids(bson.D{{Key: "tags", Value: "blue"}}, nil, 0, 0))
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.ids(bson.D{{Key: "deleted", Value: nil}}, nil, 0, 0))
+	assert.Equal(suite.T(), []string{"c"}, suite.ids(bson.D{{Key: "deleted", Value: bson.D{{Key: "$exists", Value: true}}}}, nil, 0, 0))
+	assert.Equal(suite.T(), []string{"a", "c"}, suite.ids(bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: []string{"a", "c", "z"}}}}}, nil, 0, 0))
+	assert.Equal(suite.T(), []string{"a", "c"}, suite.ids(bson.D{{Key: "rank", Value: bson.D{{Key: "$gte", Value: 2}}}}, nil, 0, 0))
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.ids(bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "name", Value: "Alpha"}},
+		bson.D{{Key: "rank", Value: 1}},
+	}}}, nil, 0, 0))
+	assert.Equal(suite.T(), []string{"c", "a"}, suite.ids(nil, byRank, 1, 2))
+	assert.Equal(suite.T(), []string{"a", "c", "b"}, suite.ids(nil, bson.D{{Key: "rank", Value: -1}}, 0, 0))
+}
+
+func (suite *MemoryRepositoryTestSuite) TestUpdates() {
+	ctx := suite.T().Context()
+
+	matched, err := suite.repo.UpdateMany(ctx, "docs", bson.D{{Key: "tags", Value: "blue"}},
+		bson.D{{Key: "$pull", Value: bson.D{{Key: "tags", Value: "blue"}}}, {Key: "$inc", Value: bson.D{{Key: "rank", Value: 10}}}})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), matched)
+	assert.Empty(suite.T(), suite.ids(bson.D{{Key: "tags", Value: "blue"}}, nil, 0, 0))
+	assert.Equal(suite.T(), []string{"a", "b"}, suite.ids(bson.D{{Key: "rank", Value: bson.D{{Key: "$gt", Value: 10}}}}, nil, 0, 0))
+
+	doc, err := suite.repo.FindOneAndUpdate(ctx, "docs", bson.D{{Key: "_id", Value: "c"}}, bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Carol"}}}})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Carol", doc.Lookup("name").StringValue())
+
+	doc, err = suite.repo.FindOneAndUpdate(ctx, "docs", bson.D{{Key: "_id", Value: "z"}}, bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Zed"}}}})
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), doc)
+}
+
+func (suite *MemoryRepositoryTestSuite) TestUniqueIndexRejectsDuplicates() {
+	ctx := suite.T().Context()
+	assert.NoError(suite.T(), suite.repo.EnsureIndex(ctx, "docs", bson.D{{Key: "name", Value: 1}}, true))
+
+	err := suite.repo.Insert(ctx, "docs", memoryTestDoc{ID: "d", Name: "Alpha"})
+	assert.True(suite.T(), mongo.IsDuplicateKeyError(err))
+	err = suite.repo.Insert(ctx, "docs", memoryTestDoc{ID: "a", Name: "Another"})
+	assert.True(suite.T(), mongo.IsDuplicateKeyError(err))
+}
+
+func (suite *MemoryRepositoryTestSuite) TestTransactionRollsBackOnError() {
+	ctx := suite.T().Context()
+	err := suite.repo.WithTransaction(ctx, func(tc context.Context) error {
+		assert.NoError(suite.T(), suite.repo.Insert(tc, "docs", memoryTestDoc{ID: "d", Name: "Delta"}))
+		_, err := suite.repo.DeleteOne(tc, "docs", bson.D{{Key: "_id", Value: "a"}})
+		assert.NoError(suite.T(), err)
+		return ErrConflict
+	})
+	assert.ErrorIs(suite.T(), err, ErrConflict)
+	assert.Equal(suite.T(), []string{"a", "b", "c"}, suite.ids(nil, nil, 0, 0))
+}
+
+func TestMemoryRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryRepositoryTestSuite))
+}
@@ -3,24 +3,60 @@ package data
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sweetrpg/api-core.go/tracing"
 	apiutil "github.com/sweetrpg/api-core.go/util"
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
 	"github.com/sweetrpg/common.go/logging"
+	modelcore "github.com/sweetrpg/model-core.go/models"
 	modelcoreutil "github.com/sweetrpg/model-core.go/util"
 	modelcorevo "github.com/sweetrpg/model-core.go/vo"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-func GetReview(c context.Context, id string) (*vo.ReviewVO, error) {
+// reviewCollection holds reviews. A review's author is its created_by.
+const reviewCollection = "reviews"
+
+// reviewDocument is the stored form of a review: models.Review plus its rating, which the
+// catalog-objects review types don't carry.
+type reviewDocument struct {
+	models.Review `bson:",inline"`
+	Rating        int `bson:"rating,omitempty"`
+}
+
+// RatedReviewVO is a review as this package returns it: vo.ReviewVO plus its rating, from
+// MinRating to MaxRating or 0 if unrated.
+type RatedReviewVO struct {
+	vo.ReviewVO
+	Rating int `json:"rating,omitempty"`
+}
+
+// EnsureReviewIndexes creates the indexes reviews rely on: a unique (volume_id, created_by) index
+// backing the one-review-per-author-per-volume rule, and a created_by index for
+// QueryReviewsByAuthor. Safe to call on every startup.
+func EnsureReviewIndexes(c context.Context) error {
+	unique := bson.D{{Key: "volume_id", Value: 1}, {Key: "created_by", Value: 1}}
+	if err := Storage.EnsureIndex(c, reviewCollection, unique, true); err != nil {
+		return fmt.Errorf("review: create volume_id+created_by index: %w", err)
+	}
+	if err := Storage.EnsureIndex(c, reviewCollection, bson.D{{Key: "created_by", Value: 1}}, false); err != nil {
+		return fmt.Errorf("review: create created_by index: %w", err)
+	}
+	return nil
+}
+
+// GetReview returns a review by ID. A soft-deleted review is a *NotFoundError.
+func GetReview(c context.Context, id string) (*RatedReviewVO, error) {
 	_, span := otel.Tracer("review").Start(c, "db-get-review", oteltrace.WithAttributes(attribute.String("id", id)))
-	results, err := database.Query[models.Review]("reviews", bson.D{{Key: "_id", Value: id}}, nil, nil, 0, 1)
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "deleted_at", Value: nil}}
+	results, err := storeQuery[reviewDocument](c, reviewCollection, filter, nil, nil, 0, 1)
 	span.End()
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for Review: %v", err))
@@ -29,18 +65,33 @@ func GetReview(c context.Context, id string) (*vo.ReviewVO, error) {
 
 	if len(results) == 0 {
 		logging.Logger.Info(fmt.Sprintf("Review not found for ID: %s", id))
-		return nil, nil
+		return nil, &NotFoundError{Type: "review", ID: id}
 	}
 
-	return reviewModelToVO(c, results[0]), nil
+	return reviewModelsToVOs(c, results)[0], nil
 }
 
-func reviewModelToVO(c context.Context, model *models.Review) *vo.ReviewVO {
-	volumeVO, err := GetVolume(c, model.VolumeId)
-	if err != nil {
-		logging.Logger.Error(fmt.Sprintf("No Volume found from Review for ID %s: %s", model.VolumeId, err.Error()))
+// reviewModelsToVOs maps reviews to VOs, resolving every row's volume in one batch (see
+// loadVolumes) rather than a GetVolume per row.
+func reviewModelsToVOs(c context.Context, results []*reviewDocument) []*RatedReviewVO {
+	volumeIDs := make([]string, 0, len(results))
+	for _, result := range results {
+		volumeIDs = append(volumeIDs, result.VolumeId)
 	}
+	volumes := loadVolumes(c, volumeIDs)
 
+	vos := make([]*RatedReviewVO, 0, len(results))
+	for _, result := range results {
+		volumeVO, ok := volumes[result.VolumeId]
+		if !ok {
+			logging.Logger.Error(fmt.Sprintf("No Volume found from Review for ID %s", result.VolumeId))
+		}
+		vos = append(vos, &RatedReviewVO{ReviewVO: *reviewModelToVO(&result.Review, volumeVO), Rating: result.Rating})
+	}
+	return vos
+}
+
+func reviewModelToVO(model *models.Review, volumeVO *vo.VolumeVO) *vo.ReviewVO {
 	return &vo.ReviewVO{
 		ID:       model.ID,
 		Title:    model.Title,
@@ -59,20 +110,222 @@ func reviewModelToVO(c context.Context, model *models.Review) *vo.ReviewVO {
 	}
 }
 
-func QueryReviews(c context.Context, params apiutil.QueryParams) ([]*vo.ReviewVO, error) {
-	span := tracing.BuildSpanWithParams(c, "contributions", "db-get-contributions", params)
+// QueryReviews returns the reviews matching params, skipping soft-deleted ones.
+func QueryReviews(c context.Context, params apiutil.QueryParams) ([]*RatedReviewVO, error) {
+	span := tracing.BuildSpanWithParams(c, "reviews", "db-get-reviews", params)
 	filter, sort, projection := apiutil.ConvertQueryParams(params)
-	models, err := database.Query[models.Review]("reviews", filter, sort, projection, params.Start, params.Limit)
+	filter = append(filter, bson.E{Key: "deleted_at", Value: nil})
+	models, err := storeQuery[reviewDocument](c, reviewCollection, filter, sort, projection, params.Start, params.Limit)
 	span.End()
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for Reviews: %v", err))
 		return nil, err
 	}
 
-	vos := make([]*vo.ReviewVO, 0, len(models))
-	for _, model := range models {
-		vos = append(vos, reviewModelToVO(c, model))
+	return reviewModelsToVOs(c, models), nil
+}
+
+// QueryReviewsByVolume returns volumeID's reviews, paged via params and newest first unless
+// params sorts otherwise. A non-empty language keeps only reviews written in it. Soft-deleted
+// reviews are skipped.
+func QueryReviewsByVolume(c context.Context, volumeID, language string, params apiutil.QueryParams) ([]*RatedReviewVO, error) {
+	span := tracing.BuildSpanWithParams(c, "reviews", "db-query-reviews-by-volume", params)
+	defer span.End()
+	return queryReviewsBy(c, bson.E{Key: "volume_id", Value: volumeID}, language, params)
+}
+
+// QueryReviewsByAuthor is QueryReviewsByVolume for the reviews written by author (their
+// created_by).
+func QueryReviewsByAuthor(c context.Context, author, language string, params apiutil.QueryParams) ([]*RatedReviewVO, error) {
+	span := tracing.BuildSpanWithParams(c, "reviews", "db-query-reviews-by-author", params)
+	defer span.End()
+	return queryReviewsBy(c, bson.E{Key: "created_by", Value: author}, language, params)
+}
+
+// queryReviewsBy is the body of the QueryReviewsBy* functions, key being the field they list by.
+func queryReviewsBy(c context.Context, key bson.E, language string, params apiutil.QueryParams) ([]*RatedReviewVO, error) {
+	filter, sort, projection := apiutil.ConvertQueryParams(params)
+	filter = append(filter, key, bson.E{Key: "deleted_at", Value: nil})
+	if language != "" {
+		filter = append(filter, bson.E{Key: "language", Value: language})
+	}
+	if len(sort) == 0 {
+		sort = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	}
+	results, err := storeQuery[reviewDocument](c, reviewCollection, filter, sort, projection, params.Start, params.Limit)
+	if err != nil {
+		logging.Logger.Error("Error while querying database for Reviews", "filter", key.Key, "error", err)
+		return nil, err
+	}
+	return reviewModelsToVOs(c, results), nil
+}
+
+// AddReview writes review.CreatedBy's review of volumeID and returns its ID. An author gets one
+// review per volume: if they already have a live one this is a *DuplicateError naming it, and if
+// they soft-deleted theirs, that review is brought back with the new text instead (keeping its
+// ID). rating is 0 for an unrated review, otherwise MinRating..MaxRating (else a *RatingError),
+// and counts toward the volume's rating aggregates (see GetVolumeRatingSummary). A missing or
+// soft-deleted volume is a *NotFoundError. The write, the aggregate update and the outbox event
+// share one transaction.
+func AddReview(c context.Context, volumeID string, review *vo.ReviewVO, rating int) (*string, error) {
+	_, span := otel.Tracer("review").Start(c, "db-add-review", oteltrace.WithAttributes(
+		attribute.String("volumeId", volumeID), attribute.String("createdBy", review.CreatedBy)))
+	defer span.End()
+
+	if review.CreatedBy == "" {
+		return nil, fmt.Errorf("add review: no author (CreatedBy) given")
+	}
+	if err := checkRating(rating); err != nil {
+		return nil, err
+	}
+	var id string
+	err := withTransaction(c, func(tc context.Context) error {
+		volume, err := volumeVersioning.requireMeta(tc, volumeID)
+		if err != nil {
+			return err
+		}
+		if volume.DeletedAt != nil {
+			return &NotFoundError{Type: "volume", ID: volumeID}
+		}
+
+		filter := bson.D{{Key: "volume_id", Value: volumeID}, {Key: "created_by", Value: review.CreatedBy}}
+		existing, err := storeQuery[reviewDocument](tc, reviewCollection, filter, nil, nil, 0, 1)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if len(existing) > 0 {
+			id = existing[0].ID
+			if existing[0].DeletedAt == nil {
+				return &DuplicateError{Type: "review", ExistingID: id,
+					Reason: fmt.Sprintf("%s already reviewed volume %s (review %s)", review.CreatedBy, volumeID, id)}
+			}
+			_, err := Storage.UpdateOne(tc, reviewCollection, bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
+				{Key: "title", Value: review.Title},
+				{Key: "body", Value: review.Body},
+				{Key: "language", Value: review.Language},
+				{Key: "tags", Value: modelcoreutil.ToTagModels(review.Tags)},
+				{Key: ratingField, Value: rating},
+				{Key: "created_at", Value: now},
+				{Key: "updated_at", Value: now},
+				{Key: "updated_by", Value: review.CreatedBy},
+				{Key: "deleted_at", Value: nil},
+				{Key: "deleted_by", Value: nil},
+			}}})
+			if err != nil {
+				return err
+			}
+		} else {
+			id = primitive.NewObjectID().Hex()
+			model := reviewDocument{
+				Review: models.Review{
+					ID:       id,
+					VolumeId: volumeID,
+					Title:    review.Title,
+					Body:     review.Body,
+					Language: review.Language,
+					Tags:     modelcoreutil.ToTagModels(review.Tags),
+					Auditable: modelcore.Auditable{
+						CreatedAt: now,
+						CreatedBy: review.CreatedBy,
+						UpdatedAt: now,
+						UpdatedBy: review.CreatedBy,
+					},
+				},
+				Rating: rating,
+			}
+			if err := storeInsert(tc, reviewCollection, model); err != nil {
+				if mongo.IsDuplicateKeyError(err) {
+					// Lost a race with a concurrent AddReview by the same author.
+					return &DuplicateError{Type: "review",
+						Reason: fmt.Sprintf("%s already reviewed volume %s", review.CreatedBy, volumeID)}
+				}
+				return err
+			}
+		}
+		// A deleted review being brought back counted for nothing, same as a new one.
+		if err := applyRatingChange(tc, volumeID, 0, rating); err != nil {
+			return err
+		}
+		return appendEvent(tc, Event{Type: EventReviewAdded, Entity: "review", RecordID: id, Actor: review.CreatedBy})
+	})
+	if err != nil {
+		logging.Logger.Info("Review not added", "volumeId", volumeID, "error", err)
+		return nil, err
 	}
+	return &id, nil
+}
 
-	return vos, nil
+// UpdateReview replaces a review's title, body, language, tags and rating in place (rating as
+// for AddReview), stamping review.UpdatedBy, and returns the updated review. A missing or
+// soft-deleted review is a *NotFoundError.
+func UpdateReview(c context.Context, id string, review *vo.ReviewVO, rating int) (*RatedReviewVO, error) {
+	_, span := otel.Tracer("review").Start(c, "db-update-review", oteltrace.WithAttributes(attribute.String("id", id)))
+	defer span.End()
+
+	if err := checkRating(rating); err != nil {
+		return nil, err
+	}
+	err := withTransaction(c, func(tc context.Context) error {
+		filter := bson.D{{Key: "_id", Value: id}, {Key: "deleted_at", Value: nil}}
+		existing, err := storeQuery[reviewDocument](tc, reviewCollection, filter, nil, nil, 0, 1)
+		if err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return &NotFoundError{Type: "review", ID: id}
+		}
+		_, err = Storage.UpdateOne(tc, reviewCollection, filter, bson.D{{Key: "$set", Value: bson.D{
+			{Key: "title", Value: review.Title},
+			{Key: "body", Value: review.Body},
+			{Key: "language", Value: review.Language},
+			{Key: "tags", Value: modelcoreutil.ToTagModels(review.Tags)},
+			{Key: ratingField, Value: rating},
+			{Key: "updated_at", Value: time.Now()},
+			{Key: "updated_by", Value: review.UpdatedBy},
+		}}})
+		if err != nil {
+			return err
+		}
+		if err := applyRatingChange(tc, existing[0].VolumeId, existing[0].Rating, rating); err != nil {
+			return err
+		}
+		return appendEvent(tc, Event{Type: EventReviewUpdated, Entity: "review", RecordID: id, Actor: review.UpdatedBy})
+	})
+	if err != nil {
+		logging.Logger.Info("Review not updated", "id", id, "error", err)
+		return nil, err
+	}
+	return GetReview(c, id)
+}
+
+// DeleteReview soft-deletes a review, stamping deleted_at/deleted_by and taking its rating out
+// of the volume's aggregates. Returns false if there was no live review with that ID.
+func DeleteReview(c context.Context, id, deletedBy string) (bool, error) {
+	_, span := otel.Tracer("review").Start(c, "db-delete-review", oteltrace.WithAttributes(attribute.String("id", id)))
+	defer span.End()
+
+	deleted := false
+	err := withTransaction(c, func(tc context.Context) error {
+		raw, err := Storage.FindOneAndUpdate(tc, reviewCollection,
+			bson.D{{Key: "_id", Value: id}, {Key: "deleted_at", Value: nil}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: time.Now()}, {Key: "deleted_by", Value: deletedBy}}}})
+		if err != nil || raw == nil {
+			return err
+		}
+		deleted = true
+		var review reviewDocument
+		if err := bson.Unmarshal(raw, &review); err != nil {
+			return err
+		}
+		if err := applyRatingChange(tc, review.VolumeId, review.Rating, 0); err != nil {
+			return err
+		}
+		return appendEvent(tc, Event{Type: EventReviewDeleted, Entity: "review", RecordID: id, Actor: deletedBy})
+	})
+	if err != nil {
+		logging.Logger.Error("Error while deleting Review", "error", err)
+		return false, err
+	}
+	return deleted, nil
 }
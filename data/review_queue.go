@@ -0,0 +1,212 @@
+package data
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// PendingSubmission is one submitted version waiting in the review queue.
+type PendingSubmission struct {
+	Type        string // entity type, e.g. "publisher"
+	RecordID    string
+	Version     int
+	DisplayName string // the record's current name; empty for contributions, which have none
+	SubmittedBy string
+	SubmittedAt time.Time
+	BaseVersion int
+	// CurrentVersion is the record's current version today. Drifted is set when it's no longer
+	// BaseVersion - the record has moved on since the submitter started editing, so accepting may
+	// conflict (see Diff*Submission).
+	CurrentVersion int
+	Drifted        bool
+	// ChangedFields are the fields the submission changes relative to BaseVersion, sorted.
+	ChangedFields []string
+	// ClaimedBy is the reviewer holding an unexpired claim on the version (see ClaimVersion),
+	// empty if none; ClaimExpiresAt is when that claim lapses.
+	ClaimedBy      string
+	ClaimExpiresAt *time.Time
+}
+
+// PendingSubmissionSort orders ListPendingSubmissions' feed. Every order falls back to oldest
+// first, so the feed is stable across pages.
+type PendingSubmissionSort string
+
+const (
+	SortPendingOldestFirst PendingSubmissionSort = "oldest"
+	SortPendingByType      PendingSubmissionSort = "type"
+	SortPendingBySubmitter PendingSubmissionSort = "submitter"
+)
+
+// reviewQueueSource is what the review queue, claims, approvals and the stale-submission sweep
+// need from one type's versioning engine.
+type reviewQueueSource interface {
+	pendingSubmissions(c context.Context, filter bson.D) ([]*PendingSubmission, error)
+	describePending(c context.Context, items []*PendingSubmission) error
+	claimVersion(c context.Context, id string, version int, reviewer string, duration time.Duration) (*VersionClaim, error)
+	releaseClaim(c context.Context, id string, version int, reviewer string) error
+	approveVersion(c context.Context, id string, version int, reviewer string, roles []string, note *string) (*ApprovalStatus, error)
+	approvalStatus(c context.Context, id string, version int) (*ApprovalStatus, error)
+	staleSubmissions(c context.Context, policy StalenessPolicy, now time.Time) ([]*ExpiredSubmission, int, error)
+	expireSubmission(c context.Context, id string, version int, note string) (bool, error)
+}
+
+// reviewQueueSources are the types whose submissions the review queue lists and claims and
+// approvals cover, by entity name.
+var reviewQueueSources = map[string]reviewQueueSource{
+	"volume":       volumeVersioning,
+	"publisher":    publisherVersioning,
+	"studio":       studioVersioning,
+	"person":       personVersioning,
+	"license":      licenseVersioning,
+	"contribution": contributionVersioning,
+}
+
+// pendingSubmissions lists cfg's submitted versions also matching filter as queue items, filling
+// in everything but DisplayName and ChangedFields (see describePending). Submissions against a
+// soft-deleted record are left out, since they can't be acted on until it's restored.
+func (cfg entityVersioningConfig[T]) pendingSubmissions(c context.Context, filter bson.D) ([]*PendingSubmission, error) {
+	filter = append(bson.D{{Key: "state", Value: string(models.VersionStateSubmitted)}}, filter...)
+	submitted, err := storeQuery[pendingVersionDocument](c, cfg.versionCollection, filter, nil, nil, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query submitted versions: %w", cfg.typeName, err)
+	}
+	ids := make([]string, 0, len(submitted))
+	for _, version := range submitted {
+		ids = append(ids, version.RecordID)
+	}
+	metas, err := cfg.getMetas(c, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := make([]*PendingSubmission, 0, len(submitted))
+	for _, version := range submitted {
+		meta, ok := metas[version.RecordID]
+		if !ok || meta.DeletedAt != nil {
+			continue
+		}
+		item := &PendingSubmission{
+			Type: cfg.typeName, RecordID: version.RecordID, Version: version.Version,
+			SubmittedBy: version.SubmittedBy, SubmittedAt: version.SubmittedAt, CurrentVersion: meta.CurrentVersion,
+		}
+		if version.BaseVersion != nil {
+			item.BaseVersion = *version.BaseVersion
+		}
+		item.Drifted = item.BaseVersion != item.CurrentVersion
+		if item.ClaimedBy = version.claimant(now); item.ClaimedBy != "" {
+			item.ClaimExpiresAt = version.ClaimExpiresAt
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// describePending fills in DisplayName and ChangedFields for items, all of cfg's type - only
+// once the feed is paged, since it reads the submitted, base and current versions of each.
+func (cfg entityVersioningConfig[T]) describePending(c context.Context, items []*PendingSubmission) error {
+	wanted := make(bson.A, 0, 3*len(items))
+	for _, item := range items {
+		for _, version := range []int{item.Version, item.BaseVersion, item.CurrentVersion} {
+			wanted = append(wanted, bson.D{{Key: "record_id", Value: item.RecordID}, {Key: "version", Value: version}})
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+	results, err := storeQuery[T](c, cfg.versionCollection, bson.D{{Key: "$or", Value: wanted}}, nil, nil, 0, 0)
+	if err != nil {
+		return fmt.Errorf("%s: query versions under review: %w", cfg.typeName, err)
+	}
+	versions := make(map[string]*T, len(results))
+	for _, version := range results {
+		versions[fmt.Sprintf("%s/%d", cfg.recordID(version), cfg.version(version))] = version
+	}
+
+	for _, item := range items {
+		submitted := versions[fmt.Sprintf("%s/%d", item.RecordID, item.Version)]
+		if current, ok := versions[fmt.Sprintf("%s/%d", item.RecordID, item.CurrentVersion)]; ok {
+			item.DisplayName = cfg.displayName(current)
+		}
+		if base, ok := versions[fmt.Sprintf("%s/%d", item.RecordID, item.BaseVersion)]; ok && submitted != nil {
+			item.ChangedFields = cfg.changedFields(submitted, base)
+		}
+		if item.ChangedFields == nil {
+			item.ChangedFields = []string{}
+		}
+	}
+	return nil
+}
+
+// ListPendingSubmissions is the moderators' review queue: every submitted version across all
+// versioned types, claimed or not, ordered by sortBy and paged by start/limit (a limit of 0 means
+// no limit).
+func ListPendingSubmissions(c context.Context, sortBy PendingSubmissionSort, start, limit int) ([]*PendingSubmission, error) {
+	_, span := otel.Tracer("review-queue").Start(c, "db-list-pending-submissions", oteltrace.WithAttributes(attribute.String("sort", string(sortBy))))
+	defer span.End()
+
+	items, err := listPending(c, nil, sortBy, start, limit)
+	if err != nil {
+		logging.Logger.Error("Error while querying database for pending submissions", "error", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// listPending gathers the submitted versions matching filter from every source, sorts and pages
+// them, and describes the page.
+func listPending(c context.Context, filter bson.D, sortBy PendingSubmissionSort, start, limit int) ([]*PendingSubmission, error) {
+	var items []*PendingSubmission
+	for _, source := range reviewQueueSources {
+		pending, err := source.pendingSubmissions(c, filter)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, pending...)
+	}
+
+	oldestFirst := func(a, b *PendingSubmission) int {
+		return cmp.Or(a.SubmittedAt.Compare(b.SubmittedAt), cmp.Compare(a.Type, b.Type),
+			cmp.Compare(a.RecordID, b.RecordID), cmp.Compare(a.Version, b.Version))
+	}
+	switch sortBy {
+	case SortPendingOldestFirst, "":
+		slices.SortFunc(items, oldestFirst)
+	case SortPendingByType:
+		slices.SortFunc(items, func(a, b *PendingSubmission) int { return cmp.Or(cmp.Compare(a.Type, b.Type), oldestFirst(a, b)) })
+	case SortPendingBySubmitter:
+		slices.SortFunc(items, func(a, b *PendingSubmission) int {
+			return cmp.Or(cmp.Compare(a.SubmittedBy, b.SubmittedBy), oldestFirst(a, b))
+		})
+	default:
+		return nil, &ValidationError{Type: "submission", Field: "sort", Reason: fmt.Sprintf("unknown sort %q", sortBy)}
+	}
+
+	start = min(max(start, 0), len(items))
+	end := len(items)
+	if limit > 0 {
+		end = min(start+limit, end)
+	}
+	page := items[start:end]
+
+	byType := map[string][]*PendingSubmission{}
+	for _, item := range page {
+		byType[item.Type] = append(byType[item.Type], item)
+	}
+	for entity, pending := range byType {
+		if err := reviewQueueSources[entity].describePending(c, pending); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
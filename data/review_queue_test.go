@@ -0,0 +1,452 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	modelcorevo "github.com/sweetrpg/model-core.go/vo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewQueueTestSuite covers ListPendingSubmissions. The queue spans every submission on the
+// database, so assertions only look at records this test created, and submitters carry a fresh
+// marker.
+type ReviewQueueTestSuite struct {
+	suite.Suite
+	marker string
+}
+
+func (suite *ReviewQueueTestSuite) SetupTest() {
+	setupTestStorage()
+	assert.NoError(suite.T(), EnsurePublisherVersioningIndexes(suite.T().Context()))
+	assert.NoError(suite.T(), EnsurePersonVersioningIndexes(suite.T().Context()))
+	suite.marker = primitive.NewObjectID().Hex()
+}
+
+// mine returns the queue's items for records in ids, in feed order.
+func (suite *ReviewQueueTestSuite) mine(sortBy PendingSubmissionSort, ids ...string) []*PendingSubmission {
+	items, err := ListPendingSubmissions(suite.T().Context(), sortBy, 0, 0)
+	assert.NoError(suite.T(), err)
+	var found []*PendingSubmission
+	for _, item := range items {
+		for _, id := range ids {
+			if item.RecordID == id {
+				found = append(found, item)
+			}
+		}
+	}
+	return found
+}
+
+func (suite *ReviewQueueTestSuite) TestListPendingSubmissionsAcrossTypes() {
+	ctx := suite.T().Context()
+	zed, ann := "zed-"+suite.marker, "ann-"+suite.marker
+
+	publisherID, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Queued Press"})
+	assert.NoError(suite.T(), err)
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Queued Person"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePerson(ctx, *personID, &vo.PersonVO{Name: "Queued Person Jr.",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: zed}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: "Queued Press Ltd",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: ann}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	// An editor's live change after the submission was made leaves the publisher's drifted.
+	_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: "Queued Press", Address: "1 Main St"},
+		models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	oldest := suite.mine(SortPendingOldestFirst, *publisherID, *personID)
+	if assert.Len(suite.T(), oldest, 2) {
+		person, publisher := oldest[0], oldest[1]
+		assert.Equal(suite.T(), "person", person.Type)
+		assert.Equal(suite.T(), zed, person.SubmittedBy)
+		assert.False(suite.T(), person.Drifted)
+		assert.Equal(suite.T(), []string{"name"}, person.ChangedFields)
+
+		assert.Equal(suite.T(), "publisher", publisher.Type)
+		assert.Equal(suite.T(), 2, publisher.Version)
+		assert.Equal(suite.T(), "Queued Press", publisher.DisplayName)
+		assert.Equal(suite.T(), 1, publisher.BaseVersion)
+		assert.Equal(suite.T(), 3, publisher.CurrentVersion)
+		assert.True(suite.T(), publisher.Drifted)
+		assert.Equal(suite.T(), []string{"name"}, publisher.ChangedFields)
+	}
+
+	bySubmitter := suite.mine(SortPendingBySubmitter, *publisherID, *personID)
+	if assert.Len(suite.T(), bySubmitter, 2) {
+		assert.Equal(suite.T(), "publisher", bySubmitter[0].Type)
+		assert.Equal(suite.T(), "person", bySubmitter[1].Type)
+	}
+
+	// Accepted submissions leave the queue.
+	_, _, err = AcceptPersonVersion(ctx, *personID, 2, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), suite.mine(SortPendingOldestFirst, *publisherID, *personID), 1)
+}
+
+func (suite *ReviewQueueTestSuite) TestListPendingSubmissionsPages() {
+	ctx := suite.T().Context()
+	all, err := ListPendingSubmissions(ctx, SortPendingOldestFirst, 0, 0)
+	assert.NoError(suite.T(), err)
+	if len(all) < 2 {
+		publisherID, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Paged Press"})
+		assert.NoError(suite.T(), err)
+		for _, name := range []string{"Paged Press I", "Paged Press II"} {
+			_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: name}, models.VersionStateSubmitted, nil)
+			assert.NoError(suite.T(), err)
+		}
+		all, err = ListPendingSubmissions(ctx, SortPendingOldestFirst, 0, 0)
+		assert.NoError(suite.T(), err)
+	}
+
+	page, err := ListPendingSubmissions(ctx, SortPendingOldestFirst, 1, 1)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), page, 1) {
+		assert.Equal(suite.T(), all[1].RecordID, page[0].RecordID)
+		assert.Equal(suite.T(), all[1].Version, page[0].Version)
+	}
+	past, err := ListPendingSubmissions(ctx, SortPendingOldestFirst, len(all), 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), past)
+
+	_, err = ListPendingSubmissions(ctx, "newest", 0, 0)
+	assert.ErrorIs(suite.T(), err, ErrValidation)
+}
+
+func (suite *ReviewQueueTestSuite) TestClaimedVersionIsReservedForItsReviewer() {
+	ctx := suite.T().Context()
+	alice, bob := "alice-"+suite.marker, "bob-"+suite.marker
+
+	publisherID, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Claimed Press"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: "Claimed Press Ltd"}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+
+	claim, err := ClaimVersion(ctx, "publisher", *publisherID, 2, alice, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), alice, claim.ClaimedBy)
+	assert.WithinDuration(suite.T(), time.Now().Add(DefaultClaimDuration), claim.ExpiresAt, time.Minute)
+
+	_, err = ClaimVersion(ctx, "publisher", *publisherID, 2, bob, 0)
+	assert.ErrorIs(suite.T(), err, ErrClaimed)
+	_, _, err = AcceptPublisherVersion(ctx, *publisherID, 2, nil, nil, bob, nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrClaimed)
+	assert.ErrorIs(suite.T(), RejectPublisherVersion(ctx, *publisherID, 2, bob, nil), ErrClaimed)
+	assert.ErrorIs(suite.T(), ReleaseClaim(ctx, "publisher", *publisherID, 2, bob), ErrClaimed)
+
+	claimed, err := ListClaimedSubmissions(ctx, alice)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), claimed, 1) {
+		assert.Equal(suite.T(), *publisherID, claimed[0].RecordID)
+		assert.Equal(suite.T(), alice, claimed[0].ClaimedBy)
+		assert.Equal(suite.T(), "Claimed Press", claimed[0].DisplayName)
+	}
+	bobsClaims, err := ListClaimedSubmissions(ctx, bob)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), bobsClaims)
+
+	assert.NoError(suite.T(), ReleaseClaim(ctx, "publisher", *publisherID, 2, alice))
+	_, _, err = AcceptPublisherVersion(ctx, *publisherID, 2, nil, nil, bob, nil, nil)
+	assert.NoError(suite.T(), err)
+	_, err = ClaimVersion(ctx, "publisher", *publisherID, 2, alice, 0)
+	assert.ErrorIs(suite.T(), err, ErrInvalidState)
+}
+
+func (suite *ReviewQueueTestSuite) TestExpiredClaimCanBeTakenOver() {
+	ctx := suite.T().Context()
+	alice, bob := "alice-"+suite.marker, "bob-"+suite.marker
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Claimed Person"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePerson(ctx, *personID, &vo.PersonVO{Name: "Claimed Person II"}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+
+	_, err = ClaimVersion(ctx, "person", *personID, 2, alice, time.Millisecond)
+	assert.NoError(suite.T(), err)
+	time.Sleep(5 * time.Millisecond)
+
+	claimed, err := ListClaimedSubmissions(ctx, alice)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), claimed)
+	_, err = ClaimVersion(ctx, "person", *personID, 2, bob, time.Hour)
+	assert.NoError(suite.T(), err)
+	_, _, err = AcceptPersonVersion(ctx, *personID, 2, nil, nil, alice, nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrClaimed)
+
+	_, err = ClaimVersion(ctx, "review", *personID, 2, bob, 0)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ReviewQueueTestSuite) TestApprovalPolicyNeedsDistinctReviewers() {
+	ctx := suite.T().Context()
+	defer func(policy ApprovalPolicy) { PublisherApprovalPolicy = policy }(PublisherApprovalPolicy)
+	PublisherApprovalPolicy = ApprovalPolicy{RequiredApprovals: 2, ForbidSelfApproval: true}
+	submitter, alice, bob := "sub-"+suite.marker, "alice-"+suite.marker, "bob-"+suite.marker
+
+	publisherID, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Approved Press"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: "Approved Press Ltd",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+
+	_, err = ApproveVersion(ctx, "publisher", *publisherID, 2, submitter, nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrApprovalRequired)
+	_, _, err = AcceptPublisherVersion(ctx, *publisherID, 2, nil, nil, submitter, nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrApprovalRequired)
+
+	status, err := ApproveVersion(ctx, "publisher", *publisherID, 2, alice, nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, status.Required)
+	assert.Len(suite.T(), status.Approvals, 1)
+	assert.False(suite.T(), status.Satisfied)
+	assert.NotEmpty(suite.T(), status.Outstanding)
+	// Alice's accept is the same reviewer as her approval, so still only one of two.
+	_, _, err = AcceptPublisherVersion(ctx, *publisherID, 2, nil, nil, alice, nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrApprovalRequired)
+	got, err := GetPublisher(ctx, *publisherID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Approved Press", got.Name)
+
+	_, _, err = AcceptPublisherVersion(ctx, *publisherID, 2, nil, nil, bob, nil, nil)
+	assert.NoError(suite.T(), err)
+	got, err = GetPublisher(ctx, *publisherID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Approved Press Ltd", got.Name)
+}
+
+func (suite *ReviewQueueTestSuite) TestApprovalPolicyElevatedFields() {
+	ctx := suite.T().Context()
+	defer func(policy ApprovalPolicy) { VolumeApprovalPolicy = policy }(VolumeApprovalPolicy)
+	VolumeApprovalPolicy = ApprovalPolicy{ElevatedFields: []string{"license_ids"}, ElevatedRole: "licensing"}
+	alice, bob := "alice-"+suite.marker, "bob-"+suite.marker
+
+	licenseID, err := AddLicense(ctx, &vo.LicenseVO{Title: "Queued License"})
+	assert.NoError(suite.T(), err)
+	volumeID, err := AddVolume(ctx, &vo.VolumeVO{Title: "Licensed Volume"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdateVolume(ctx, *volumeID, &vo.VolumeVO{Title: "Licensed Volume",
+		Licenses: []*vo.LicenseVO{{ID: *licenseID}}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+
+	_, err = ApproveVersion(ctx, "volume", *volumeID, 2, alice, []string{"moderator"}, nil)
+	assert.NoError(suite.T(), err)
+	_, _, err = AcceptVolumeVersion(ctx, *volumeID, 2, nil, nil, bob, nil, nil, nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrApprovalRequired)
+
+	status, err := ApproveVersion(ctx, "volume", *volumeID, 2, bob, []string{"licensing"}, nil)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), status.Satisfied)
+	_, _, err = AcceptVolumeVersion(ctx, *volumeID, 2, nil, nil, bob, nil, nil, nil, nil)
+	assert.NoError(suite.T(), err)
+
+	// A change that leaves license_ids alone needs no elevated sign-off.
+	_, err = UpdateVolume(ctx, *volumeID, &vo.VolumeVO{Title: "Licensed Volume, Revised",
+		Licenses: []*vo.LicenseVO{{ID: *licenseID}}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	_, _, err = AcceptVolumeVersion(ctx, *volumeID, 3, nil, nil, alice, nil, nil, nil, nil)
+	assert.NoError(suite.T(), err)
+}
+
+// expiredFor returns report's entries for record id.
+func expiredFor(report *SweepReport, id string) []*ExpiredSubmission {
+	var found []*ExpiredSubmission
+	for _, expired := range report.Expired {
+		if expired.RecordID == id {
+			found = append(found, expired)
+		}
+	}
+	return found
+}
+
+func (suite *ReviewQueueTestSuite) TestSweepExpiresDriftedSubmissions() {
+	ctx := suite.T().Context()
+	defer func(policy StalenessPolicy) { SubmissionStalenessPolicy = policy }(SubmissionStalenessPolicy)
+	SubmissionStalenessPolicy = StalenessPolicy{MaxDrift: 1}
+	submitter := "sub-" + suite.marker
+
+	publisherID, err := AddPublisher(ctx, &vo.PublisherVO{Name: "Drifting Press"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: "Drifting Press Ltd",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	for _, address := range []string{"1 Main St", "2 Main St"} {
+		_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: "Drifting Press", Address: address}, models.VersionStateLive, nil)
+		assert.NoError(suite.T(), err)
+	}
+	// Based on the current version, so not drifted at all.
+	_, err = UpdatePublisher(ctx, *publisherID, &vo.PublisherVO{Name: "Drifting Press", Address: "3 Main St"}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+
+	report, err := SweepStaleSubmissions(ctx)
+	assert.NoError(suite.T(), err)
+	mine := expiredFor(report, *publisherID)
+	if assert.Len(suite.T(), mine, 1) {
+		assert.Equal(suite.T(), 2, mine[0].Version)
+		assert.Equal(suite.T(), StaleDrifted, mine[0].Reason)
+		assert.Equal(suite.T(), submitter, mine[0].SubmittedBy)
+		assert.Equal(suite.T(), "Drifting Press", mine[0].DisplayName)
+	}
+
+	expired, err := GetPublisherVersion(ctx, *publisherID, 2)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), VersionStateExpired, models.VersionState(expired.State))
+	if assert.NotNil(suite.T(), expired.ReviewNote) {
+		assert.Contains(suite.T(), *expired.ReviewNote, "2 versions went live")
+	}
+	_, _, err = AcceptPublisherVersion(ctx, *publisherID, 2, nil, nil, "editor-1", nil, nil)
+	assert.ErrorIs(suite.T(), err, ErrInvalidState)
+
+	again, err := SweepStaleSubmissions(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), expiredFor(again, *publisherID))
+}
+
+func (suite *ReviewQueueTestSuite) TestSweepExpiresOldSubmissionsButNotClaimedOnes() {
+	ctx := suite.T().Context()
+	defer func(policy StalenessPolicy) { SubmissionStalenessPolicy = policy }(SubmissionStalenessPolicy)
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Aging Person"})
+	assert.NoError(suite.T(), err)
+	claimedID, err := AddPerson(ctx, &vo.PersonVO{Name: "Watched Person"})
+	assert.NoError(suite.T(), err)
+	for _, id := range []string{*personID, *claimedID} {
+		_, err = UpdatePerson(ctx, id, &vo.PersonVO{Name: "Renamed Person"}, models.VersionStateSubmitted, nil)
+		assert.NoError(suite.T(), err)
+	}
+	_, err = ClaimVersion(ctx, "person", *claimedID, 2, "alice-"+suite.marker, time.Hour)
+	assert.NoError(suite.T(), err)
+
+	SubmissionStalenessPolicy = StalenessPolicy{}
+	report, err := SweepStaleSubmissions(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), report.Expired)
+
+	time.Sleep(5 * time.Millisecond)
+	SubmissionStalenessPolicy = StalenessPolicy{MaxAge: time.Millisecond}
+	report, err = SweepStaleSubmissions(ctx)
+	assert.NoError(suite.T(), err)
+	assert.GreaterOrEqual(suite.T(), report.Checked, 2)
+	if mine := expiredFor(report, *personID); assert.Len(suite.T(), mine, 1) {
+		assert.Equal(suite.T(), StaleTooOld, mine[0].Reason)
+	}
+	assert.Empty(suite.T(), expiredFor(report, *claimedID))
+}
+
+func (suite *ReviewQueueTestSuite) TestSubmissionCapPendingLimits() {
+	ctx := suite.T().Context()
+	defer func(limits SubmissionCap) { PublisherSubmissionCap = limits }(PublisherSubmissionCap)
+	PublisherSubmissionCap = SubmissionCap{MaxPending: 2, MaxPendingPerRecord: 1}
+	submitter := "capped-" + suite.marker
+	submit := func(id, name string) error {
+		_, err := UpdatePublisher(ctx, id, &vo.PublisherVO{Name: name,
+			AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+		return err
+	}
+
+	var ids []string
+	for _, name := range []string{"Cap Press A", "Cap Press B", "Cap Press C"} {
+		id, err := AddPublisher(ctx, &vo.PublisherVO{Name: name})
+		assert.NoError(suite.T(), err)
+		ids = append(ids, *id)
+	}
+	assert.NoError(suite.T(), submit(ids[0], "Cap Press A2"))
+
+	var exceeded *CapExceededError
+	err := submit(ids[0], "Cap Press A3")
+	assert.ErrorIs(suite.T(), err, ErrCapExceeded)
+	if assert.ErrorAs(suite.T(), err, &exceeded) {
+		assert.Equal(suite.T(), CapPendingPerRecord, exceeded.Limit)
+		assert.Equal(suite.T(), 1, exceeded.Max)
+		assert.Equal(suite.T(), SubmissionCounts{Pending: 1, PendingOnRecord: 1}, exceeded.Counts)
+	}
+
+	assert.NoError(suite.T(), submit(ids[1], "Cap Press B2"))
+	err = submit(ids[2], "Cap Press C2")
+	if assert.ErrorAs(suite.T(), err, &exceeded) {
+		assert.Equal(suite.T(), CapPending, exceeded.Limit)
+		assert.Equal(suite.T(), 2, exceeded.Counts.Pending)
+	}
+	versions, err := ListPublisherVersions(ctx, ids[2])
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), versions, 1)
+
+	// A reviewed submission frees its slot; a live edit never takes one.
+	assert.NoError(suite.T(), RejectPublisherVersion(ctx, ids[0], 2, "editor-1", nil))
+	assert.NoError(suite.T(), submit(ids[2], "Cap Press C2"))
+	_, err = UpdatePublisher(ctx, ids[0], &vo.PublisherVO{Name: "Cap Press A4",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *ReviewQueueTestSuite) TestSubmissionCapWindow() {
+	ctx := suite.T().Context()
+	defer func(limits SubmissionCap) { PersonSubmissionCap = limits }(PersonSubmissionCap)
+	PersonSubmissionCap = SubmissionCap{MaxPerWindow: 2, Window: time.Hour}
+	submitter := "windowed-" + suite.marker
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Window Person", AuditableVO: modelcorevo.AuditableVO{CreatedBy: submitter}})
+	assert.NoError(suite.T(), err)
+	for _, name := range []string{"Window Person I", "Window Person II"} {
+		_, err = UpdatePerson(ctx, *personID, &vo.PersonVO{Name: name,
+			AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+		assert.NoError(suite.T(), err)
+	}
+	// Rejecting one doesn't give it back: the window counts submissions made, not pending.
+	assert.NoError(suite.T(), RejectPersonVersion(ctx, *personID, 2, "editor-1", nil))
+
+	_, err = UpdatePerson(ctx, *personID, &vo.PersonVO{Name: "Window Person III",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+	var exceeded *CapExceededError
+	if assert.ErrorAs(suite.T(), err, &exceeded) {
+		assert.Equal(suite.T(), CapPerWindow, exceeded.Limit)
+		assert.Equal(suite.T(), SubmissionCounts{InWindow: 2}, exceeded.Counts)
+		assert.Equal(suite.T(), submitter, exceeded.SubmittedBy)
+	}
+
+	// Another submitter, and the same one on another type, are unaffected.
+	_, err = UpdatePerson(ctx, *personID, &vo.PersonVO{Name: "Window Person III",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: "other-" + suite.marker}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	studioID, err := AddStudio(ctx, &vo.StudioVO{Name: "Window Studio"})
+	assert.NoError(suite.T(), err)
+	_, err = UpdateStudio(ctx, *studioID, &vo.StudioVO{Name: "Window Studio II",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *ReviewQueueTestSuite) TestSubmissionCapWindowCountsPartialAcceptOnce() {
+	ctx := suite.T().Context()
+	defer func(limits SubmissionCap) { PersonSubmissionCap = limits }(PersonSubmissionCap)
+	PersonSubmissionCap = SubmissionCap{MaxPerWindow: 2, Window: time.Hour}
+	submitter := "partial-" + suite.marker
+
+	personID, err := AddPerson(ctx, &vo.PersonVO{Name: "Partial Person"})
+	assert.NoError(suite.T(), err)
+	submitted, err := UpdatePerson(ctx, *personID, &vo.PersonVO{Name: "Partial Person I", Notes: "Some notes",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	// Taking only the name derives a new live version, stamped with the same submitter.
+	derived, _, err := AcceptPersonVersion(ctx, *personID, submitted.Version, []string{"name"}, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), submitted.Version, derived.Version)
+
+	_, err = UpdatePerson(ctx, *personID, &vo.PersonVO{Name: "Partial Person II",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	_, err = UpdatePerson(ctx, *personID, &vo.PersonVO{Name: "Partial Person III",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: submitter}}, models.VersionStateSubmitted, nil)
+	var exceeded *CapExceededError
+	if assert.ErrorAs(suite.T(), err, &exceeded) {
+		assert.Equal(suite.T(), SubmissionCounts{InWindow: 2}, exceeded.Counts)
+	}
+}
+
+func TestReviewQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(ReviewQueueTestSuite))
+}
@@ -0,0 +1,180 @@
+package data
+
+import (
+	"github.com/stretchr/testify/assert"
+	apiutil "github.com/sweetrpg/api-core.go/util"
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	modelcorevo "github.com/sweetrpg/model-core.go/vo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (suite *VolumeDataTestSuite) TestAddUpdateDeleteReview() {
+	ctx := suite.T().Context()
+
+	id, err := AddReview(ctx, suite.seedVolumeID, &vo.ReviewVO{
+		Title: "Solid", Body: "Good maps.", Language: "en",
+		AuditableVO: modelcorevo.AuditableVO{CreatedBy: "auth0|reader"},
+	}, 4)
+	assert.NoError(suite.T(), err)
+
+	got, err := GetReview(ctx, *id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Solid", got.Title)
+	assert.Equal(suite.T(), "auth0|reader", got.CreatedBy)
+	assert.Equal(suite.T(), 4, got.Rating)
+	if assert.NotNil(suite.T(), got.Volume) {
+		assert.Equal(suite.T(), suite.seedVolumeID, got.Volume.ID)
+	}
+
+	updated, err := UpdateReview(ctx, *id, &vo.ReviewVO{
+		Title: "Very solid", Body: "Great maps.", Language: "en",
+		AuditableVO: modelcorevo.AuditableVO{UpdatedBy: "auth0|reader"},
+	}, 5)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Very solid", updated.Title)
+	assert.Equal(suite.T(), "auth0|reader", updated.CreatedBy)
+	assert.Equal(suite.T(), 5, updated.Rating)
+
+	deleted, err := DeleteReview(ctx, *id, "auth0|reader")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), deleted)
+	_, err = GetReview(ctx, *id)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+	_, err = UpdateReview(ctx, *id, &vo.ReviewVO{Title: "Too late"}, 0)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+	deleted, err = DeleteReview(ctx, *id, "auth0|reader")
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), deleted)
+}
+
+func (suite *VolumeDataTestSuite) TestAddReviewOnePerAuthorPerVolume() {
+	ctx := suite.T().Context()
+	author := modelcorevo.AuditableVO{CreatedBy: "auth0|critic"}
+
+	id, err := AddReview(ctx, suite.seedVolumeID, &vo.ReviewVO{Title: "First", AuditableVO: author}, 0)
+	assert.NoError(suite.T(), err)
+
+	_, err = AddReview(ctx, suite.seedVolumeID, &vo.ReviewVO{Title: "Second", AuditableVO: author}, 0)
+	var duplicate *DuplicateError
+	if assert.ErrorAs(suite.T(), err, &duplicate) {
+		assert.Equal(suite.T(), *id, duplicate.ExistingID)
+	}
+	assert.ErrorIs(suite.T(), err, ErrDuplicate)
+
+	// Once the first is deleted, reviewing again brings it back with the new text.
+	_, err = DeleteReview(ctx, *id, "auth0|critic")
+	assert.NoError(suite.T(), err)
+	again, err := AddReview(ctx, suite.seedVolumeID, &vo.ReviewVO{Title: "Second thoughts", AuditableVO: author}, 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *id, *again)
+	got, err := GetReview(ctx, *id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Second thoughts", got.Title)
+
+	_, err = AddReview(ctx, "no-such-volume", &vo.ReviewVO{Title: "Lost", AuditableVO: author}, 0)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+}
+
+func (suite *VolumeDataTestSuite) TestQueryReviewsByVolumeAndAuthor() {
+	ctx := suite.T().Context()
+	// A fresh author, so reviews left by earlier runs on a shared database don't count.
+	anna := "auth0|anna-" + primitive.NewObjectID().Hex()
+	otherVolume, err := AddVolume(ctx, &vo.VolumeVO{Title: "Other Volume"})
+	assert.NoError(suite.T(), err)
+
+	_, err = AddReview(ctx, suite.seedVolumeID, &vo.ReviewVO{Title: "English", Language: "en",
+		AuditableVO: modelcorevo.AuditableVO{CreatedBy: anna}}, 0)
+	assert.NoError(suite.T(), err)
+	_, err = AddReview(ctx, suite.seedVolumeID, &vo.ReviewVO{Title: "Deutsch", Language: "de",
+		AuditableVO: modelcorevo.AuditableVO{CreatedBy: "auth0|bernd"}}, 0)
+	assert.NoError(suite.T(), err)
+	_, err = AddReview(ctx, *otherVolume, &vo.ReviewVO{Title: "Elsewhere", Language: "en",
+		AuditableVO: modelcorevo.AuditableVO{CreatedBy: anna}}, 0)
+	assert.NoError(suite.T(), err)
+
+	byVolume, err := QueryReviewsByVolume(ctx, suite.seedVolumeID, "", apiutil.QueryParams{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), byVolume, 2)
+
+	german, err := QueryReviewsByVolume(ctx, suite.seedVolumeID, "de", apiutil.QueryParams{})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), german, 1) {
+		assert.Equal(suite.T(), "Deutsch", german[0].Title)
+	}
+
+	byAuthor, err := QueryReviewsByAuthor(ctx, anna, "en", apiutil.QueryParams{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), byAuthor, 2)
+}
+
+func (suite *VolumeDataTestSuite) TestVolumeRatingSummaryFollowsReviews() {
+	ctx := suite.T().Context()
+	review := func(author string) *vo.ReviewVO {
+		return &vo.ReviewVO{Title: "Rated", AuditableVO: modelcorevo.AuditableVO{CreatedBy: author, UpdatedBy: author}}
+	}
+
+	summary, err := GetVolumeRatingSummary(ctx, suite.seedVolumeID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, summary.Count)
+	assert.Equal(suite.T(), map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}, summary.Histogram)
+
+	first, err := AddReview(ctx, suite.seedVolumeID, review("auth0|a"), 5)
+	assert.NoError(suite.T(), err)
+	second, err := AddReview(ctx, suite.seedVolumeID, review("auth0|b"), 3)
+	assert.NoError(suite.T(), err)
+	_, err = AddReview(ctx, suite.seedVolumeID, review("auth0|c"), 0)
+	assert.NoError(suite.T(), err)
+	_, err = AddReview(ctx, suite.seedVolumeID, review("auth0|d"), 6)
+	assert.ErrorIs(suite.T(), err, ErrInvalidRating)
+
+	summary, err = GetVolumeRatingSummary(ctx, suite.seedVolumeID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, summary.Count)
+	assert.Equal(suite.T(), 4.0, summary.Mean)
+	assert.Equal(suite.T(), map[int]int{1: 0, 2: 0, 3: 1, 4: 0, 5: 1}, summary.Histogram)
+
+	// Re-rating moves a review between buckets; deleting takes it out.
+	_, err = UpdateReview(ctx, *second, review("auth0|b"), 1)
+	assert.NoError(suite.T(), err)
+	_, err = DeleteReview(ctx, *first, "auth0|a")
+	assert.NoError(suite.T(), err)
+	summary, err = GetVolumeRatingSummary(ctx, suite.seedVolumeID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, summary.Count)
+	assert.Equal(suite.T(), 1.0, summary.Mean)
+	assert.Equal(suite.T(), map[int]int{1: 1, 2: 0, 3: 0, 4: 0, 5: 0}, summary.Histogram)
+
+	rebuilt, err := RebuildVolumeRatings(ctx)
+	assert.NoError(suite.T(), err)
+	assert.GreaterOrEqual(suite.T(), rebuilt, 1)
+	again, err := GetVolumeRatingSummary(ctx, suite.seedVolumeID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), summary, again)
+
+	_, err = GetVolumeRatingSummary(ctx, "no-such-volume")
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
+}
+
+func (suite *VolumeDataTestSuite) TestQueryVolumesSortsByRating() {
+	ctx := suite.T().Context()
+	author := modelcorevo.AuditableVO{CreatedBy: "auth0|rater"}
+	high, err := AddVolume(ctx, &vo.VolumeVO{Title: "Zz Highly Rated"})
+	assert.NoError(suite.T(), err)
+	_, err = AddReview(ctx, *high, &vo.ReviewVO{Title: "Great", AuditableVO: author}, 5)
+	assert.NoError(suite.T(), err)
+	_, err = AddReview(ctx, suite.seedVolumeID, &vo.ReviewVO{Title: "Meh", AuditableVO: author}, 2)
+	assert.NoError(suite.T(), err)
+
+	// A version written after the review still carries the aggregates.
+	_, err = UpdateVolume(ctx, *high, &vo.VolumeVO{Title: "Zz Highly Rated", Description: "edited"}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	volumes, err := QueryVolumes(ctx, apiutil.QueryParams{Sort: []apiutil.Sort{{Field: RatingMeanField, Order: -1}}})
+	assert.NoError(suite.T(), err)
+	position := map[string]int{}
+	for i, volume := range volumes {
+		position[volume.ID] = i
+	}
+	assert.Less(suite.T(), position[*high], position[suite.seedVolumeID])
+}
@@ -0,0 +1,401 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"github.com/sweetrpg/common.go/logging"
+	modelcore "github.com/sweetrpg/model-core.go/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const searchCollection = "catalog_search"
+
+// searchCandidateLimit bounds how many matching search documents one query ranks. Candidates
+// whose title matches every query term - the only ones that can score for the title - are
+// fetched ahead of those matching elsewhere, so the cap drops the weakest matches first; within
+// each of those two tiers it drops by title order. It only bites for a very short, very common
+// prefix.
+const searchCandidateLimit = 1000
+
+// searchDocument is the denormalized search entry for one record's current version - one per
+// record, in searchCollection, rewritten whenever the record's current version moves. Terms
+// are searchTerms words, so a prefix match is an anchored regex on a multikey index.
+type searchDocument struct {
+	ID         string   `bson:"_id"` // entity + ":" + record id
+	Entity     string   `bson:"entity"`
+	RecordID   string   `bson:"record_id"`
+	Title      string   `bson:"title"`
+	TitleTerms []string `bson:"title_terms"`
+	Terms      []string `bson:"terms"` // TitleTerms plus every other searchable field's terms
+	Deleted    bool     `bson:"deleted"`
+}
+
+// SearchHit is one ranked SearchCatalog result. Exactly one of the typed fields is set,
+// matching Entity.
+type SearchHit struct {
+	Entity string // "volume", "publisher", "studio", "person" or "license"
+	ID     string
+	Title  string
+	Score  int
+
+	Volume    *vo.VolumeVO
+	Publisher *vo.PublisherVO
+	Studio    *vo.StudioVO
+	Person    *vo.PersonVO
+	License   *vo.LicenseVO
+}
+
+// SearchEntities is every entity type SearchCatalog covers, in the order its results break
+// score ties.
+var SearchEntities = []string{"volume", "publisher", "studio", "person", "license"}
+
+// EnsureSearchIndexes creates the indexes search queries rely on: multikey indexes on terms and
+// title_terms, which serve anchored prefix matches, and (entity, deleted) for scoping. Safe to
+// call on every startup.
+func EnsureSearchIndexes(c context.Context) error {
+	if err := Storage.EnsureIndex(c, searchCollection, bson.D{{Key: "terms", Value: 1}}, false); err != nil {
+		return fmt.Errorf("search: create terms index: %w", err)
+	}
+	if err := Storage.EnsureIndex(c, searchCollection, bson.D{{Key: "title_terms", Value: 1}}, false); err != nil {
+		return fmt.Errorf("search: create title_terms index: %w", err)
+	}
+	if err := Storage.EnsureIndex(c, searchCollection, bson.D{{Key: "entity", Value: 1}, {Key: "deleted", Value: 1}}, false); err != nil {
+		return fmt.Errorf("search: create entity+deleted index: %w", err)
+	}
+	return nil
+}
+
+// searchTerms splits text into NormalizeName'd words, dropping duplicates and keeping
+// first-seen order - so "uber dungeon" matches "Über-Dungeon", and "5th edition" matches "5E".
+func searchTerms(text ...string) []string {
+	seen := map[string]bool{}
+	terms := make([]string, 0)
+	for _, t := range text {
+		for _, word := range strings.Fields(NormalizeName(t)) {
+			if !seen[word] {
+				seen[word] = true
+				terms = append(terms, word)
+			}
+		}
+	}
+	return terms
+}
+
+// tagSearchText is the searchable text of a record's tags - each tag's name and value.
+func tagSearchText(tags []modelcore.Tag) []string {
+	text := make([]string, 0, 2*len(tags))
+	for _, tag := range tags {
+		text = append(text, tag.Name, tag.Value)
+	}
+	return text
+}
+
+func searchDocumentID(entity, id string) string {
+	return entity + ":" + id
+}
+
+// indexSearch rewrites the search document for record id from live, its new current version.
+// A no-op for a type without a search config. Call it in the transaction that moved the
+// current version.
+func (cfg entityVersioningConfig[T]) indexSearch(c context.Context, id string, live *T, deleted bool) error {
+	if cfg.search == nil {
+		return nil
+	}
+	title, text := cfg.search(live)
+	titleTerms := searchTerms(title)
+	doc := searchDocument{
+		ID: searchDocumentID(cfg.typeName, id), Entity: cfg.typeName, RecordID: id,
+		Title: title, TitleTerms: titleTerms, Terms: searchTerms(append([]string{title}, text...)...),
+		Deleted: deleted,
+	}
+	if _, err := Storage.DeleteOne(c, searchCollection, bson.D{{Key: "_id", Value: doc.ID}}); err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	if err := storeInsert(c, searchCollection, doc); err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	return nil
+}
+
+// markSearchDeleted flags record id's search document deleted (or not), hiding it from (or
+// returning it to) search results along with every other read path.
+func (cfg entityVersioningConfig[T]) markSearchDeleted(c context.Context, id string, deleted bool) error {
+	if cfg.search == nil {
+		return nil
+	}
+	_, err := Storage.UpdateOne(c, searchCollection,
+		bson.D{{Key: "_id", Value: searchDocumentID(cfg.typeName, id)}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "deleted", Value: deleted}}}})
+	return err
+}
+
+// unindexSearch removes record id's search document, for a purge.
+func (cfg entityVersioningConfig[T]) unindexSearch(c context.Context, id string) error {
+	if cfg.search == nil {
+		return nil
+	}
+	_, err := Storage.DeleteOne(c, searchCollection, bson.D{{Key: "_id", Value: searchDocumentID(cfg.typeName, id)}})
+	return err
+}
+
+// rebuildSearch reindexes every record of cfg's type from its current version.
+func (cfg entityVersioningConfig[T]) rebuildSearch(c context.Context) (int, error) {
+	metas, err := storeQuery[models.EntityMeta](c, cfg.metaCollection, nil, nil, nil, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]string, 0, len(metas))
+	for _, meta := range metas {
+		ids = append(ids, meta.ID)
+	}
+	byID, versions, err := cfg.getCurrentMany(c, ids)
+	if err != nil {
+		return 0, err
+	}
+	for id, meta := range byID {
+		if err := cfg.indexSearch(c, id, versions[id], meta.DeletedAt != nil); err != nil {
+			return 0, err
+		}
+	}
+	return len(byID), nil
+}
+
+// RebuildSearchIndex reindexes every volume, publisher, studio, person and license from its
+// current version, returning how many records it indexed - a one-time backfill for records
+// written before the search index existed, or a repair after restoring a backup. Writes keep
+// the index current on their own.
+func RebuildSearchIndex(c context.Context) (int, error) {
+	total := 0
+	for _, rebuild := range []func(context.Context) (int, error){
+		volumeVersioning.rebuildSearch, publisherVersioning.rebuildSearch, studioVersioning.rebuildSearch,
+		personVersioning.rebuildSearch, licenseVersioning.rebuildSearch,
+	} {
+		n, err := rebuild(c)
+		total += n
+		if err != nil {
+			logging.Logger.Error(fmt.Sprintf("Error while rebuilding search index: %+v", err))
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// scoreSearchDocument ranks doc against the query's terms: the whole title matching the query
+// beats the title starting with it, and each query term scores more for an exact title word
+// than a title-word prefix, and more for either than a match in the rest of the record (tags,
+// description).
+func scoreSearchDocument(doc *searchDocument, terms []string) int {
+	score := 0
+	title, phrase := strings.Join(doc.TitleTerms, " "), strings.Join(terms, " ")
+	switch {
+	case title == phrase:
+		score += 100
+	case strings.HasPrefix(title, phrase):
+		score += 50
+	}
+
+	for _, term := range terms {
+		best := 1
+		for _, word := range doc.TitleTerms {
+			if word == term {
+				best = 10
+				break
+			}
+			if strings.HasPrefix(word, term) {
+				best = 5
+			}
+		}
+		if best == 1 {
+			for _, word := range doc.Terms {
+				if word == term {
+					best = 2
+					break
+				}
+			}
+		}
+		score += best
+	}
+	return score
+}
+
+// searchDocuments runs query against the live search documents of entities, returning one
+// ranked page (limit <= 0 for every ranked match). Every query term must prefix-match some
+// term of the record, so "dung dra" finds "Dungeon Dragons" - the autocomplete case. At most
+// searchCandidateLimit matches are ranked.
+func searchDocuments(c context.Context, query string, entities []string, start, limit int) ([]*searchDocument, []int, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []*searchDocument{}, []int{}, nil
+	}
+
+	docs, err := searchCandidates(c, terms, entities)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entityOrder := make(map[string]int, len(SearchEntities))
+	for i, entity := range SearchEntities {
+		entityOrder[entity] = i
+	}
+	scores := make(map[string]int, len(docs))
+	for _, doc := range docs {
+		scores[doc.ID] = scoreSearchDocument(doc, terms)
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		if scores[docs[i].ID] != scores[docs[j].ID] {
+			return scores[docs[i].ID] > scores[docs[j].ID]
+		}
+		if docs[i].Title != docs[j].Title {
+			return docs[i].Title < docs[j].Title
+		}
+		return entityOrder[docs[i].Entity] < entityOrder[docs[j].Entity]
+	})
+
+	start = min(max(start, 0), len(docs))
+	end := len(docs)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	page := docs[start:end]
+	pageScores := make([]int, 0, len(page))
+	for _, doc := range page {
+		pageScores = append(pageScores, scores[doc.ID])
+	}
+	return page, pageScores, nil
+}
+
+// searchCandidates fetches up to searchCandidateLimit live search documents of entities matching
+// every term, those whose title matches every term first.
+func searchCandidates(c context.Context, terms []string, entities []string) ([]*searchDocument, error) {
+	prefixMatches := func(key string) bson.A {
+		matches := make(bson.A, 0, len(terms))
+		for _, term := range terms {
+			matches = append(matches, bson.D{{Key: key, Value: bson.D{{Key: "$regex", Value: "^" + regexp.QuoteMeta(term)}}}})
+		}
+		return matches
+	}
+	scope := func(matches bson.A, extra ...bson.E) bson.D {
+		filter := bson.D{
+			{Key: "entity", Value: bson.D{{Key: "$in", Value: entities}}},
+			{Key: "deleted", Value: false},
+			{Key: "$and", Value: matches},
+		}
+		return append(filter, extra...)
+	}
+	byTitle := bson.D{{Key: "title", Value: 1}}
+
+	docs, err := storeQuery[searchDocument](c, searchCollection, scope(prefixMatches("title_terms")), byTitle, nil, 0, searchCandidateLimit)
+	if err != nil || len(docs) == searchCandidateLimit {
+		return docs, err
+	}
+	fetched := make(bson.A, 0, len(docs))
+	for _, doc := range docs {
+		fetched = append(fetched, doc.ID)
+	}
+	rest, err := storeQuery[searchDocument](c, searchCollection,
+		scope(prefixMatches("terms"), bson.E{Key: "_id", Value: bson.D{{Key: "$nin", Value: fetched}}}),
+		byTitle, nil, 0, searchCandidateLimit-len(docs))
+	if err != nil {
+		return nil, err
+	}
+	return append(docs, rest...), nil
+}
+
+// searchEntity is searchDocuments for one type, resolving the page of hits to VOs in rank order.
+// A hit whose record no longer resolves is dropped.
+func searchEntity[V any](c context.Context, entity, query string, start, limit int, resolve func(context.Context, []string) map[string]*V) ([]*V, error) {
+	docs, _, err := searchDocuments(c, query, []string{entity}, start, limit)
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while searching %s: %+v", entity, err))
+		return nil, err
+	}
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.RecordID)
+	}
+	resolved := resolve(c, ids)
+	results := make([]*V, 0, len(ids))
+	for _, id := range ids {
+		if v, ok := resolved[id]; ok {
+			results = append(results, v)
+		}
+	}
+	return results, nil
+}
+
+func resolvePublishers(c context.Context, ids []string) map[string]*vo.PublisherVO {
+	return resolveCurrent(c, publisherVersioning, ids, flattenPublisher)
+}
+
+func resolveStudios(c context.Context, ids []string) map[string]*vo.StudioVO {
+	return resolveCurrent(c, studioVersioning, ids, flattenStudio)
+}
+
+func resolvePersons(c context.Context, ids []string) map[string]*vo.PersonVO {
+	return resolveCurrent(c, personVersioning, ids, flattenPerson)
+}
+
+func resolveLicenses(c context.Context, ids []string) map[string]*vo.LicenseVO {
+	return resolveCurrent(c, licenseVersioning, ids, flattenLicense)
+}
+
+// SearchVolumes finds live volumes by title, description and tags, best match first - every
+// word of query must match the start of a word in the volume, so it serves autocomplete as
+// well as full queries. start/limit page the ranked results (limit <= 0 for all of them; a
+// negative start is treated as 0). Only the first 1000 matches are ranked, those matching in the
+// title first, so a very short, very common query can miss weaker matches past that.
+func SearchVolumes(c context.Context, query string, start, limit int) ([]*vo.VolumeVO, error) {
+	return searchEntity(c, "volume", query, start, limit, loadVolumes)
+}
+
+// SearchCatalog is SearchVolumes across every type in entities (nil for SearchEntities),
+// returning one ranked list of mixed hits. The 1000-match ranking cap applies across all of
+// entities together, not per type.
+func SearchCatalog(c context.Context, query string, entities []string, start, limit int) ([]*SearchHit, error) {
+	if entities == nil {
+		entities = SearchEntities
+	}
+	docs, scores, err := searchDocuments(c, query, entities, start, limit)
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while searching catalog: %+v", err))
+		return nil, err
+	}
+
+	idsByEntity := map[string][]string{}
+	for _, doc := range docs {
+		idsByEntity[doc.Entity] = append(idsByEntity[doc.Entity], doc.RecordID)
+	}
+	volumes := loadVolumes(c, idsByEntity["volume"])
+	publishers := resolvePublishers(c, idsByEntity["publisher"])
+	studios := resolveStudios(c, idsByEntity["studio"])
+	persons := resolvePersons(c, idsByEntity["person"])
+	licenses := resolveLicenses(c, idsByEntity["license"])
+
+	hits := make([]*SearchHit, 0, len(docs))
+	for i, doc := range docs {
+		hit := &SearchHit{Entity: doc.Entity, ID: doc.RecordID, Title: doc.Title, Score: scores[i]}
+		switch doc.Entity {
+		case "volume":
+			hit.Volume = volumes[doc.RecordID]
+		case "publisher":
+			hit.Publisher = publishers[doc.RecordID]
+		case "studio":
+			hit.Studio = studios[doc.RecordID]
+		case "person":
+			hit.Person = persons[doc.RecordID]
+		case "license":
+			hit.License = licenses[doc.RecordID]
+		}
+		if hit.Volume == nil && hit.Publisher == nil && hit.Studio == nil && hit.Person == nil && hit.License == nil {
+			continue
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
@@ -0,0 +1,112 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	modelcorevo "github.com/sweetrpg/model-core.go/vo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchTestSuite checks the catalog_search index stays in step with writes and ranks hits.
+// Each test puts a fresh marker word in every record it creates and queries for it, so records
+// left behind by other tests on a shared database never match.
+type SearchTestSuite struct {
+	suite.Suite
+	marker string
+}
+
+func (suite *SearchTestSuite) SetupTest() {
+	setupTestStorage()
+	assert.NoError(suite.T(), EnsureSearchIndexes(suite.T().Context()))
+	suite.marker = "m" + primitive.NewObjectID().Hex()
+}
+
+func (suite *SearchTestSuite) TestSearchFollowsLiveVersionAndDeletion() {
+	ctx := suite.T().Context()
+	id, err := AddPublisher(ctx, &vo.PublisherVO{Name: suite.marker + " Alpha Press"})
+	assert.NoError(suite.T(), err)
+
+	found, err := SearchPublishers(ctx, suite.marker+" alp", 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+
+	// A submission isn't live, so it isn't searchable until accepted.
+	submitted, err := UpdatePublisher(ctx, *id, &vo.PublisherVO{Name: suite.marker + " Beta Press"}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	found, err = SearchPublishers(ctx, suite.marker+" beta", 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found)
+
+	_, _, err = AcceptPublisherVersion(ctx, *id, submitted.Version, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	found, err = SearchPublishers(ctx, suite.marker+" beta", 0, 0)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), found, 1) {
+		assert.Equal(suite.T(), suite.marker+" Beta Press", found[0].Name)
+	}
+	found, err = SearchPublishers(ctx, suite.marker+" alpha", 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found)
+
+	assert.NoError(suite.T(), SoftDeletePublisher(ctx, *id, "admin-1"))
+	found, err = SearchPublishers(ctx, suite.marker, 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found)
+
+	assert.NoError(suite.T(), RestorePublisher(ctx, *id))
+	found, err = SearchPublishers(ctx, suite.marker, 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+}
+
+func (suite *SearchTestSuite) TestSearchCatalogRanksMixedHits() {
+	ctx := suite.T().Context()
+	_, err := AddVolume(ctx, &vo.VolumeVO{Title: suite.marker + " Dragon Atlas", Description: "Maps."})
+	assert.NoError(suite.T(), err)
+	_, err = AddPublisher(ctx, &vo.PublisherVO{Name: suite.marker + " Dragonfly Games"})
+	assert.NoError(suite.T(), err)
+	_, err = AddPerson(ctx, &vo.PersonVO{Name: suite.marker + " Jo Smith", Tags: []modelcorevo.TagVO{{Name: "dragon"}}})
+	assert.NoError(suite.T(), err)
+
+	hits, err := SearchCatalog(ctx, suite.marker+" dragon", nil, 0, 0)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), hits, 3) {
+		// Exact title word, then title-word prefix, then a tag match.
+		assert.Equal(suite.T(), "volume", hits[0].Entity)
+		assert.NotNil(suite.T(), hits[0].Volume)
+		assert.Equal(suite.T(), "publisher", hits[1].Entity)
+		assert.NotNil(suite.T(), hits[1].Publisher)
+		assert.Equal(suite.T(), "person", hits[2].Entity)
+		assert.NotNil(suite.T(), hits[2].Person)
+		assert.Greater(suite.T(), hits[0].Score, hits[1].Score)
+		assert.Greater(suite.T(), hits[1].Score, hits[2].Score)
+	}
+
+	page, err := SearchCatalog(ctx, suite.marker+" dragon", nil, 1, 1)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), page, 1) {
+		assert.Equal(suite.T(), "publisher", page[0].Entity)
+	}
+
+	volumes, err := SearchVolumes(ctx, suite.marker+" drag", 0, 0)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), volumes, 1)
+}
+
+func (suite *SearchTestSuite) TestSearchNegativeStartIsFirstPage() {
+	ctx := suite.T().Context()
+	_, err := AddPublisher(ctx, &vo.PublisherVO{Name: suite.marker + " Gamma Press"})
+	assert.NoError(suite.T(), err)
+
+	found, err := SearchPublishers(ctx, suite.marker, -5, 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), found, 1)
+}
+
+func TestSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchTestSuite))
+}
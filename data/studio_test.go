@@ -1,16 +1,13 @@
 package data
 
 import (
-	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
-	"github.com/sweetrpg/common.go/logging"
-	"github.com/sweetrpg/mongodb.go/constants"
-	"github.com/sweetrpg/mongodb.go/database"
 )
 
 type StudioDataTestSuite struct {
@@ -19,9 +16,7 @@ type StudioDataTestSuite struct {
 }
 
 func (suite *StudioDataTestSuite) SetupTest() {
-	_ = os.Setenv(constants.DB_URI, os.Getenv("TEST_DB_URI"))
-	logging.Init()
-	database.SetupDatabase()
+	setupTestStorage()
 	assert.NoError(suite.T(), EnsureStudioVersioningIndexes(suite.T().Context()))
 
 	id, err := AddStudio(suite.T().Context(), &vo.StudioVO{Name: "Test Studio"})
@@ -33,7 +28,7 @@ func (suite *StudioDataTestSuite) SetupTest() {
 func (suite *StudioDataTestSuite) TestUpdateStudioLive() {
 	updated, err := UpdateStudio(suite.T().Context(), suite.seedStudioID, &vo.StudioVO{
 		Name: "Updated Studio",
-	}, models.VersionStateLive)
+	}, models.VersionStateLive, nil)
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updated)
 	assert.Equal(suite.T(), "Updated Studio", updated.Name)
@@ -47,19 +42,19 @@ func (suite *StudioDataTestSuite) TestUpdateStudioLive() {
 func (suite *StudioDataTestSuite) TestUpdateStudioNotFound() {
 	updated, err := UpdateStudio(suite.T().Context(), "does-not-exist", &vo.StudioVO{
 		Name: "Doesn't Matter",
-	}, models.VersionStateLive)
-	assert.NoError(suite.T(), err)
+	}, models.VersionStateLive, nil)
+	assert.ErrorIs(suite.T(), err, ErrNotFound)
 	assert.Nil(suite.T(), updated)
 }
 
 func (suite *StudioDataTestSuite) TestAcceptStudioVersionFullAccept() {
 	submitted, err := UpdateStudio(suite.T().Context(), suite.seedStudioID, &vo.StudioVO{
 		Name: "Proposed Studio",
-	}, models.VersionStateSubmitted)
+	}, models.VersionStateSubmitted, nil)
 	assert.NoError(suite.T(), err)
 
 	accepted, conflicts, err := AcceptStudioVersion(
-		suite.T().Context(), suite.seedStudioID, submitted.Version, nil, "editor-1", nil)
+		suite.T().Context(), suite.seedStudioID, submitted.Version, nil, nil, "editor-1", nil, nil)
 	assert.NoError(suite.T(), err)
 	assert.Empty(suite.T(), conflicts)
 	assert.Equal(suite.T(), string(models.VersionStateLive), string(accepted.State))
@@ -77,6 +72,9 @@ func (suite *StudioDataTestSuite) TestGetStudioStatsReflectsMostRecent() {
 	assert.NotEmpty(suite.T(), stats.MostRecentID)
 	assert.NotEmpty(suite.T(), stats.MostRecentName)
 
+	// submitted_at is stored at millisecond precision - make sure the second studio's is later
+	// than the seed's, which an in-memory Storage can otherwise write in the same millisecond.
+	time.Sleep(2 * time.Millisecond)
 	secondID, err := AddStudio(suite.T().Context(), &vo.StudioVO{Name: "Second Studio"})
 	assert.NoError(suite.T(), err)
 
@@ -3,7 +3,6 @@ package data
 import (
 	"context"
 	"net/url"
-	"strings"
 	"time"
 
 	apiutil "github.com/sweetrpg/api-core.go/util"
@@ -12,7 +11,6 @@ import (
 	modelcore "github.com/sweetrpg/model-core.go/models"
 	modelcoreutil "github.com/sweetrpg/model-core.go/util"
 	modelcorevo "github.com/sweetrpg/model-core.go/vo"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -21,23 +19,39 @@ const (
 	studioVersionCollection = "studios_versions"
 )
 
+// StudioApprovalPolicy is VolumeApprovalPolicy for studios.
+var StudioApprovalPolicy ApprovalPolicy
+
+// StudioSubmissionCap is VolumeSubmissionCap for studios.
+var StudioSubmissionCap SubmissionCap
+
 var studioVersioning = entityVersioningConfig[models.StudioVersion]{
 	metaCollection:    studioMetaCollection,
 	versionCollection: studioVersionCollection,
 	typeName:          "studio",
-	lifecycle:         func(v *models.StudioVersion) *models.VersionLifecycle { return &v.VersionLifecycle },
+	lifecycle:         func(v *models.StudioVersion) models.VersionLifecycle { return v.VersionLifecycle },
+	setLifecycle:      func(v *models.StudioVersion, lc models.VersionLifecycle) { v.VersionLifecycle = lc },
 	setID:             func(v *models.StudioVersion, id string) { v.ID = id },
 	setRecordID:       func(v *models.StudioVersion, id string) { v.RecordID = id },
 	setVersion:        func(v *models.StudioVersion, n int) { v.Version = n },
+	version:           func(v *models.StudioVersion) int { return v.Version },
 	recordID:          func(v *models.StudioVersion) string { return v.RecordID },
 	displayName:       func(v *models.StudioVersion) string { return v.Name },
 	fields: map[string]entityFieldAccessor[models.StudioVersion]{
 		"name":       {get: func(v *models.StudioVersion) any { return v.Name }, set: func(v *models.StudioVersion, val any) { v.Name = val.(string) }},
 		"website":    {get: func(v *models.StudioVersion) any { return v.Website }, set: func(v *models.StudioVersion, val any) { v.Website = val.(url.URL) }},
 		"notes":      {get: func(v *models.StudioVersion) any { return v.Notes }, set: func(v *models.StudioVersion, val any) { v.Notes = val.(string) }},
-		"properties": {get: func(v *models.StudioVersion) any { return v.Properties }, set: func(v *models.StudioVersion, val any) { v.Properties = val.([]modelcore.Property) }},
-		"tags":       {get: func(v *models.StudioVersion) any { return v.Tags }, set: func(v *models.StudioVersion, val any) { v.Tags = val.([]modelcore.Tag) }},
+		"properties": {get: func(v *models.StudioVersion) any { return v.Properties }, set: func(v *models.StudioVersion, val any) { v.Properties = val.([]modelcore.Property) }, setMerge: true},
+		"tags":       {get: func(v *models.StudioVersion) any { return v.Tags }, set: func(v *models.StudioVersion, val any) { v.Tags = val.([]modelcore.Tag) }, setMerge: true},
+	},
+	references: []entityReference{
+		{dependentType: "volume", collection: volumeVersionCollection, field: "studio_ids", listed: true},
+	},
+	search: func(v *models.StudioVersion) (string, []string) {
+		return v.Name, tagSearchText(v.Tags)
 	},
+	approvalPolicy: &StudioApprovalPolicy,
+	submissionCap:  &StudioSubmissionCap,
 }
 
 // EnsureStudioVersioningIndexes creates the indexes studio version queries rely on. Safe to call
@@ -52,6 +66,12 @@ func SoftDeleteStudio(c context.Context, id string, deletedBy string) error {
 	return studioVersioning.softDelete(c, id, deletedBy)
 }
 
+// DeleteStudio hard-deletes a studio and its version history, handling volume versions still
+// listing it in studio_ids per policy - see DeleteVolume.
+func DeleteStudio(c context.Context, id string, policy CascadePolicy) error {
+	return studioVersioning.purge(c, id, policy)
+}
+
 // RestoreStudio clears a soft-deleted studio's deletion, returning it to every normal read path.
 func RestoreStudio(c context.Context, id string) error {
 	return studioVersioning.restore(c, id)
@@ -99,30 +119,20 @@ func AddStudio(c context.Context, studio *vo.StudioVO) (*string, error) {
 	return studioVersioning.addEntity(c, &version, studio.CreatedBy)
 }
 
-// GetStudio returns the flattened view of a studio.
+// GetStudio returns the flattened view of a studio, following merge redirects like GetPublisher.
 func GetStudio(c context.Context, id string) (*vo.StudioVO, error) {
-	meta, err := studioVersioning.getMeta(c, id)
-	if err != nil {
-		return nil, err
-	}
-	if meta == nil {
-		return nil, nil
-	}
-	version, err := studioVersioning.getVersion(c, id, meta.CurrentVersion)
+	meta, version, err := studioVersioning.getCurrentFollowingMerges(c, id)
 	if err != nil {
 		return nil, err
 	}
-	if version == nil {
-		return nil, nil
-	}
 	return flattenStudio(meta, version), nil
 }
 
 // UpdateStudio creates a new version of the studio rather than mutating in place.
-func UpdateStudio(c context.Context, id string, studio *vo.StudioVO, state models.VersionState) (*vo.StudioVersionVO, error) {
+func UpdateStudio(c context.Context, id string, studio *vo.StudioVO, state models.VersionState, expectedCurrentVersion *int) (*vo.StudioVersionVO, error) {
 	version := studioVersionFields(studio)
-	result, err := studioVersioning.createVersion(c, id, &version, state, studio.UpdatedBy)
-	if err != nil || result == nil {
+	result, err := studioVersioning.createVersion(c, id, &version, state, studio.UpdatedBy, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return studioVersionToVO(result), nil
@@ -143,22 +153,41 @@ func ListStudioVersions(c context.Context, id string) ([]*vo.StudioVersionVO, er
 
 // GetStudioVersion returns one version's full snapshot.
 func GetStudioVersion(c context.Context, id string, version int) (*vo.StudioVersionVO, error) {
-	result, err := studioVersioning.getVersion(c, id, version)
-	if err != nil || result == nil {
+	result, err := studioVersioning.requireVersion(c, id, version)
+	if err != nil {
 		return nil, err
 	}
 	return studioVersionToVO(result), nil
 }
 
+// DiffStudioVersions is DiffVolumeVersions for a studio.
+func DiffStudioVersions(c context.Context, id string, from, to int) (*VersionDiff, error) {
+	return studioVersioning.diffVersions(c, id, from, to)
+}
+
+// DiffStudioSubmission is DiffVolumeSubmission for a studio, previewing AcceptStudioVersion.
+func DiffStudioSubmission(c context.Context, id string, version int) (*SubmissionDiff, error) {
+	return studioVersioning.diffSubmission(c, id, version)
+}
+
 // AcceptStudioVersion reviews a submitted version - see AcceptVolumeVersion's doc comment.
-func AcceptStudioVersion(c context.Context, id string, version int, selectedFields []string, reviewedBy string, reviewNote *string) (*vo.StudioVersionVO, []string, error) {
-	result, conflicts, err := studioVersioning.acceptVersion(c, id, version, selectedFields, reviewedBy, reviewNote)
-	if err != nil || result == nil {
+func AcceptStudioVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*vo.StudioVersionVO, []string, error) {
+	result, conflicts, err := studioVersioning.acceptVersion(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, expectedCurrentVersion)
+	if err != nil {
 		return nil, conflicts, err
 	}
 	return studioVersionToVO(result), conflicts, nil
 }
 
+// PreviewAcceptStudioVersion is PreviewAcceptVolumeVersion for a studio.
+func PreviewAcceptStudioVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, expectedCurrentVersion *int) (*AcceptPreview[vo.StudioVersionVO], error) {
+	plan, err := studioVersioning.previewAccept(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, nil, expectedCurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	return newAcceptPreview(plan, studioVersionToVO), nil
+}
+
 // RejectStudioVersion marks a submitted version rejected.
 func RejectStudioVersion(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
 	return studioVersioning.rejectVersion(c, id, version, reviewedBy, reviewNote)
@@ -167,16 +196,16 @@ func RejectStudioVersion(c context.Context, id string, version int, reviewedBy s
 // RetractStudioVersion lets the original submitter withdraw their own pending submission.
 func RetractStudioVersion(c context.Context, id string, version int, submitterID string) (*vo.StudioVersionVO, error) {
 	result, err := studioVersioning.retractVersion(c, id, version, submitterID)
-	if err != nil || result == nil {
+	if err != nil {
 		return nil, err
 	}
 	return studioVersionToVO(result), nil
 }
 
 // SetCurrentStudioVersion rolls a studio back (or forward) to an arbitrary existing version.
-func SetCurrentStudioVersion(c context.Context, id string, version int) (*vo.StudioVersionVO, error) {
-	result, err := studioVersioning.setCurrentVersion(c, id, version)
-	if err != nil || result == nil {
+func SetCurrentStudioVersion(c context.Context, id string, version int, expectedCurrentVersion *int) (*vo.StudioVersionVO, error) {
+	result, err := studioVersioning.setCurrentVersion(c, id, version, expectedCurrentVersion)
+	if err != nil {
 		return nil, err
 	}
 	return studioVersionToVO(result), nil
@@ -191,7 +220,7 @@ func CountSubmittedStudioVersionsBySubmitter(c context.Context, submittedBy stri
 func QueryStudios(c context.Context, params apiutil.QueryParams) ([]*vo.StudioVO, error) {
 	filter, sort, projection := apiutil.ConvertQueryParams(params)
 	filter = append(filter, bson.E{Key: "deleted_at", Value: nil})
-	metas, err := database.Query[models.EntityMeta](studioMetaCollection, filter, sort, projection, params.Start, params.Limit)
+	metas, err := storeQuery[models.EntityMeta](c, studioMetaCollection, filter, sort, projection, params.Start, params.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -209,30 +238,17 @@ func QueryStudios(c context.Context, params apiutil.QueryParams) ([]*vo.StudioVO
 	return vos, nil
 }
 
-// SearchStudios finds live studios whose name contains query (case-insensitive), scanning the
-// full collection - see data.SearchPersons for why this scans in memory rather than pushing the
-// match down to Mongo.
-func SearchStudios(c context.Context, query string) ([]*vo.StudioVO, error) {
-	all, err := QueryStudios(c, apiutil.QueryParams{Limit: searchScanLimit})
-	if err != nil {
-		return nil, err
-	}
-	needle := strings.ToLower(query)
-	matches := make([]*vo.StudioVO, 0, len(all))
-	for _, s := range all {
-		if strings.Contains(strings.ToLower(s.Name), needle) {
-			matches = append(matches, s)
-		}
-	}
-	return matches, nil
+// SearchStudios is SearchVolumes for studios, matching name and tags.
+func SearchStudios(c context.Context, query string, start, limit int) ([]*vo.StudioVO, error) {
+	return searchEntity(c, "studio", query, start, limit, resolveStudios)
 }
 
 // CreateSubmittedStudioVersion creates a submitted version stamped with a caller-supplied
 // submittedBy/submittedAt - see CreateSubmittedPublisherVersion's doc comment.
 func CreateSubmittedStudioVersion(c context.Context, id string, studio *vo.StudioVO, submittedBy string, submittedAt time.Time) (*vo.StudioVersionVO, error) {
 	version := studioVersionFields(studio)
-	result, err := studioVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt)
-	if err != nil || result == nil {
+	result, err := studioVersioning.createVersionWithSubmission(c, id, &version, models.VersionStateSubmitted, submittedBy, submittedAt, nil)
+	if err != nil {
 		return nil, err
 	}
 	return studioVersionToVO(result), nil
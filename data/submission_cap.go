@@ -0,0 +1,144 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// submissionCapLockCollection holds one document per (type, submitter) that a capped submission
+// bumps before counting, so two submissions by the same person serialize on it - in a
+// transaction, the second one's write conflicts and the driver retries it after the first has
+// committed, when its count includes the first.
+const submissionCapLockCollection = "submission_cap_locks"
+
+// SubmissionCap limits how much one submitter can have in flight for one type. A zero field is no
+// limit, so the zero value caps nothing, which is how every type behaves until its cap variable
+// (VolumeSubmissionCap, LicenseSubmissionCap, ...) is set - once at startup, like
+// VolumeReferenceValidation.
+type SubmissionCap struct {
+	// MaxPending is how many submitted versions a submitter may have awaiting review.
+	MaxPending int
+	// MaxPendingPerRecord is MaxPending for a single record.
+	MaxPendingPerRecord int
+	// MaxPerWindow is how many submissions a submitter may make in any Window, whatever became
+	// of them since. Live edits don't count.
+	MaxPerWindow int
+	Window       time.Duration
+}
+
+// SubmissionCapLimit names the SubmissionCap field a submission ran into.
+type SubmissionCapLimit string
+
+const (
+	CapPending          SubmissionCapLimit = "pending"
+	CapPendingPerRecord SubmissionCapLimit = "pending_per_record"
+	CapPerWindow        SubmissionCapLimit = "per_window"
+)
+
+// SubmissionCounts is where a submitter stood against a SubmissionCap when they submitted: their
+// pending submissions of the type, those on the record, and submissions made within the window.
+// A count whose limit is unset isn't taken and stays 0.
+type SubmissionCounts struct {
+	Pending         int
+	PendingOnRecord int
+	InWindow        int
+}
+
+// capped reports whether limits caps anything at all.
+func (limits *SubmissionCap) capped() bool {
+	return limits.MaxPending > 0 || limits.MaxPendingPerRecord > 0 || (limits.MaxPerWindow > 0 && limits.Window > 0)
+}
+
+// lockSubmitter bumps submittedBy's lock document for cfg's type, creating it on first use: a
+// racing creator's insert fails on the duplicate _id and it retries the increment.
+func (cfg entityVersioningConfig[T]) lockSubmitter(c context.Context, submittedBy string) error {
+	lockID := cfg.typeName + "/" + submittedBy
+	increment := bson.D{{Key: "$inc", Value: bson.D{{Key: "submissions", Value: int64(1)}}}}
+	for attempt := 0; attempt < 2; attempt++ {
+		doc, err := Storage.FindOneAndUpdate(c, submissionCapLockCollection, bson.D{{Key: "_id", Value: lockID}}, increment)
+		if err != nil || doc != nil {
+			return err
+		}
+		seed := bson.D{{Key: "_id", Value: lockID}, {Key: "submissions", Value: int64(0)}}
+		if err := Storage.Insert(c, submissionCapLockCollection, seed); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: submission lock for %s could not be created", cfg.typeName, submittedBy)
+}
+
+// countSubmissions counts what submittedBy has in flight for cfg's type, taking only the counts
+// limits has a limit for.
+func (cfg entityVersioningConfig[T]) countSubmissions(c context.Context, limits *SubmissionCap, id, submittedBy string, now time.Time) (SubmissionCounts, error) {
+	var counts SubmissionCounts
+	pending := bson.D{{Key: "submitted_by", Value: submittedBy}, {Key: "state", Value: string(models.VersionStateSubmitted)}}
+	if limits.MaxPending > 0 {
+		n, err := Storage.Count(c, cfg.versionCollection, pending)
+		if err != nil {
+			return counts, err
+		}
+		counts.Pending = int(n)
+	}
+	if limits.MaxPendingPerRecord > 0 {
+		n, err := Storage.Count(c, cfg.versionCollection, append(bson.D{{Key: "record_id", Value: id}}, pending...))
+		if err != nil {
+			return counts, err
+		}
+		counts.PendingOnRecord = int(n)
+	}
+	if limits.MaxPerWindow > 0 && limits.Window > 0 {
+		// A live or archived version nobody reviewed was a live edit (or a record's first
+		// version), not a submission; one a partial accept derived is the submission it came
+		// from, already counted.
+		window := bson.D{
+			{Key: "submitted_by", Value: submittedBy},
+			{Key: "submitted_at", Value: bson.D{{Key: "$gt", Value: now.Add(-limits.Window)}}},
+			{Key: derivedFromField, Value: bson.D{{Key: "$exists", Value: false}}},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "state", Value: bson.D{{Key: "$nin", Value: bson.A{string(models.VersionStateLive), string(models.VersionStateArchived)}}}}},
+				bson.D{{Key: "reviewed_by", Value: bson.D{{Key: "$ne", Value: nil}}}},
+			}},
+		}
+		n, err := Storage.Count(c, cfg.versionCollection, window)
+		if err != nil {
+			return counts, err
+		}
+		counts.InWindow = int(n)
+	}
+	return counts, nil
+}
+
+// checkSubmissionCap returns a *CapExceededError if one more submission by submittedBy on record
+// id would break cfg's cap - createVersionTx's gate for a submitted version. Taking the
+// submitter's lock first makes the count and the insert that follows it atomic, as far as
+// Transactions allows: with transactions off, two racing submissions can both get through.
+func (cfg entityVersioningConfig[T]) checkSubmissionCap(c context.Context, id, submittedBy string) error {
+	if cfg.submissionCap == nil || !cfg.submissionCap.capped() {
+		return nil
+	}
+	limits := *cfg.submissionCap
+	if err := cfg.lockSubmitter(c, submittedBy); err != nil {
+		return err
+	}
+	counts, err := cfg.countSubmissions(c, &limits, id, submittedBy, time.Now())
+	if err != nil {
+		return err
+	}
+	exceeded := &CapExceededError{Type: cfg.typeName, ID: id, SubmittedBy: submittedBy, Counts: counts}
+	switch {
+	case limits.MaxPending > 0 && counts.Pending >= limits.MaxPending:
+		exceeded.Limit, exceeded.Max = CapPending, limits.MaxPending
+	case limits.MaxPendingPerRecord > 0 && counts.PendingOnRecord >= limits.MaxPendingPerRecord:
+		exceeded.Limit, exceeded.Max = CapPendingPerRecord, limits.MaxPendingPerRecord
+	case limits.MaxPerWindow > 0 && limits.Window > 0 && counts.InWindow >= limits.MaxPerWindow:
+		exceeded.Limit, exceeded.Max = CapPerWindow, limits.MaxPerWindow
+	default:
+		return nil
+	}
+	return exceeded
+}
@@ -0,0 +1,211 @@
+package data
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.opentelemetry.io/otel"
+)
+
+// VersionStateExpired is the state SweepStaleSubmissions leaves a submission in once it has sat
+// unreviewed too long, or its record has moved on too far for it to be worth reviewing. Like
+// rejected and withdrawn it's final: the version stays in the history but can't be accepted.
+// catalog-objects doesn't define it, so it lives here.
+const VersionStateExpired models.VersionState = "expired"
+
+// StalenessPolicy is when SweepStaleSubmissions expires a submission. The zero value expires
+// nothing.
+type StalenessPolicy struct {
+	// MaxAge expires a submission submitted longer ago than this; 0 for no age limit.
+	MaxAge time.Duration
+	// MaxDrift expires a submission once more than this many versions have gone live on its
+	// record since its base version; 0 for no drift limit.
+	MaxDrift int
+}
+
+// SubmissionStalenessPolicy is the StalenessPolicy SweepStaleSubmissions applies. Set once at
+// startup, like VolumeReferenceValidation.
+var SubmissionStalenessPolicy StalenessPolicy
+
+// StaleReason says which limit expired a submission.
+type StaleReason string
+
+const (
+	StaleTooOld  StaleReason = "too_old"
+	StaleDrifted StaleReason = "drifted"
+)
+
+// staleSweepActor is the outbox actor on an expiry.
+const staleSweepActor = "submission-sweep"
+
+// ExpiredSubmission is one submission SweepStaleSubmissions expired - enough for the caller to
+// tell its submitter. Note is the text also stored as the version's review note.
+type ExpiredSubmission struct {
+	Type        string
+	RecordID    string
+	Version     int
+	DisplayName string
+	SubmittedBy string
+	SubmittedAt time.Time
+	Reason      StaleReason
+	Note        string
+}
+
+// SweepReport is what one SweepStaleSubmissions run did: how many pending submissions it looked
+// at and which it expired, oldest first.
+type SweepReport struct {
+	Checked int
+	Expired []*ExpiredSubmission
+}
+
+// liveDrift counts, per record in items, the versions that went live after the item's base
+// version - the live one plus archived ones, which were live before it. Submissions that were
+// rejected, withdrawn or are still pending don't count.
+func (cfg entityVersioningConfig[T]) liveDrift(c context.Context, items []*PendingSubmission) (map[*PendingSubmission]int, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.RecordID)
+	}
+	filter := bson.D{
+		{Key: "record_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		{Key: "state", Value: bson.D{{Key: "$in", Value: bson.A{string(models.VersionStateLive), string(models.VersionStateArchived)}}}},
+	}
+	wentLive, err := storeQuery[pendingVersionDocument](c, cfg.versionCollection, filter, nil, nil, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query live versions: %w", cfg.typeName, err)
+	}
+	drift := make(map[*PendingSubmission]int, len(items))
+	for _, item := range items {
+		for _, version := range wentLive {
+			if version.RecordID == item.RecordID && version.Version > item.BaseVersion {
+				drift[item]++
+			}
+		}
+	}
+	return drift, nil
+}
+
+// staleSubmissions returns those of cfg's pending submissions policy expires as of now. A
+// claimed submission is left alone - someone is looking at it.
+func (cfg entityVersioningConfig[T]) staleSubmissions(c context.Context, policy StalenessPolicy, now time.Time) ([]*ExpiredSubmission, int, error) {
+	pending, err := cfg.pendingSubmissions(c, nil)
+	if err != nil || len(pending) == 0 {
+		return nil, 0, err
+	}
+	var drift map[*PendingSubmission]int
+	if policy.MaxDrift > 0 {
+		if drift, err = cfg.liveDrift(c, pending); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var stale []*PendingSubmission
+	var expired []*ExpiredSubmission
+	for _, item := range pending {
+		if item.ClaimedBy != "" {
+			continue
+		}
+		found := &ExpiredSubmission{
+			Type: cfg.typeName, RecordID: item.RecordID, Version: item.Version,
+			SubmittedBy: item.SubmittedBy, SubmittedAt: item.SubmittedAt,
+		}
+		switch {
+		case policy.MaxAge > 0 && now.Sub(item.SubmittedAt) > policy.MaxAge:
+			found.Reason = StaleTooOld
+			found.Note = fmt.Sprintf("expired: not reviewed within %s of submission", policy.MaxAge)
+		case policy.MaxDrift > 0 && drift[item] > policy.MaxDrift:
+			found.Reason = StaleDrifted
+			found.Note = fmt.Sprintf("expired: %d versions went live after version %d, which it was based on", drift[item], item.BaseVersion)
+		default:
+			continue
+		}
+		stale = append(stale, item)
+		expired = append(expired, found)
+	}
+	if err := cfg.describePending(c, stale); err != nil {
+		return nil, 0, err
+	}
+	for i, item := range stale {
+		expired[i].DisplayName = item.DisplayName
+	}
+	return expired, len(pending), nil
+}
+
+// expireSubmission moves a submitted version to VersionStateExpired with note as its review
+// note, and appends the outbox event, in one transaction. Returns false, changing nothing, if
+// the version was reviewed, withdrawn or claimed since the sweep found it.
+func (cfg entityVersioningConfig[T]) expireSubmission(c context.Context, id string, version int, note string) (bool, error) {
+	expired := false
+	err := withTransaction(c, func(tc context.Context) error {
+		now := time.Now()
+		pending, err := cfg.readPendingVersion(tc, id, version)
+		if err != nil || pending == nil || pending.State != models.VersionStateSubmitted || pending.claimant(now) != "" {
+			return err
+		}
+		filter := bson.D{{Key: "record_id", Value: id}, {Key: "version", Value: version}, {Key: "state", Value: string(models.VersionStateSubmitted)}}
+		matched, err := Storage.UpdateOne(tc, cfg.versionCollection, filter, bson.D{{Key: "$set", Value: bson.D{
+			{Key: "state", Value: string(VersionStateExpired)},
+			{Key: "reviewed_at", Value: now},
+			{Key: "review_note", Value: note},
+		}}})
+		if err != nil || matched == 0 {
+			return err
+		}
+		expired = true
+		if err := appendEvent(tc, Event{
+			Type: EventVersionExpired, Entity: cfg.typeName, RecordID: id, Version: version,
+			State: VersionStateExpired, Actor: staleSweepActor,
+		}); err != nil {
+			return err
+		}
+		return cfg.submissionDropped(tc, id)
+	})
+	return expired, err
+}
+
+// SweepStaleSubmissions expires every pending submission SubmissionStalenessPolicy says is too
+// old or too far behind its record, across all versioned types, and reports what it expired so
+// the caller can let each submitter know. Meant to run periodically; each expiry commits on its
+// own, so a failure part-way leaves the earlier ones done and the next run picks up the rest.
+// Claimed submissions and those on soft-deleted records are skipped. On an error, the report
+// returned alongside it lists what was expired before it.
+func SweepStaleSubmissions(c context.Context) (*SweepReport, error) {
+	_, span := otel.Tracer("review-queue").Start(c, "db-sweep-stale-submissions")
+	defer span.End()
+
+	policy := SubmissionStalenessPolicy
+	report := &SweepReport{Expired: []*ExpiredSubmission{}}
+	if policy.MaxAge <= 0 && policy.MaxDrift <= 0 {
+		return report, nil
+	}
+	now := time.Now()
+	for _, source := range reviewQueueSources {
+		stale, checked, err := source.staleSubmissions(c, policy, now)
+		if err != nil {
+			logging.Logger.Error("Error while querying database for stale submissions", "error", err)
+			return report, err
+		}
+		report.Checked += checked
+		for _, found := range stale {
+			expired, err := source.expireSubmission(c, found.RecordID, found.Version, found.Note)
+			if err != nil {
+				logging.Logger.Error("Error while expiring submission", "type", found.Type, "id", found.RecordID, "version", found.Version, "error", err)
+				return report, err
+			}
+			if expired {
+				report.Expired = append(report.Expired, found)
+			}
+		}
+	}
+	slices.SortFunc(report.Expired, func(a, b *ExpiredSubmission) int {
+		return cmp.Or(a.SubmittedAt.Compare(b.SubmittedAt), cmp.Compare(a.Type, b.Type), cmp.Compare(a.RecordID, b.RecordID))
+	})
+	logging.Logger.Info("Swept stale submissions", "checked", report.Checked, "expired", len(report.Expired))
+	return report, nil
+}
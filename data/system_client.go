@@ -2,6 +2,7 @@ package data
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/sweetrpg/catalog-data.go/gamesystems"
@@ -12,19 +13,23 @@ import (
 // GameSystemsClient resolves system references against gamesystems-api, the system of record
 // for game systems (see platform's game-systems-catalog spec), instead of catalog-api storing
 // its own copy. Set once at startup (see catalog-api's cmd/catalog-api/main.go); nil until then,
-// in which case GetSystem/GetSystemStats degrade to "no data" rather than panicking - matching
-// how resolveVolumeRelations already treats a missing/erroring relation as skip-and-log.
+// in which case GetSystem reports every id as not found and the list/stats reads degrade to "no
+// data" rather than panicking - matching how resolveVolumeRelations already treats a
+// missing/erroring relation as skip-and-log.
 var GameSystemsClient *gamesystems.Client
 
-// GetSystem resolves a game system by id against gamesystems-api's current (live) version.
+// GetSystem resolves a game system by id against gamesystems-api's current (live) version. A
+// gamesystems-api 404 surfaces as this package's *NotFoundError, like every other Get*, and so
+// does any id while GameSystemsClient isn't configured.
 func GetSystem(c context.Context, id string) (*vo.SystemVO, error) {
 	if GameSystemsClient == nil {
-		return nil, nil
+		return nil, &NotFoundError{Type: "system", ID: id}
 	}
 	system, err := GameSystemsClient.Get(c, id)
 	if err != nil {
-		if _, ok := err.(gamesystems.NotFoundError); ok {
-			return nil, nil
+		var notFound gamesystems.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, &NotFoundError{Type: "system", ID: notFound.ID}
 		}
 		return nil, err
 	}
@@ -40,7 +45,7 @@ func GetSystem(c context.Context, id string) (*vo.SystemVO, error) {
 
 // QuerySystems lists every live game system against gamesystems-api's current versions. Backs
 // catalog-api's /systems list route - a nil GameSystemsClient (unconfigured GAMESYSTEMS_API_URL)
-// degrades to an empty list rather than an error, matching GetSystem's fail-open convention.
+// degrades to an empty list rather than an error.
 func QuerySystems(c context.Context) ([]*vo.SystemVO, error) {
 	if GameSystemsClient == nil {
 		return []*vo.SystemVO{}, nil
@@ -63,18 +68,19 @@ func QuerySystems(c context.Context) ([]*vo.SystemVO, error) {
 	return vos, nil
 }
 
-// SearchSystems finds live game systems whose name contains query (case-insensitive) - same
-// scan-in-memory approach as data.SearchPersons, over gamesystems-api's full live list rather
-// than a Mongo collection (there is no local collection for Systems to query).
+// SearchSystems finds live game systems whose name contains query, both compared in their
+// NormalizeName form so case, accents and punctuation don't matter. It scans gamesystems-api's
+// full live list in memory - systems have no local collection, so they aren't in the
+// catalog_search index the other Search* functions use.
 func SearchSystems(c context.Context, query string) ([]*vo.SystemVO, error) {
 	all, err := QuerySystems(c)
 	if err != nil {
 		return nil, err
 	}
-	needle := strings.ToLower(query)
+	needle := NormalizeName(query)
 	matches := make([]*vo.SystemVO, 0, len(all))
 	for _, s := range all {
-		if strings.Contains(strings.ToLower(s.GameSystem), needle) {
+		if strings.Contains(NormalizeName(s.GameSystem), needle) {
 			matches = append(matches, s)
 		}
 	}
@@ -84,7 +90,7 @@ func SearchSystems(c context.Context, query string) ([]*vo.SystemVO, error) {
 // GetSystemsMap resolves every live game system in one call, keyed by ID - lets a caller
 // resolving many volumes' system references (e.g. QueryVolumes) do it with a single
 // gamesystems-api round trip instead of one per volume. Returns an empty (non-nil) map, not an
-// error, when the client isn't configured, matching GetSystem's fail-open behavior.
+// error, when the client isn't configured, like QuerySystems.
 func GetSystemsMap(c context.Context) (map[string]*vo.SystemVO, error) {
 	if GameSystemsClient == nil {
 		return map[string]*vo.SystemVO{}, nil
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransactionMode selects how this package's multi-write lifecycle operations (accept,
+// set-current, a live update, add, migrate) are made atomic - each one must preserve the
+// meta/version invariant "exactly one live version, and meta.CurrentVersion points at it", which
+// a failure between its individual writes would otherwise break.
+type TransactionMode int
+
+const (
+	// TransactionModeAuto runs each operation in a Mongo transaction, falling back to
+	// TransactionModeDisabled's plain sequential writes the first time the server reports it
+	// can't run transactions (a standalone mongod, as in local development and CI) - and for
+	// every operation after that, without re-probing.
+	TransactionModeAuto TransactionMode = iota
+	// TransactionModeRequired runs each operation in a Mongo transaction and fails it outright on
+	// a server that can't run transactions, rather than silently losing atomicity.
+	TransactionModeRequired
+	// TransactionModeDisabled never starts a transaction - each write commits on its own, as
+	// before transactions were introduced.
+	TransactionModeDisabled
+)
+
+// Transactions is the TransactionMode every lifecycle operation uses. Set once at startup (see
+// catalog-api's cmd/catalog-api/main.go), before any data function is called.
+var Transactions = TransactionModeAuto
+
+// transactionsUnsupported latches once TransactionModeAuto has seen the server reject a
+// transaction, so later operations skip straight to the fallback.
+var transactionsUnsupported atomic.Bool
+
+// illegalOperationCode is the server error code a standalone mongod answers a transaction with,
+// alongside transactionsUnsupportedMessage.
+const (
+	illegalOperationCode           = 20
+	transactionsUnsupportedMessage = "Transaction numbers are only allowed on a replica set member or mongos"
+)
+
+// isTransactionsUnsupported reports whether err is the server refusing a transaction because it
+// isn't a replica set member or mongos.
+func isTransactionsUnsupported(err error) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorCodeWithMessage(illegalOperationCode, transactionsUnsupportedMessage)
+}
+
+// withTransaction runs fn inside a Storage transaction per Transactions, passing it the context
+// every read and write it makes must use to take part. fn may be called more than once (the
+// Mongo driver retries transient transaction errors), so it must not accumulate state across
+// calls.
+func withTransaction(c context.Context, fn func(tc context.Context) error) error {
+	if Transactions == TransactionModeDisabled || (Transactions == TransactionModeAuto && transactionsUnsupported.Load()) {
+		return fn(c)
+	}
+
+	err := Storage.WithTransaction(c, fn)
+	if err != nil && Transactions == TransactionModeAuto && isTransactionsUnsupported(err) {
+		logging.Logger.Info("Mongo server doesn't support transactions, falling back to unsessioned writes")
+		transactionsUnsupported.Store(true)
+		return fn(c)
+	}
+	return err
+}
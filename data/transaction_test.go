@@ -0,0 +1,209 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransactionTestSuite checks the meta/version invariant withTransaction exists to protect -
+// exactly one live version, and meta.CurrentVersion points at it - after each multi-write
+// lifecycle operation, via publisher as the vehicle (see EntityMigrationTestSuite). It runs in
+// whatever mode the test server allows: a standalone mongod exercises the Auto fallback, a
+// replica set the transactional path.
+type TransactionTestSuite struct {
+	suite.Suite
+	seedPublisherID string
+}
+
+func (suite *TransactionTestSuite) SetupTest() {
+	setupTestStorage()
+	assert.NoError(suite.T(), EnsurePublisherVersioningIndexes(suite.T().Context()))
+
+	id, err := AddPublisher(suite.T().Context(), &vo.PublisherVO{Name: "Transactional Publisher"})
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), id)
+	suite.seedPublisherID = *id
+}
+
+// assertSingleLiveVersion asserts the invariant for the seed publisher, returning the live
+// version number.
+func (suite *TransactionTestSuite) assertSingleLiveVersion() int {
+	filter := bson.D{
+		{Key: "record_id", Value: suite.seedPublisherID},
+		{Key: "state", Value: string(models.VersionStateLive)},
+	}
+	live, err := storeQuery[models.PublisherVersion](suite.T().Context(), publisherVersionCollection, filter, nil, nil, 0, 0)
+	assert.NoError(suite.T(), err)
+	if !assert.Len(suite.T(), live, 1) {
+		return 0
+	}
+
+	meta, err := publisherVersioning.getMeta(suite.T().Context(), suite.seedPublisherID)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), meta)
+	assert.Equal(suite.T(), live[0].Version, meta.CurrentVersion)
+	return live[0].Version
+}
+
+func (suite *TransactionTestSuite) TestAddPublisherHoldsInvariant() {
+	assert.Equal(suite.T(), 1, suite.assertSingleLiveVersion())
+}
+
+func (suite *TransactionTestSuite) TestLiveUpdateHoldsInvariant() {
+	updated, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Updated Transactional Publisher",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), updated)
+	assert.Equal(suite.T(), updated.Version, suite.assertSingleLiveVersion())
+}
+
+func (suite *TransactionTestSuite) TestAcceptHoldsInvariant() {
+	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Proposed Transactional Publisher",
+	}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+
+	accepted, _, err := AcceptPublisherVersion(suite.T().Context(), suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), accepted)
+	assert.Equal(suite.T(), accepted.Version, suite.assertSingleLiveVersion())
+}
+
+func (suite *TransactionTestSuite) TestSetCurrentHoldsInvariant() {
+	_, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Second Transactional Publisher",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+
+	_, err = SetCurrentPublisherVersion(suite.T().Context(), suite.seedPublisherID, 1, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, suite.assertSingleLiveVersion())
+}
+
+func (suite *TransactionTestSuite) TestDisabledModeHoldsInvariant() {
+	previous := Transactions
+	Transactions = TransactionModeDisabled
+	defer func() { Transactions = previous }()
+
+	_, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Untransacted Publisher",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, suite.assertSingleLiveVersion())
+}
+
+// racingRepository runs race, once, just before the next compare-and-swap on a meta record's
+// current_version in metaCollection - standing in for a concurrent writer that gets there first.
+type racingRepository struct {
+	Repository
+	metaCollection string
+	race           func()
+}
+
+func (r *racingRepository) UpdateOne(c context.Context, collection string, filter, update bson.D) (int64, error) {
+	if race := r.race; race != nil && collection == r.metaCollection {
+		for _, e := range filter {
+			if e.Key == "current_version" {
+				r.race = nil
+				race()
+				break
+			}
+		}
+	}
+	return r.Repository.UpdateOne(c, collection, filter, update)
+}
+
+// raceUntransacted switches transactions off for the rest of the test and has a live update of the
+// seed publisher win the next current-version swap.
+func (suite *TransactionTestSuite) raceUntransacted() {
+	previous := Transactions
+	Transactions = TransactionModeDisabled
+	suite.T().Cleanup(func() { Transactions = previous })
+
+	inner := Storage
+	racing := &racingRepository{Repository: inner, metaCollection: publisherMetaCollection}
+	racing.race = func() {
+		_, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+			Name: "Winning Publisher",
+		}, models.VersionStateLive, nil)
+		assert.NoError(suite.T(), err)
+	}
+	Storage = racing
+	suite.T().Cleanup(func() { Storage = inner })
+}
+
+func (suite *TransactionTestSuite) TestUntransactedLiveUpdateLosingRaceLeavesNothingBehind() {
+	suite.raceUntransacted()
+
+	updated, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Losing Publisher",
+	}, models.VersionStateLive, nil)
+	assert.Nil(suite.T(), updated)
+	var conflict *ConflictError
+	assert.ErrorAs(suite.T(), err, &conflict)
+
+	// The loser allocated version 2 and the winner 3; the loser's insert is gone again.
+	assert.Equal(suite.T(), 3, suite.assertSingleLiveVersion())
+	_, err = GetPublisherVersion(suite.T().Context(), suite.seedPublisherID, 2)
+	assert.ErrorIs(suite.T(), err, ErrVersionNotFound)
+}
+
+func (suite *TransactionTestSuite) TestUntransactedAcceptLosingRaceLeavesSubmissionPending() {
+	submitted, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Proposed Publisher",
+	}, models.VersionStateSubmitted, nil)
+	assert.NoError(suite.T(), err)
+	suite.raceUntransacted()
+
+	accepted, _, err := AcceptPublisherVersion(suite.T().Context(), suite.seedPublisherID, submitted.Version, nil, nil, "editor-1", nil, nil)
+	assert.Nil(suite.T(), accepted)
+	var conflict *ConflictError
+	assert.ErrorAs(suite.T(), err, &conflict)
+
+	assert.Equal(suite.T(), 3, suite.assertSingleLiveVersion())
+	version, err := GetPublisherVersion(suite.T().Context(), suite.seedPublisherID, submitted.Version)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.VersionStateSubmitted, models.VersionState(version.State))
+}
+
+func (suite *TransactionTestSuite) TestUntransactedSetCurrentLosingRaceRestoresTarget() {
+	_, err := UpdatePublisher(suite.T().Context(), suite.seedPublisherID, &vo.PublisherVO{
+		Name: "Second Transactional Publisher",
+	}, models.VersionStateLive, nil)
+	assert.NoError(suite.T(), err)
+	suite.raceUntransacted()
+
+	rolledBack, err := SetCurrentPublisherVersion(suite.T().Context(), suite.seedPublisherID, 1, nil)
+	assert.Nil(suite.T(), rolledBack)
+	var conflict *ConflictError
+	assert.ErrorAs(suite.T(), err, &conflict)
+
+	assert.Equal(suite.T(), 3, suite.assertSingleLiveVersion())
+	version, err := GetPublisherVersion(suite.T().Context(), suite.seedPublisherID, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.VersionStateArchived, models.VersionState(version.State))
+}
+
+func (suite *TransactionTestSuite) TestIsTransactionsUnsupported() {
+	standalone := mongo.CommandError{Code: illegalOperationCode, Message: transactionsUnsupportedMessage}
+	assert.True(suite.T(), isTransactionsUnsupported(standalone))
+	assert.True(suite.T(), isTransactionsUnsupported(errors.Join(errors.New("wrapped"), standalone)))
+
+	otherIllegal := mongo.CommandError{Code: illegalOperationCode, Message: "something else"}
+	assert.False(suite.T(), isTransactionsUnsupported(otherIllegal))
+	assert.False(suite.T(), isTransactionsUnsupported(errors.New("boom")))
+	assert.False(suite.T(), isTransactionsUnsupported(nil))
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionTestSuite))
+}
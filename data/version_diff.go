@@ -0,0 +1,134 @@
+package data
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+)
+
+// FieldChange is one substantive field whose value differs between two versions. Old and New
+// are the stored model values (e.g. []modelcore.Tag, or a volume's relation id slices), not
+// VOs.
+type FieldChange struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// VersionDiff is the field-by-field difference from one version of a record to another, in
+// field-name order. Only fields a submission can change are compared - never the lifecycle
+// bookkeeping (state, submitted_by, ...).
+type VersionDiff struct {
+	Type        string // entity type, e.g. "publisher"
+	ID          string
+	FromVersion int
+	ToVersion   int
+	Changes     []FieldChange
+}
+
+// SubmissionDiff is what a reviewer needs to see before accepting a submitted version: what the
+// submitter changed relative to the version they edited (Proposed), what accepting it would
+// change relative to today's current version (AgainstCurrent), and which proposed fields a full
+// accept without resolutions would exclude as conflicts (Conflicts, sorted) - those the current
+// version has itself moved off the base value on since, less the list fields accept set-merges.
+// Conflicts is empty whenever BaseVersion is still the current version.
+type SubmissionDiff struct {
+	Type           string
+	ID             string
+	Version        int
+	BaseVersion    int
+	CurrentVersion int
+	Proposed       *VersionDiff
+	AgainstCurrent *VersionDiff
+	Conflicts      []string
+}
+
+// diffFields compares every substantive field of from and to.
+func (cfg entityVersioningConfig[T]) diffFields(from, to *T) []FieldChange {
+	changes := make([]FieldChange, 0)
+	for _, field := range cfg.changedFields(to, from) {
+		changes = append(changes, FieldChange{Field: field, Old: cfg.fieldValue(from, field), New: cfg.fieldValue(to, field)})
+	}
+	return changes
+}
+
+// wouldConflict reports whether acceptVersion would refuse to apply field from a submission
+// based on base, because current's value has diverged from base's since.
+func (cfg entityVersioningConfig[T]) wouldConflict(field string, base, current *T) bool {
+	return !reflect.DeepEqual(cfg.fieldValue(current, field), cfg.fieldValue(base, field))
+}
+
+// diffVersions compares two arbitrary versions of one record.
+func (cfg entityVersioningConfig[T]) diffVersions(c context.Context, id string, from, to int) (*VersionDiff, error) {
+	fromVersion, err := cfg.requireVersion(c, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := cfg.requireVersion(c, id, to)
+	if err != nil {
+		return nil, err
+	}
+	return &VersionDiff{Type: cfg.typeName, ID: id, FromVersion: from, ToVersion: to, Changes: cfg.diffFields(fromVersion, toVersion)}, nil
+}
+
+// diffSubmission builds a submitted version's SubmissionDiff, working its conflicts out with the
+// same mergeFields acceptVersion applies.
+func (cfg entityVersioningConfig[T]) diffSubmission(c context.Context, id string, version int) (*SubmissionDiff, error) {
+	submitted, err := cfg.requireVersion(c, id, version)
+	if err != nil {
+		return nil, err
+	}
+	lc := cfg.lifecycle(submitted)
+	if lc.State != models.VersionStateSubmitted {
+		return nil, &InvalidStateError{Type: cfg.typeName, ID: id, Version: version, State: lc.State}
+	}
+	if lc.BaseVersion == nil {
+		return nil, &NoBaseVersionError{Type: cfg.typeName, ID: id, Version: version}
+	}
+
+	meta, current, err := cfg.getCurrent(c, id)
+	if err != nil {
+		return nil, err
+	}
+	base, err := cfg.requireVersion(c, id, *lc.BaseVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SubmissionDiff{
+		Type: cfg.typeName, ID: id, Version: version,
+		BaseVersion: *lc.BaseVersion, CurrentVersion: meta.CurrentVersion,
+		Proposed:       &VersionDiff{Type: cfg.typeName, ID: id, FromVersion: *lc.BaseVersion, ToVersion: version, Changes: cfg.diffFields(base, submitted)},
+		AgainstCurrent: &VersionDiff{Type: cfg.typeName, ID: id, FromVersion: meta.CurrentVersion, ToVersion: version, Changes: cfg.diffFields(current, submitted)},
+		Conflicts:      make([]string, 0),
+	}
+	derived := *current
+	_, conflicts := cfg.mergeFields(&derived, cfg.changedFields(submitted, base), nil, base, current, submitted)
+	diff.Conflicts = append(diff.Conflicts, conflicts...)
+	return diff, nil
+}
+
+// AcceptPreview is a dry run of an Accept*Version call: Version is the VO that call would
+// return, Accepted the fields it would take from the submission, wholly or merged, and Conflicts
+// the fields it would exclude (both sorted; a field resolved ResolveKeepCurrent is in neither).
+// Version is the submitted version itself for a clean full accept;
+// otherwise it's the derived version, which has no id and a zero version number until the real
+// accept allocates them. CurrentVersion is the record's current version the preview was worked
+// out against - pass it as the real accept's expectedCurrentVersion to refuse a record that has
+// moved on since.
+type AcceptPreview[V any] struct {
+	Version        *V
+	Accepted       []string
+	Conflicts      []string
+	CurrentVersion int
+}
+
+// newAcceptPreview turns an accept plan into its AcceptPreview, converting the would-be live
+// version with toVO.
+func newAcceptPreview[T, V any](plan *acceptPlan[T], toVO func(*T) *V) *AcceptPreview[V] {
+	return &AcceptPreview[V]{
+		Version: toVO(plan.live), Accepted: plan.accepted, Conflicts: plan.conflicts,
+		CurrentVersion: plan.meta.CurrentVersion,
+	}
+}
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"reflect"
+	"sort"
+
+	modelcore "github.com/sweetrpg/model-core.go/models"
+)
+
+// ResolutionStrategy is how a reviewer settles one conflicting field on accept - a field the
+// submission changed whose current value has also moved off the submission's base since.
+type ResolutionStrategy string
+
+const (
+	// ResolveTakeSubmitted applies the submission's value, overwriting the current one.
+	ResolveTakeSubmitted ResolutionStrategy = "take_submitted"
+	// ResolveKeepCurrent keeps the current value - what an unresolved conflict does anyway,
+	// stated explicitly.
+	ResolveKeepCurrent ResolutionStrategy = "keep_current"
+	// ResolveMerged applies a hand-merged Value, which must have the field's own type (e.g.
+	// []string for a volume's system_ids).
+	ResolveMerged ResolutionStrategy = "merged"
+)
+
+// ConflictResolution settles one conflicting field; see ResolutionStrategy. Value is only read
+// for ResolveMerged.
+type ConflictResolution struct {
+	Strategy ResolutionStrategy
+	Value    any
+}
+
+// checkResolutions validates resolutions (field name -> resolution) up front, so a bad entry
+// fails the accept before anything is worked out: every field must be one of cfg's substantive
+// fields, every strategy known, and every merged value of the field's type. It doesn't require
+// the field to actually conflict - a resolution for a field that turns out not to is ignored.
+func (cfg entityVersioningConfig[T]) checkResolutions(id string, resolutions map[string]ConflictResolution) error {
+	fields := make([]string, 0, len(resolutions))
+	for field := range resolutions {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var zero T
+	for _, field := range fields {
+		resolution := resolutions[field]
+		if _, ok := cfg.fields[field]; !ok {
+			return &ResolutionError{Type: cfg.typeName, ID: id, Field: field, Reason: "not a field of this type"}
+		}
+		switch resolution.Strategy {
+		case ResolveTakeSubmitted, ResolveKeepCurrent:
+		case ResolveMerged:
+			want := reflect.TypeOf(cfg.fieldValue(&zero, field))
+			if resolution.Value == nil || reflect.TypeOf(resolution.Value) != want {
+				return &ResolutionError{Type: cfg.typeName, ID: id, Field: field, Reason: "merged value must be a " + want.String()}
+			}
+		default:
+			return &ResolutionError{Type: cfg.typeName, ID: id, Field: field, Reason: "unknown strategy " + string(resolution.Strategy)}
+		}
+	}
+	return nil
+}
+
+// resolveConflict settles a conflicting field, returning the value it should go live with and
+// whether that value came (wholly or in part) from the submission. ok is false when the field
+// stays a conflict: no resolution was given and the field can't be set-merged. An explicit
+// resolution always wins over the automatic set-merge.
+func (cfg entityVersioningConfig[T]) resolveConflict(field string, resolution *ConflictResolution, base, current, submitted *T) (value any, fromSubmission bool, ok bool) {
+	if resolution != nil {
+		switch resolution.Strategy {
+		case ResolveTakeSubmitted:
+			return cfg.fieldValue(submitted, field), true, true
+		case ResolveKeepCurrent:
+			return cfg.fieldValue(current, field), false, true
+		case ResolveMerged:
+			return resolution.Value, true, true
+		}
+	}
+	if cfg.fields[field].setMerge {
+		return setMerge(cfg.fieldValue(base, field), cfg.fieldValue(current, field), cfg.fieldValue(submitted, field)), true, true
+	}
+	return nil, false, false
+}
+
+// mergeKey is what setMerge matches list elements on: a tag or property by its name, so one whose
+// value was edited is the same element on both sides; anything else (the relation id lists) by
+// the whole value.
+func mergeKey(elem any) any {
+	switch e := elem.(type) {
+	case modelcore.Tag:
+		return e.Name
+	case modelcore.Property:
+		return e.Name
+	}
+	return elem
+}
+
+// setMerge three-way merges a list field as a set of elements matched by mergeKey: it keeps
+// current's elements in order, drops those the submission removed relative to base, takes the
+// submission's value for those it edited, then appends those the submission added, in the
+// submission's order. An element both sides edited goes live with the submission's value. base,
+// current and submitted must be slices of one type.
+func setMerge(base, current, submitted any) any {
+	b, cur, sub := reflect.ValueOf(base), reflect.ValueOf(current), reflect.ValueOf(submitted)
+	find := func(list reflect.Value, key any) (any, bool) {
+		for i := 0; i < list.Len(); i++ {
+			if elem := list.Index(i).Interface(); reflect.DeepEqual(mergeKey(elem), key) {
+				return elem, true
+			}
+		}
+		return nil, false
+	}
+
+	merged := reflect.MakeSlice(cur.Type(), 0, cur.Len()+sub.Len())
+	for i := 0; i < cur.Len(); i++ {
+		elem := cur.Index(i).Interface()
+		key := mergeKey(elem)
+		baseElem, inBase := find(b, key)
+		subElem, inSub := find(sub, key)
+		switch {
+		case inBase && !inSub:
+			continue
+		case inSub && (!inBase || !reflect.DeepEqual(subElem, baseElem)):
+			merged = reflect.Append(merged, reflect.ValueOf(subElem))
+		default:
+			merged = reflect.Append(merged, reflect.ValueOf(elem))
+		}
+	}
+	for i := 0; i < sub.Len(); i++ {
+		elem := sub.Index(i).Interface()
+		key := mergeKey(elem)
+		if _, inBase := find(b, key); inBase {
+			continue
+		}
+		if _, inMerged := find(merged, key); !inMerged {
+			merged = reflect.Append(merged, reflect.ValueOf(elem))
+		}
+	}
+	if merged.Len() == 0 {
+		return reflect.Zero(cur.Type()).Interface()
+	}
+	return merged.Interface()
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,61 +13,57 @@ import (
 	"github.com/sweetrpg/common.go/logging"
 	modelcoreutil "github.com/sweetrpg/model-core.go/util"
 	modelcorevo "github.com/sweetrpg/model-core.go/vo"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-// AddVolume creates a volume's meta record and its first (live) version.
+// AddVolume creates a volume's meta record and its first (live) version. Its relation ids are
+// checked per VolumeReferenceValidation first.
 func AddVolume(c context.Context, volume *vo.VolumeVO) (*string, error) {
 	logging.Logger.Info("AddVolume", "c", c, "volume", volume)
 
 	_, span := otel.Tracer("volume").Start(c, "db-add-volume", oteltrace.WithAttributes())
 	defer span.End()
 
-	now := time.Now()
-	metaID := primitive.NewObjectID().Hex()
-	meta := models.VolumeMeta{
-		ID:             metaID,
-		CurrentVersion: 1,
-		CreatedAt:      now,
-		CreatedBy:      volume.CreatedBy,
-	}
-	if _, err := database.Insert[models.VolumeMeta](volumeMetaCollection, meta); err != nil {
-		logging.Logger.Error("Error while inserting VolumeMeta object", "error", err)
+	version := volumeVOToVersionFields(volume)
+	if err := validateVolumeReferences(c, "", &version, nil); err != nil {
 		return nil, err
 	}
-
-	version := volumeVOToVersionFields(volume)
-	version.ID = primitive.NewObjectID().Hex()
-	version.RecordID = metaID
-	version.Version = 1
-	version.State = models.VersionStateLive
-	version.BaseVersion = nil
-	version.SubmittedBy = volume.CreatedBy
-	version.SubmittedAt = now
-	if _, err := database.Insert[models.VolumeVersion](volumeVersionCollection, version); err != nil {
-		logging.Logger.Error("Error while inserting VolumeVersion object", "error", err)
+	id, err := volumeVersioning.addEntity(c, &version, volume.CreatedBy)
+	if err != nil {
+		logging.Logger.Error("Error while inserting Volume", "error", err)
 		return nil, err
 	}
-
-	return &metaID, nil
+	return id, nil
 }
 
 // UpdateVolume creates a new version of the volume rather than mutating the record in place.
 // For state VersionStateLive, the new version becomes current immediately and the previously
 // current version is archived; any other state (VersionStateSubmitted) leaves the current
-// pointer untouched. Returns the created version, or nil if the record doesn't exist.
-func UpdateVolume(c context.Context, id string, volume *vo.VolumeVO, state models.VersionState) (*vo.VolumeVersionVO, error) {
+// pointer untouched. Returns the created version, or a *NotFoundError if the record doesn't
+// exist.
+//
+// expectedCurrentVersion is an optional optimistic-concurrency precondition (nil skips it): the
+// current version the caller based its edit on. If another write has moved the record's current
+// version since, UpdateVolume writes nothing and returns a *ConflictError. The same parameter
+// on AcceptVolumeVersion/SetCurrentVolumeVersion and every other type's Update*/Accept*/
+// SetCurrent* behaves identically.
+//
+// As with AddVolume, relation ids are checked per VolumeReferenceValidation before anything is
+// written - those the current version doesn't already carry, so an id that has since gone stale
+// doesn't block an unrelated edit.
+func UpdateVolume(c context.Context, id string, volume *vo.VolumeVO, state models.VersionState, expectedCurrentVersion *int) (*vo.VolumeVersionVO, error) {
 	logging.Logger.Info("UpdateVolume", "c", c, "id", id, "volume", volume, "state", state)
 
 	_, span := otel.Tracer("volume").Start(c, "db-update-volume", oteltrace.WithAttributes(attribute.String("id", id)))
 	defer span.End()
 
-	return createVolumeVersion(c, id, volume, state, volume.UpdatedBy, time.Now(), nil, nil)
+	if err := validateVolumeVOReferences(c, id, volume); err != nil {
+		return nil, err
+	}
+	return createVolumeVersion(c, id, volume, state, volume.UpdatedBy, time.Now(), nil, nil, expectedCurrentVersion)
 }
 
 // CreateSubmittedVolumeVersion creates a submitted version stamped with a caller-supplied
@@ -74,7 +71,7 @@ func UpdateVolume(c context.Context, id string, volume *vo.VolumeVO, state model
 // version model - unlike UpdateVolume, which always stamps "now", this preserves the original
 // proposal's submission audit per design.md's Migration Plan.
 func CreateSubmittedVolumeVersion(c context.Context, id string, volume *vo.VolumeVO, submittedBy string, submittedAt time.Time) (*vo.VolumeVersionVO, error) {
-	return createVolumeVersion(c, id, volume, models.VersionStateSubmitted, submittedBy, submittedAt, nil, nil)
+	return createVolumeVersion(c, id, volume, models.VersionStateSubmitted, submittedBy, submittedAt, nil, nil, nil)
 }
 
 // CreateSubmittedVolumeVersionWithStagedAssets is CreateSubmittedVolumeVersion's edit-session
@@ -83,85 +80,52 @@ func CreateSubmittedVolumeVersion(c context.Context, id string, volume *vo.Volum
 // the current live values (nothing has been promoted yet); stagedCoverAssetId/
 // stagedSampleAssetIds carry the session's not-yet-promoted uploads instead.
 func CreateSubmittedVolumeVersionWithStagedAssets(c context.Context, id string, volume *vo.VolumeVO, submittedBy string, stagedCoverAssetId *string, stagedSampleAssetIds []string) (*vo.VolumeVersionVO, error) {
-	return createVolumeVersion(c, id, volume, models.VersionStateSubmitted, submittedBy, time.Now(), stagedCoverAssetId, stagedSampleAssetIds)
+	return createVolumeVersion(c, id, volume, models.VersionStateSubmitted, submittedBy, time.Now(), stagedCoverAssetId, stagedSampleAssetIds, nil)
 }
 
-func createVolumeVersion(c context.Context, id string, volume *vo.VolumeVO, state models.VersionState, submittedBy string, submittedAt time.Time, stagedCoverAssetId *string, stagedSampleAssetIds []string) (*vo.VolumeVersionVO, error) {
-	meta, err := getVolumeMeta(c, id)
-	if err != nil {
-		logging.Logger.Error("Error while looking up VolumeMeta for update", "id", id, "error", err)
-		return nil, err
-	}
-	if meta == nil {
-		logging.Logger.Info(fmt.Sprintf("Volume not found for update, ID: %s", id))
-		return nil, nil
-	}
-
-	nextVersion, err := nextVolumeVersionNumber(c, id)
-	if err != nil {
-		return nil, err
-	}
-
-	baseVersion := meta.CurrentVersion
+func createVolumeVersion(c context.Context, id string, volume *vo.VolumeVO, state models.VersionState, submittedBy string, submittedAt time.Time, stagedCoverAssetId *string, stagedSampleAssetIds []string, expectedCurrentVersion *int) (*vo.VolumeVersionVO, error) {
 	newVersion := volumeVOToVersionFields(volume)
-	newVersion.ID = primitive.NewObjectID().Hex()
-	newVersion.RecordID = id
-	newVersion.Version = nextVersion
-	newVersion.State = state
-	newVersion.BaseVersion = &baseVersion
-	newVersion.SubmittedBy = submittedBy
-	newVersion.SubmittedAt = submittedAt
 	newVersion.StagedCoverAssetId = stagedCoverAssetId
 	newVersion.StagedSampleAssetIds = stagedSampleAssetIds
 
-	if _, err := database.Insert[models.VolumeVersion](volumeVersionCollection, newVersion); err != nil {
-		logging.Logger.Error("Error while inserting VolumeVersion object", "error", err)
+	result, err := volumeVersioning.createVersionWithSubmission(c, id, &newVersion, state, submittedBy, submittedAt, expectedCurrentVersion)
+	if errors.Is(err, ErrNotFound) {
+		logging.Logger.Info(fmt.Sprintf("Volume not found for update, ID: %s", id))
 		return nil, err
 	}
-
-	if state == models.VersionStateLive {
-		if err := archiveVolumeVersion(c, id, meta.CurrentVersion); err != nil {
-			return nil, err
-		}
-		if err := setVolumeMetaCurrentVersion(c, id, nextVersion); err != nil {
-			return nil, err
-		}
+	if err != nil {
+		logging.Logger.Error("Error while creating VolumeVersion", "id", id, "error", err)
+		return nil, err
 	}
-
-	return volumeVersionModelToVO(c, &newVersion), nil
+	return volumeVersionModelToVO(c, result), nil
 }
 
-func DeleteVolume(c context.Context, id string) error {
+// DeleteVolume hard-deletes a volume - its meta record and its whole version history - unlike
+// SoftDeleteVolume, which only hides it. policy decides what happens to the volume's
+// contributions and reviews: CascadeRefuse fails with a *DependencyError listing them,
+// CascadeDelete deletes them with the volume, CascadeOrphan leaves them marked orphaned_at. A
+// deleted credit goes with its whole version history, and unless orphaning, so do credits that
+// were removed or only ever proposed. Returns a *NotFoundError if the volume doesn't exist.
+func DeleteVolume(c context.Context, id string, policy CascadePolicy) error {
 	_, span := otel.Tracer("volume").Start(c, "db-delete-volume", oteltrace.WithAttributes(attribute.String("id", id)))
+	defer span.End()
 
-	// TODO
-
-	span.End()
-
+	if err := volumeVersioning.purge(c, id, policy); err != nil {
+		logging.Logger.Error("Error while deleting Volume", "id", id, "error", err)
+		return err
+	}
 	return nil
 }
 
 // SoftDeleteVolume sets the volume meta record's deleted_at/deleted_by, hiding it from
-// QueryVolumes without touching its version history - Volume's own non-generic path mirroring
-// entityVersioningConfig.softDelete, since Volume isn't on the shared engine (see design.md).
+// QueryVolumes without touching its version history.
 func SoftDeleteVolume(c context.Context, id string, deletedBy string) error {
-	now := time.Now()
-	_, err := database.Db.Collection(volumeMetaCollection).UpdateOne(
-		c,
-		bson.D{{Key: "_id", Value: id}},
-		bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: now}, {Key: "deleted_by", Value: deletedBy}}}},
-	)
-	return err
+	return volumeVersioning.softDelete(c, id, deletedBy)
 }
 
 // RestoreVolume clears a soft-deleted volume's deletion, returning it to QueryVolumes.
 func RestoreVolume(c context.Context, id string) error {
-	_, err := database.Db.Collection(volumeMetaCollection).UpdateOne(
-		c,
-		bson.D{{Key: "_id", Value: id}},
-		bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: nil}, {Key: "deleted_by", Value: nil}}}},
-	)
-	return err
+	return volumeVersioning.restore(c, id)
 }
 
 // GetVolume returns the flattened view of a volume - its meta record merged with its current
@@ -170,28 +134,17 @@ func GetVolume(c context.Context, id string) (*vo.VolumeVO, error) {
 	_, span := otel.Tracer("volume").Start(c, "db-get-volume", oteltrace.WithAttributes(attribute.String("id", id)))
 	defer span.End()
 
-	meta, err := getVolumeMeta(c, id)
-	if err != nil {
-		logging.Logger.Error(fmt.Sprintf("Error while querying database for VolumeMeta: %+v", err))
-		return nil, err
-	}
-	if meta == nil {
+	meta, version, err := volumeVersioning.getCurrent(c, id)
+	if errors.Is(err, ErrNotFound) {
 		logging.Logger.Info(fmt.Sprintf("Volume not found for ID: %s", id))
-		return nil, nil
-	}
-
-	version, err := getVolumeVersion(c, id, meta.CurrentVersion)
-	if err != nil {
-		logging.Logger.Error(fmt.Sprintf("Error while querying database for VolumeVersion: %+v", err))
 		return nil, err
 	}
-	if version == nil {
-		err := fmt.Errorf("volume %s: meta points at missing current version %d", id, meta.CurrentVersion)
-		logging.Logger.Error(err.Error())
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while querying database for Volume: %+v", err))
 		return nil, err
 	}
 
-	return flattenVolume(c, meta, version, nil), nil
+	return flattenVolume(c, meta, version, loadVolumeRelations(c, []*models.VolumeVersion{version})), nil
 }
 
 // relationIDs extracts each element's ID from a pointer-slice relationship field - the
@@ -208,72 +161,37 @@ func relationIDs[T any](relations []*T, id func(*T) string) []string {
 	return ids
 }
 
-// resolveVolumeRelations resolves a volume's relationship IDs into their VOs. systemsMap, when
-// non-nil, is used as a pre-fetched id->system lookup instead of one gamesystems-api call per
-// id - callers resolving many volumes at once (QueryVolumes) build it once via GetSystemsMap and
-// share it across every volume; callers resolving a single volume pass nil and fall back to
-// GetSystem's per-id call, which is cheap enough at that scale.
-func resolveVolumeRelations(c context.Context, systemIds, publisherIds, studioIds, licenseIds []string, systemsMap map[string]*vo.SystemVO) (
+// resolveVolumeRelations picks a volume version's relations out of relations (see
+// loadVolumeRelations), in the version's own id order.
+func resolveVolumeRelations(c context.Context, version *models.VolumeVersion, relations *volumeRelations) (
 	systems []*vo.SystemVO, publishers []*vo.PublisherVO, studios []*vo.StudioVO, licenses []*vo.LicenseVO,
 ) {
-	systems = make([]*vo.SystemVO, 0, len(systemIds))
-	for _, id := range systemIds {
-		if systemsMap != nil {
-			if system, ok := systemsMap[id]; ok {
-				systems = append(systems, system)
-			}
-			continue
-		}
-		system, err := GetSystem(c, id)
-		if err != nil {
-			logging.Logger.Error(fmt.Sprintf("No System found from Volume for ID %s: %s", id, err.Error()))
-			continue
-		}
-		if system != nil {
-			systems = append(systems, system)
-		}
-	}
-	publishers = make([]*vo.PublisherVO, 0, len(publisherIds))
-	for _, id := range publisherIds {
-		publisher, err := GetPublisher(c, id)
-		if err != nil {
-			logging.Logger.Error(fmt.Sprintf("No Publisher found from Volume for ID %s: %s", id, err.Error()))
-			continue
-		}
-		if publisher != nil {
-			publishers = append(publishers, publisher)
-		}
-	}
-	studios = make([]*vo.StudioVO, 0, len(studioIds))
-	for _, id := range studioIds {
-		studio, err := GetStudio(c, id)
-		if err != nil {
-			logging.Logger.Error(fmt.Sprintf("No Studio found from Volume for ID %s: %s", id, err.Error()))
-			continue
-		}
-		if studio != nil {
-			studios = append(studios, studio)
-		}
-	}
-	licenses = make([]*vo.LicenseVO, 0, len(licenseIds))
-	for _, id := range licenseIds {
-		license, err := GetLicense(c, id)
-		if err != nil {
-			logging.Logger.Error(fmt.Sprintf("No License found from Volume for ID %s: %s", id, err.Error()))
+	systems = pickRelations("System", version.SystemIds, relations.systems)
+	publishers = pickRelations("Publisher", version.PublisherIds, relations.publishers)
+	studios = pickRelations("Studio", version.StudioIds, relations.studios)
+	licenses = pickRelations("License", version.LicenseIds, relations.licenses)
+	return
+}
+
+// pickRelations looks each of ids up in resolved, logging and skipping any that didn't resolve.
+func pickRelations[V any](kind string, ids []string, resolved map[string]*V) []*V {
+	out := make([]*V, 0, len(ids))
+	for _, id := range ids {
+		v, ok := resolved[id]
+		if !ok {
+			logging.Logger.Error(fmt.Sprintf("No %s found from Volume for ID %s", kind, id))
 			continue
 		}
-		if license != nil {
-			licenses = append(licenses, license)
-		}
+		out = append(out, v)
 	}
-	return
+	return out
 }
 
 // flattenVolume merges a meta record with its current version into the pre-versioning VolumeVO
 // shape: creation/deletion audit comes from meta, the "last updated" audit comes from the
 // version's own submission audit (its most recent live edit).
-func flattenVolume(c context.Context, meta *models.VolumeMeta, version *models.VolumeVersion, systemsMap map[string]*vo.SystemVO) *vo.VolumeVO {
-	systems, publishers, studios, licenses := resolveVolumeRelations(c, version.SystemIds, version.PublisherIds, version.StudioIds, version.LicenseIds, systemsMap)
+func flattenVolume(c context.Context, meta *models.EntityMeta, version *models.VolumeVersion, relations *volumeRelations) *vo.VolumeVO {
+	systems, publishers, studios, licenses := resolveVolumeRelations(c, version, relations)
 
 	return &vo.VolumeVO{
 		ID:             meta.ID,
@@ -303,7 +221,22 @@ func flattenVolume(c context.Context, meta *models.VolumeMeta, version *models.V
 // volumeVersionModelToVO converts a version record on its own (no meta) into the version-history
 // API shape, resolving its relationship IDs the same way a flattened read does.
 func volumeVersionModelToVO(c context.Context, version *models.VolumeVersion) *vo.VolumeVersionVO {
-	systems, publishers, studios, licenses := resolveVolumeRelations(c, version.SystemIds, version.PublisherIds, version.StudioIds, version.LicenseIds, nil)
+	return volumeVersionModelsToVOs(c, []*models.VolumeVersion{version})[0]
+}
+
+// volumeVersionModelsToVOs is volumeVersionModelToVO for a whole version history, with every
+// relation across it resolved together.
+func volumeVersionModelsToVOs(c context.Context, versions []*models.VolumeVersion) []*vo.VolumeVersionVO {
+	relations := loadVolumeRelations(c, versions)
+	vos := make([]*vo.VolumeVersionVO, 0, len(versions))
+	for _, version := range versions {
+		vos = append(vos, volumeVersionToVO(c, version, relations))
+	}
+	return vos
+}
+
+func volumeVersionToVO(c context.Context, version *models.VolumeVersion, relations *volumeRelations) *vo.VolumeVersionVO {
+	systems, publishers, studios, licenses := resolveVolumeRelations(c, version, relations)
 
 	return &vo.VolumeVersionVO{
 		ID:                   version.ID,
@@ -356,15 +289,83 @@ func volumeVOToVersionFields(volume *vo.VolumeVO) models.VolumeVersion {
 
 // QueryVolumes lists the current (live) version of every volume matching params - the live
 // version's data is exactly today's flat volume shape, since exactly one version per record is
-// ever live at a time.
+// ever live at a time. Besides the version fields, params can sort by RatingMeanField or
+// RatingCountField; volumes never rated sort as if those were null.
 func QueryVolumes(c context.Context, params apiutil.QueryParams) ([]*vo.VolumeVO, error) {
 	logging.Logger.Info("QueryVolumes", "c", c, "params", params)
 
 	span := tracing.BuildSpanWithParams(c, "volumes", "db-get-volumes", params)
 	defer span.End()
 
+	return queryLiveVolumes(c, params, nil)
+}
+
+// QueryVolumesByPublisher lists the live volumes crediting publisherID, paged and sorted like
+// QueryVolumes. A soft-deleted publisher lists nothing, the same as it's hidden from
+// QueryPublishers; a missing one is a *NotFoundError.
+func QueryVolumesByPublisher(c context.Context, publisherID string, params apiutil.QueryParams) ([]*vo.VolumeVO, error) {
+	logging.Logger.Info("QueryVolumesByPublisher", "c", c, "publisherId", publisherID, "params", params)
+
+	span := tracing.BuildSpanWithParams(c, "volumes", "db-get-volumes-by-publisher", params)
+	defer span.End()
+
+	return queryVolumesByRelation(c, publisherVersioning.requireMeta, "publisher_ids", publisherID, params)
+}
+
+// QueryVolumesByStudio is QueryVolumesByPublisher for a studio.
+func QueryVolumesByStudio(c context.Context, studioID string, params apiutil.QueryParams) ([]*vo.VolumeVO, error) {
+	logging.Logger.Info("QueryVolumesByStudio", "c", c, "studioId", studioID, "params", params)
+
+	span := tracing.BuildSpanWithParams(c, "volumes", "db-get-volumes-by-studio", params)
+	defer span.End()
+
+	return queryVolumesByRelation(c, studioVersioning.requireMeta, "studio_ids", studioID, params)
+}
+
+// QueryVolumesByLicense is QueryVolumesByPublisher for a license.
+func QueryVolumesByLicense(c context.Context, licenseID string, params apiutil.QueryParams) ([]*vo.VolumeVO, error) {
+	logging.Logger.Info("QueryVolumesByLicense", "c", c, "licenseId", licenseID, "params", params)
+
+	span := tracing.BuildSpanWithParams(c, "volumes", "db-get-volumes-by-license", params)
+	defer span.End()
+
+	return queryVolumesByRelation(c, licenseVersioning.requireMeta, "license_ids", licenseID, params)
+}
+
+// QueryVolumesBySystem lists the live volumes for systemID. Systems live in gamesystems-api, so
+// unlike the other QueryVolumesBy* functions the id isn't checked first - an unknown system
+// simply lists nothing.
+func QueryVolumesBySystem(c context.Context, systemID string, params apiutil.QueryParams) ([]*vo.VolumeVO, error) {
+	logging.Logger.Info("QueryVolumesBySystem", "c", c, "systemId", systemID, "params", params)
+
+	span := tracing.BuildSpanWithParams(c, "volumes", "db-get-volumes-by-system", params)
+	defer span.End()
+
+	return queryVolumesByRelation(c, nil, "system_ids", systemID, params)
+}
+
+// queryVolumesByRelation runs queryLiveVolumes restricted to versions listing id in field, after
+// checking id against requireMeta (nil to skip the check).
+func queryVolumesByRelation(c context.Context, requireMeta func(context.Context, string) (*models.EntityMeta, error), field, id string, params apiutil.QueryParams) ([]*vo.VolumeVO, error) {
+	if requireMeta != nil {
+		meta, err := requireMeta(c, id)
+		if err != nil {
+			logging.Logger.Info(fmt.Sprintf("Volumes not listed for %s %s: %v", field, id, err))
+			return nil, err
+		}
+		if meta.DeletedAt != nil {
+			return []*vo.VolumeVO{}, nil
+		}
+	}
+	return queryLiveVolumes(c, params, bson.D{{Key: field, Value: id}})
+}
+
+// queryLiveVolumes is the body of every volume list query: the live versions matching params
+// plus extra, flattened, with soft-deleted volumes skipped.
+func queryLiveVolumes(c context.Context, params apiutil.QueryParams, extra bson.D) ([]*vo.VolumeVO, error) {
 	filter, sort, projection := apiutil.ConvertQueryParams(params)
-	filter = append(filter, bson.E{Key: "state", Value: string(models.VersionStateLive)})
+	filter = append(filter, bson.E{Key: "state", Value: string(models.VersionStateLive)}, notRecordDeleted)
+	filter = append(filter, extra...)
 	if len(sort) == 0 {
 		// Without an explicit sort, Mongo returns natural (insertion) order - stable for an
 		// untouched record, but an edit re-inserts that record's new live version, so it jumps
@@ -374,36 +375,38 @@ func QueryVolumes(c context.Context, params apiutil.QueryParams) ([]*vo.VolumeVO
 	}
 	logging.Logger.Debug("query volumes", "filter", filter, "sort", sort, "projection", projection)
 
-	versions, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, sort, projection, params.Start, params.Limit)
+	versions, err := storeQuery[models.VolumeVersion](c, volumeVersionCollection, filter, sort, projection, params.Start, params.Limit)
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for Volumes: %+v", err))
 		return nil, err
 	}
 
-	// Resolve every volume's system reference against one shared map instead of one
-	// gamesystems-api call per volume - see GetSystemsMap. A page of volumes was measured
-	// taking 10+ seconds under the old per-volume GetSystem calls.
-	systemsMap, err := GetSystemsMap(c)
+	// Every meta and every publisher/studio/license across the page is fetched in a handful of
+	// $in queries up front, rather than a meta lookup per volume plus a Get* per relation id.
+	recordIDs := make([]string, 0, len(versions))
+	for _, version := range versions {
+		recordIDs = append(recordIDs, version.RecordID)
+	}
+	metas, err := volumeVersioning.getMetas(c, recordIDs)
 	if err != nil {
-		logging.Logger.Error(fmt.Sprintf("Error while fetching systems map for Volumes: %+v", err))
-		systemsMap = map[string]*vo.SystemVO{}
+		logging.Logger.Error(fmt.Sprintf("Error while querying database for VolumeMetas: %+v", err))
+		return nil, err
 	}
+	relations := loadVolumeRelations(c, versions)
 
 	vos := make([]*vo.VolumeVO, 0, len(versions))
 	for _, version := range versions {
-		meta, err := getVolumeMeta(c, version.RecordID)
-		if err != nil {
-			logging.Logger.Error(fmt.Sprintf("No VolumeMeta found for VolumeVersion record %s: %s", version.RecordID, err.Error()))
-			continue
-		}
+		meta := metas[version.RecordID]
 		if meta == nil {
 			logging.Logger.Error(fmt.Sprintf("No VolumeMeta found for VolumeVersion record %s", version.RecordID))
 			continue
 		}
 		if meta.DeletedAt != nil {
+			// Only a volume soft-deleted before record_deleted existed, and not yet marked by
+			// BackfillRecordDeleted, gets this far.
 			continue
 		}
-		vos = append(vos, flattenVolume(c, meta, version, systemsMap))
+		vos = append(vos, flattenVolume(c, meta, version, relations))
 	}
 
 	logging.Logger.Debug("returning volume value objects", "vos", vos)
@@ -419,10 +422,9 @@ type CatalogStats struct {
 }
 
 // GetCatalogStats computes CatalogStats over every live volume version. Uses an unlimited
-// database.Query (limit 0) rather than a driver-level CountDocuments/aggregate, matching this
-// package's existing pattern of going through database.Query rather than reaching past it to
-// the raw mongo-driver collection - catalog sizes here are small enough (an indie/hobby
-// catalog, not a high-volume one) that this isn't a real cost.
+// storeQuery (limit 0) rather than a count/aggregate, matching this package's existing pattern
+// of reading whole result sets - catalog sizes here are small enough (an indie/hobby catalog,
+// not a high-volume one) that this isn't a real cost.
 func GetCatalogStats(c context.Context) (*CatalogStats, error) {
 	logging.Logger.Info("GetCatalogStats", "c", c)
 
@@ -432,7 +434,7 @@ func GetCatalogStats(c context.Context) (*CatalogStats, error) {
 	filter := bson.D{{Key: "state", Value: string(models.VersionStateLive)}}
 	projection := bson.D{{Key: "submitted_at", Value: 1}}
 
-	versions, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, nil, projection, 0, 0)
+	versions, err := storeQuery[models.VolumeVersion](c, volumeVersionCollection, filter, nil, projection, 0, 0)
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for catalog stats: %+v", err))
 		return nil, err
@@ -451,38 +453,10 @@ func GetCatalogStats(c context.Context) (*CatalogStats, error) {
 }
 
 // GetVolumeTypeStats returns the volumes landing-page-summary card's count/most-recent data -
-// TypeStats-shaped like the other five entity types' Get*Stats (catalog-landing-page-summary),
-// but implemented directly against volumeVersionCollection rather than entityVersioningConfig
-// since Volume isn't on that shared engine (see entity_versioning.go's own doc comment).
+// TypeStats-shaped like the other five entity types' Get*Stats (catalog-landing-page-summary).
 func GetVolumeTypeStats(c context.Context) (*TypeStats, error) {
 	span := tracing.BuildSpanWithParams(c, "volumes", "db-get-volume-type-stats", apiutil.QueryParams{})
 	defer span.End()
 
-	filter := bson.D{{Key: "state", Value: string(models.VersionStateLive)}}
-
-	countProjection := bson.D{{Key: "record_id", Value: 1}}
-	all, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, nil, countProjection, 0, 0)
-	if err != nil {
-		return nil, fmt.Errorf("volume: count live versions: %w", err)
-	}
-
-	stats := &TypeStats{Count: len(all)}
-	if stats.Count == 0 {
-		return stats, nil
-	}
-
-	sortOrder := bson.D{{Key: "submitted_at", Value: -1}}
-	recent, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, sortOrder, nil, 0, 1)
-	if err != nil {
-		return nil, fmt.Errorf("volume: query most recent version: %w", err)
-	}
-	if len(recent) == 0 {
-		return stats, nil
-	}
-
-	submittedAt := recent[0].SubmittedAt
-	stats.LastUpdated = &submittedAt
-	stats.MostRecentID = recent[0].RecordID
-	stats.MostRecentName = recent[0].Title
-	return stats, nil
+	return volumeVersioning.stats(c)
 }
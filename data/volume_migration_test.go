@@ -1,7 +1,6 @@
 package data
 
 import (
-	"os"
 	"testing"
 	"time"
 
@@ -9,10 +8,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
-	"github.com/sweetrpg/common.go/logging"
 	modelcore "github.com/sweetrpg/model-core.go/models"
-	"github.com/sweetrpg/mongodb.go/constants"
-	"github.com/sweetrpg/mongodb.go/database"
 )
 
 type VolumeMigrationTestSuite struct {
@@ -20,9 +16,7 @@ type VolumeMigrationTestSuite struct {
 }
 
 func (suite *VolumeMigrationTestSuite) SetupTest() {
-	_ = os.Setenv(constants.DB_URI, os.Getenv("TEST_DB_URI"))
-	logging.Init()
-	database.SetupDatabase()
+	setupTestStorage()
 	assert.NoError(suite.T(), EnsureVolumeVersioningIndexes(suite.T().Context()))
 }
 
@@ -38,7 +32,7 @@ func (suite *VolumeMigrationTestSuite) TestMigrateVolumesBackfillsMetaAndLiveVer
 			UpdatedBy: "auth0|last-editor",
 		},
 	}
-	_, err := database.Insert[models.Volume]("volumes", legacy)
+	err := storeInsert(suite.T().Context(), "volumes", legacy)
 	assert.NoError(suite.T(), err)
 
 	migrated, err := MigrateVolumes(suite.T().Context())
@@ -60,7 +54,7 @@ func (suite *VolumeMigrationTestSuite) TestMigrateVolumesBackfillsMetaAndLiveVer
 
 func (suite *VolumeMigrationTestSuite) TestMigrateVolumesIsIdempotent() {
 	legacy := models.Volume{ID: "legacy-volume-2", Title: "Legacy Volume Two"}
-	_, err := database.Insert[models.Volume]("volumes", legacy)
+	err := storeInsert(suite.T().Context(), "volumes", legacy)
 	assert.NoError(suite.T(), err)
 
 	first, err := MigrateVolumes(suite.T().Context())
@@ -0,0 +1,259 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/sweetrpg/catalog-objects.go/models"
+	"github.com/sweetrpg/catalog-objects.go/vo"
+	"github.com/sweetrpg/common.go/logging"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ReferenceValidation selects what AddVolume/UpdateVolume/AcceptVolumeVersion do about a volume
+// relation id (system, publisher, studio, license) that doesn't resolve to a live record.
+type ReferenceValidation int
+
+const (
+	// ReferenceValidationOff stores relation ids unchecked - read paths skip ids that don't
+	// resolve, as they always have.
+	ReferenceValidationOff ReferenceValidation = iota
+	// ReferenceValidationReport logs every dangling reference but still writes.
+	ReferenceValidationReport
+	// ReferenceValidationReject refuses the write with a *ReferenceError listing every dangling
+	// reference.
+	ReferenceValidationReject
+)
+
+// VolumeReferenceValidation is the ReferenceValidation volume writes use. Set once at startup
+// (see catalog-api's cmd/catalog-api/main.go), like GameSystemsClient.
+var VolumeReferenceValidation = ReferenceValidationOff
+
+// ReferenceProblem says why a reference dangles.
+type ReferenceProblem string
+
+const (
+	// ReferenceMissing: no record with that id exists.
+	ReferenceMissing ReferenceProblem = "missing"
+	// ReferenceDeleted: the record exists but is soft-deleted.
+	ReferenceDeleted ReferenceProblem = "deleted"
+)
+
+// DanglingReference is one relation id on a volume version that doesn't resolve to a live record.
+type DanglingReference struct {
+	Field   string // the version's bson relation field, e.g. "publisher_ids"
+	ID      string
+	Problem ReferenceProblem
+}
+
+// volumeReferenceTarget is one of a volume version's relation fields and where its ids point.
+type volumeReferenceTarget struct {
+	field          string
+	metaCollection string // empty for system_ids, which resolve against gamesystems-api instead
+	ids            func(*models.VolumeVersion) []string
+}
+
+var volumeReferenceTargets = []volumeReferenceTarget{
+	{field: "system_ids", ids: func(v *models.VolumeVersion) []string { return v.SystemIds }},
+	{field: "publisher_ids", metaCollection: publisherMetaCollection, ids: func(v *models.VolumeVersion) []string { return v.PublisherIds }},
+	{field: "studio_ids", metaCollection: studioMetaCollection, ids: func(v *models.VolumeVersion) []string { return v.StudioIds }},
+	{field: "license_ids", metaCollection: licenseMetaCollection, ids: func(v *models.VolumeVersion) []string { return v.LicenseIds }},
+}
+
+// referenceStatuses looks up every id in ids against target, returning the problem with each one
+// that dangles - one $in query per target rather than one lookup per id. Systems can only be
+// checked for existence (gamesystems-api doesn't expose soft deletion), and not at all without a
+// GameSystemsClient.
+func referenceStatuses(c context.Context, target volumeReferenceTarget, ids []string) (map[string]ReferenceProblem, error) {
+	problems := map[string]ReferenceProblem{}
+	if len(ids) == 0 {
+		return problems, nil
+	}
+
+	if target.metaCollection == "" {
+		if GameSystemsClient == nil {
+			return problems, nil
+		}
+		systems, err := GetSystemsMap(c)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if _, ok := systems[id]; !ok {
+				problems[id] = ReferenceMissing
+			}
+		}
+		return problems, nil
+	}
+
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+	projection := bson.D{{Key: "_id", Value: 1}, {Key: "deleted_at", Value: 1}}
+	metas, err := storeQuery[models.EntityMeta](c, target.metaCollection, filter, nil, projection, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]*models.EntityMeta, len(metas))
+	for _, meta := range metas {
+		found[meta.ID] = meta
+	}
+	for _, id := range ids {
+		meta, ok := found[id]
+		switch {
+		case !ok:
+			problems[id] = ReferenceMissing
+		case meta.DeletedAt != nil:
+			problems[id] = ReferenceDeleted
+		}
+	}
+	return problems, nil
+}
+
+// findDanglingReferences checks fields (nil for every relation field) across versions, returning
+// each version's dangling references at the same index.
+func findDanglingReferences(c context.Context, versions []*models.VolumeVersion, fields []string) ([][]DanglingReference, error) {
+	result := make([][]DanglingReference, len(versions))
+	for _, target := range volumeReferenceTargets {
+		if fields != nil && len(intersectFields([]string{target.field}, fields)) == 0 {
+			continue
+		}
+
+		seen := map[string]bool{}
+		var ids []string
+		for _, v := range versions {
+			for _, id := range target.ids(v) {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+		problems, err := referenceStatuses(c, target, ids)
+		if err != nil {
+			return nil, fmt.Errorf("volume: check %s: %w", target.field, err)
+		}
+		if len(problems) == 0 {
+			continue
+		}
+
+		for i, v := range versions {
+			for _, id := range target.ids(v) {
+				if problem, ok := problems[id]; ok {
+					result[i] = append(result[i], DanglingReference{Field: target.field, ID: id, Problem: problem})
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// validateVolumeReferences applies VolumeReferenceValidation to version's fields (nil for all)
+// ahead of a write. id is the volume's record id, empty for a volume not yet created.
+func validateVolumeReferences(c context.Context, id string, version *models.VolumeVersion, fields []string) error {
+	if VolumeReferenceValidation == ReferenceValidationOff {
+		return nil
+	}
+	dangling, err := findDanglingReferences(c, []*models.VolumeVersion{version}, fields)
+	if err != nil {
+		if VolumeReferenceValidation == ReferenceValidationReport {
+			// Report mode never blocks a write, not even when the check itself fails.
+			logging.Logger.Error("Error while checking volume references", "id", id, "error", err)
+			return nil
+		}
+		return err
+	}
+	if len(dangling[0]) == 0 {
+		return nil
+	}
+	if VolumeReferenceValidation == ReferenceValidationReport {
+		logging.Logger.Info("volume has dangling references", "id", id, "references", dangling[0])
+		return nil
+	}
+	return &ReferenceError{Type: "volume", ID: id, References: dangling[0]}
+}
+
+// validateVolumeVOReferences is validateVolumeReferences for a request VO updating volume id,
+// before it's been mapped onto a version. Only relation ids the volume's current version doesn't
+// already carry are checked (see addedReferences).
+func validateVolumeVOReferences(c context.Context, id string, volume *vo.VolumeVO) error {
+	if VolumeReferenceValidation == ReferenceValidationOff {
+		return nil
+	}
+	_, current, err := volumeVersioning.getCurrent(c, id)
+	if err != nil {
+		return err
+	}
+	version := volumeVOToVersionFields(volume)
+	return validateVolumeReferences(c, id, addedReferences(&version, current), nil)
+}
+
+// checkVolumeAccept is the volume config's checkAccept: validateVolumeReferences on the relation
+// ids the accept would add to the live volume, whichever fields they come in through.
+func checkVolumeAccept(c context.Context, id string, live, current *models.VolumeVersion) error {
+	return validateVolumeReferences(c, id, addedReferences(live, current), nil)
+}
+
+// addedReferences returns a version holding only next's relation ids that current doesn't
+// already carry - what a write actually adds. An id that went stale after it was written is
+// ScanDanglingVolumeReferences' concern; it doesn't block every later edit to the volume.
+func addedReferences(next, current *models.VolumeVersion) *models.VolumeVersion {
+	return &models.VolumeVersion{
+		SystemIds:    addedIDs(next.SystemIds, current.SystemIds),
+		PublisherIds: addedIDs(next.PublisherIds, current.PublisherIds),
+		StudioIds:    addedIDs(next.StudioIds, current.StudioIds),
+		LicenseIds:   addedIDs(next.LicenseIds, current.LicenseIds),
+	}
+}
+
+// addedIDs returns the members of ids not in existing, in ids' order.
+func addedIDs(ids, existing []string) []string {
+	var added []string
+	for _, id := range ids {
+		if !slices.Contains(existing, id) {
+			added = append(added, id)
+		}
+	}
+	return added
+}
+
+// VolumeReferenceReport is one volume ScanDanglingVolumeReferences found with dangling
+// references on its current version.
+type VolumeReferenceReport struct {
+	VolumeID   string
+	Title      string
+	References []DanglingReference
+}
+
+// ScanDanglingVolumeReferences checks every live volume version's relation ids, regardless of
+// VolumeReferenceValidation, and lists each volume with at least one dangling reference - an
+// offline job for finding references that went stale after they were written (a publisher
+// soft-deleted or purged with CascadeOrphan, a system removed from gamesystems-api).
+func ScanDanglingVolumeReferences(c context.Context) ([]*VolumeReferenceReport, error) {
+	filter := bson.D{{Key: "state", Value: string(models.VersionStateLive)}}
+	projection := bson.D{
+		{Key: "record_id", Value: 1}, {Key: "title", Value: 1},
+		{Key: "system_ids", Value: 1}, {Key: "publisher_ids", Value: 1},
+		{Key: "studio_ids", Value: 1}, {Key: "license_ids", Value: 1},
+	}
+	versions, err := storeQuery[models.VolumeVersion](c, volumeVersionCollection, filter, nil, projection, 0, 0)
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while querying database for live VolumeVersions: %+v", err))
+		return nil, err
+	}
+
+	dangling, err := findDanglingReferences(c, versions, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*VolumeReferenceReport, 0)
+	for i, version := range versions {
+		if len(dangling[i]) == 0 {
+			continue
+		}
+		reports = append(reports, &VolumeReferenceReport{
+			VolumeID: version.RecordID, Title: version.Title, References: dangling[i],
+		})
+	}
+	return reports, nil
+}
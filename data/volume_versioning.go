@@ -3,19 +3,12 @@ package data
 import (
 	"context"
 	"fmt"
-	"reflect"
-	"sort"
-	"time"
 
 	"github.com/sweetrpg/catalog-objects.go/models"
 	"github.com/sweetrpg/catalog-objects.go/vo"
 	"github.com/sweetrpg/common.go/logging"
 	modelcore "github.com/sweetrpg/model-core.go/models"
-	"github.com/sweetrpg/mongodb.go/database"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -23,203 +16,144 @@ const (
 	volumeVersionCollection = "volumes_versions"
 )
 
-// EnsureVolumeVersioningIndexes creates the indexes volume version queries rely on: a unique
-// (record_id, version) index so a version number can never be reused for a record, and a
-// (record_id, state) index for the pending-submission lookup. Safe to call on every startup.
-func EnsureVolumeVersioningIndexes(ctx context.Context) error {
-	_, err := database.Db.Collection(volumeVersionCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    bson.D{{Key: "record_id", Value: 1}, {Key: "version", Value: 1}},
-		Options: options.Index().SetUnique(true),
-	})
-	if err != nil {
-		return fmt.Errorf("volumes: create record_id+version index: %w", err)
-	}
-
-	_, err = database.Db.Collection(volumeVersionCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys: bson.D{{Key: "record_id", Value: 1}, {Key: "state", Value: 1}},
-	})
-	if err != nil {
-		return fmt.Errorf("volumes: create record_id+state index: %w", err)
-	}
-
-	return nil
-}
-
-func getVolumeMeta(c context.Context, id string) (*models.VolumeMeta, error) {
-	results, err := database.Query[models.VolumeMeta](volumeMetaCollection, bson.D{{Key: "_id", Value: id}}, nil, nil, 0, 1)
-	if err != nil {
-		return nil, err
-	}
-	if len(results) == 0 {
-		return nil, nil
-	}
-	return results[0], nil
+// VolumeApprovalPolicy is what AcceptVolumeVersion requires before promoting a submission - the
+// zero policy, one reviewer, unless set at startup. See ApprovalPolicy and ApproveVersion.
+var VolumeApprovalPolicy ApprovalPolicy
+
+// VolumeSubmissionCap is how many volume submissions one submitter may have in flight - checked
+// by every submitted UpdateVolume and CreateSubmittedVolumeVersion*, which fail with a
+// *CapExceededError past it. The zero cap, unless set at startup.
+var VolumeSubmissionCap SubmissionCap
+
+var volumeVersioning = entityVersioningConfig[models.VolumeVersion]{
+	metaCollection:    volumeMetaCollection,
+	versionCollection: volumeVersionCollection,
+	typeName:          "volume",
+	lifecycle: func(v *models.VolumeVersion) models.VersionLifecycle {
+		return models.VersionLifecycle{
+			State: v.State, BaseVersion: v.BaseVersion,
+			SubmittedBy: v.SubmittedBy, SubmittedAt: v.SubmittedAt,
+			ReviewedBy: v.ReviewedBy, ReviewedAt: v.ReviewedAt,
+			ReviewNote: v.ReviewNote, ResultingVersion: v.ResultingVersion,
+		}
+	},
+	setLifecycle: func(v *models.VolumeVersion, lc models.VersionLifecycle) {
+		v.State, v.BaseVersion = lc.State, lc.BaseVersion
+		v.SubmittedBy, v.SubmittedAt = lc.SubmittedBy, lc.SubmittedAt
+		v.ReviewedBy, v.ReviewedAt = lc.ReviewedBy, lc.ReviewedAt
+		v.ReviewNote, v.ResultingVersion = lc.ReviewNote, lc.ResultingVersion
+	},
+	setID:       func(v *models.VolumeVersion, id string) { v.ID = id },
+	setRecordID: func(v *models.VolumeVersion, id string) { v.RecordID = id },
+	setVersion:  func(v *models.VolumeVersion, n int) { v.Version = n },
+	version:     func(v *models.VolumeVersion) int { return v.Version },
+	recordID:    func(v *models.VolumeVersion) string { return v.RecordID },
+	displayName: func(v *models.VolumeVersion) string { return v.Title },
+	fields: map[string]entityFieldAccessor[models.VolumeVersion]{
+		"title":            {get: func(v *models.VolumeVersion) any { return v.Title }, set: func(v *models.VolumeVersion, val any) { v.Title = val.(string) }},
+		"description":      {get: func(v *models.VolumeVersion) any { return v.Description }, set: func(v *models.VolumeVersion, val any) { v.Description = val.(string) }},
+		"notes":            {get: func(v *models.VolumeVersion) any { return v.Notes }, set: func(v *models.VolumeVersion, val any) { v.Notes = val.(string) }},
+		"format":           {get: func(v *models.VolumeVersion) any { return v.Format }, set: func(v *models.VolumeVersion, val any) { v.Format = val.(string) }},
+		"cover_asset_id":   {get: func(v *models.VolumeVersion) any { return v.CoverAssetId }, set: func(v *models.VolumeVersion, val any) { v.CoverAssetId = val.(string) }},
+		"sample_asset_ids": {get: func(v *models.VolumeVersion) any { return v.SampleAssetIds }, set: func(v *models.VolumeVersion, val any) { v.SampleAssetIds = val.([]string) }},
+		"system_ids":       {get: func(v *models.VolumeVersion) any { return v.SystemIds }, set: func(v *models.VolumeVersion, val any) { v.SystemIds = val.([]string) }, setMerge: true},
+		"publisher_ids":    {get: func(v *models.VolumeVersion) any { return v.PublisherIds }, set: func(v *models.VolumeVersion, val any) { v.PublisherIds = val.([]string) }, setMerge: true},
+		"studio_ids":       {get: func(v *models.VolumeVersion) any { return v.StudioIds }, set: func(v *models.VolumeVersion, val any) { v.StudioIds = val.([]string) }, setMerge: true},
+		"license_ids":      {get: func(v *models.VolumeVersion) any { return v.LicenseIds }, set: func(v *models.VolumeVersion, val any) { v.LicenseIds = val.([]string) }, setMerge: true},
+		"properties":       {get: func(v *models.VolumeVersion) any { return v.Properties }, set: func(v *models.VolumeVersion, val any) { v.Properties = val.([]modelcore.Property) }, setMerge: true},
+		"tags":             {get: func(v *models.VolumeVersion) any { return v.Tags }, set: func(v *models.VolumeVersion, val any) { v.Tags = val.([]modelcore.Tag) }, setMerge: true},
+	},
+	staged: &stagedFieldsConfig[models.VolumeVersion]{
+		keys: []string{"staged_cover_asset_id", "staged_sample_asset_ids"},
+		clear: func(v *models.VolumeVersion) {
+			v.StagedCoverAssetId = nil
+			v.StagedSampleAssetIds = nil
+		},
+	},
+	references: []entityReference{
+		{dependentType: "contribution", collection: "contributions", field: "volume_id"},
+		{dependentType: "review", collection: "reviews", field: "volume_id"},
+	},
+	search: func(v *models.VolumeVersion) (string, []string) {
+		return v.Title, append([]string{v.Description}, tagSearchText(v.Tags)...)
+	},
+	extraFields: func(c context.Context, v *models.VolumeVersion) (bson.D, error) {
+		return volumeRatingFields(c, v.RecordID)
+	},
+	onPurge:        purgeVolume,
+	checkAccept:    checkVolumeAccept,
+	approvalPolicy: &VolumeApprovalPolicy,
+	submissionCap:  &VolumeSubmissionCap,
 }
 
-func getVolumeVersion(c context.Context, recordID string, version int) (*models.VolumeVersion, error) {
-	filter := bson.D{{Key: "record_id", Value: recordID}, {Key: "version", Value: version}}
-	results, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, nil, nil, 0, 1)
-	if err != nil {
-		return nil, err
-	}
-	if len(results) == 0 {
-		return nil, nil
+// purgeVolume is the volume config's onPurge: the volume's credit records and rating aggregate go
+// with it (see purgeCredits, purgeVolumeRatings).
+func purgeVolume(c context.Context, volumeID string, policy CascadePolicy) error {
+	if err := purgeCredits(c, "volume_id", volumeID, policy); err != nil {
+		return err
 	}
-	return results[0], nil
+	return purgeVolumeRatings(c, volumeID, policy)
 }
 
-func nextVolumeVersionNumber(c context.Context, recordID string) (int, error) {
-	filter := bson.D{{Key: "record_id", Value: recordID}}
-	sortOrder := bson.D{{Key: "version", Value: -1}}
-	results, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, sortOrder, nil, 0, 1)
-	if err != nil {
-		return 0, err
+// EnsureVolumeVersioningIndexes creates the indexes volume version queries rely on: a unique
+// (record_id, version) index so a version number can never be reused for a record, a
+// (record_id, state) index for the pending-submission lookup, a (relation ids, state) index per
+// relation field for the QueryVolumesBy* reverse lookups. Safe to call on every startup.
+func EnsureVolumeVersioningIndexes(ctx context.Context) error {
+	if err := volumeVersioning.ensureIndexes(ctx); err != nil {
+		return err
 	}
-	if len(results) == 0 {
-		return 1, nil
+	for _, field := range []string{"system_ids", "publisher_ids", "studio_ids", "license_ids"} {
+		err := Storage.EnsureIndex(ctx, volumeVersionCollection, bson.D{{Key: field, Value: 1}, {Key: "state", Value: 1}}, false)
+		if err != nil {
+			return fmt.Errorf("volume: create %s+state index: %w", field, err)
+		}
 	}
-	return results[0].Version + 1, nil
-}
-
-func setVolumeVersionState(c context.Context, recordID string, version int, fields bson.D) error {
-	filter := bson.D{{Key: "record_id", Value: recordID}, {Key: "version", Value: version}}
-	_, err := database.Db.Collection(volumeVersionCollection).UpdateOne(c, filter, bson.D{{Key: "$set", Value: fields}})
-	return err
-}
-
-func archiveVolumeVersion(c context.Context, recordID string, version int) error {
-	return setVolumeVersionState(c, recordID, version, bson.D{{Key: "state", Value: string(models.VersionStateArchived)}})
-}
-
-func setVolumeMetaCurrentVersion(c context.Context, recordID string, version int) error {
-	_, err := database.Db.Collection(volumeMetaCollection).UpdateOne(
-		c,
-		bson.D{{Key: "_id", Value: recordID}},
-		bson.D{{Key: "$set", Value: bson.D{{Key: "current_version", Value: version}}}},
-	)
-	return err
+	return nil
 }
 
 // ListVolumeVersions returns every version of a volume, newest first.
 func ListVolumeVersions(c context.Context, id string) ([]*vo.VolumeVersionVO, error) {
-	filter := bson.D{{Key: "record_id", Value: id}}
-	sortOrder := bson.D{{Key: "version", Value: -1}}
-	versions, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, sortOrder, nil, 0, 0)
+	versions, err := volumeVersioning.listVersions(c, id)
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for VolumeVersions: %+v", err))
 		return nil, err
 	}
 
-	vos := make([]*vo.VolumeVersionVO, 0, len(versions))
-	for _, version := range versions {
-		vos = append(vos, volumeVersionModelToVO(c, version))
-	}
-	return vos, nil
+	return volumeVersionModelsToVOs(c, versions), nil
 }
 
 // GetVolumeVersion returns one version's full snapshot, regardless of whether it's current.
 func GetVolumeVersion(c context.Context, id string, version int) (*vo.VolumeVersionVO, error) {
-	result, err := getVolumeVersion(c, id, version)
+	result, err := volumeVersioning.requireVersion(c, id, version)
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for VolumeVersion: %+v", err))
 		return nil, err
 	}
-	if result == nil {
-		return nil, nil
-	}
 	return volumeVersionModelToVO(c, result), nil
 }
 
-// volumeVersionSubstantiveFields are the fields a submission can change and a review can
-// selectively accept - everything on VolumeVersion except its version-lifecycle bookkeeping.
-var volumeVersionSubstantiveFields = []string{
-	"title", "description", "notes", "format", "cover_asset_id", "sample_asset_ids",
-	"system_ids", "publisher_ids", "studio_ids", "license_ids", "properties", "tags",
-}
-
-func volumeVersionFieldValue(v *models.VolumeVersion, field string) any {
-	switch field {
-	case "title":
-		return v.Title
-	case "description":
-		return v.Description
-	case "notes":
-		return v.Notes
-	case "format":
-		return v.Format
-	case "cover_asset_id":
-		return v.CoverAssetId
-	case "sample_asset_ids":
-		return v.SampleAssetIds
-	case "system_ids":
-		return v.SystemIds
-	case "publisher_ids":
-		return v.PublisherIds
-	case "studio_ids":
-		return v.StudioIds
-	case "license_ids":
-		return v.LicenseIds
-	case "properties":
-		return v.Properties
-	case "tags":
-		return v.Tags
-	default:
-		return nil
-	}
-}
-
-func setVolumeVersionFieldValue(v *models.VolumeVersion, field string, value any) {
-	switch field {
-	case "title":
-		v.Title = value.(string)
-	case "description":
-		v.Description = value.(string)
-	case "notes":
-		v.Notes = value.(string)
-	case "format":
-		v.Format = value.(string)
-	case "cover_asset_id":
-		v.CoverAssetId = value.(string)
-	case "sample_asset_ids":
-		v.SampleAssetIds = value.([]string)
-	case "system_ids":
-		v.SystemIds = value.([]string)
-	case "publisher_ids":
-		v.PublisherIds = value.([]string)
-	case "studio_ids":
-		v.StudioIds = value.([]string)
-	case "license_ids":
-		v.LicenseIds = value.([]string)
-	case "properties":
-		v.Properties = value.([]modelcore.Property)
-	case "tags":
-		v.Tags = value.([]modelcore.Tag)
-	}
-}
-
-// volumeVersionChangedFields returns the substantive fields where submitted differs from base.
-func volumeVersionChangedFields(submitted, base *models.VolumeVersion) []string {
-	var changed []string
-	for _, field := range volumeVersionSubstantiveFields {
-		if !reflect.DeepEqual(volumeVersionFieldValue(submitted, field), volumeVersionFieldValue(base, field)) {
-			changed = append(changed, field)
-		}
+// DiffVolumeVersions compares two arbitrary versions of a volume field by field - e.g. a rollback
+// target against the current version. Either version missing is a *VersionNotFoundError.
+func DiffVolumeVersions(c context.Context, id string, from, to int) (*VersionDiff, error) {
+	diff, err := volumeVersioning.diffVersions(c, id, from, to)
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while diffing VolumeVersions: %+v", err))
+		return nil, err
 	}
-	return changed
+	return diff, nil
 }
 
-func intersectFields(fields, selected []string) []string {
-	selectedSet := make(map[string]bool, len(selected))
-	for _, f := range selected {
-		selectedSet[f] = true
-	}
-	var out []string
-	for _, f := range fields {
-		if selectedSet[f] {
-			out = append(out, f)
-		}
+// DiffVolumeSubmission previews AcceptVolumeVersion for a submitted version: its changes against
+// its base and against the current version, and which fields a full accept would exclude as
+// conflicts. A version that isn't submitted is an *InvalidStateError.
+func DiffVolumeSubmission(c context.Context, id string, version int) (*SubmissionDiff, error) {
+	diff, err := volumeVersioning.diffSubmission(c, id, version)
+	if err != nil {
+		logging.Logger.Error(fmt.Sprintf("Error while diffing VolumeVersion submission: %+v", err))
+		return nil, err
 	}
-	return out
+	return diff, nil
 }
 
 // AcceptVolumeVersion reviews a submitted version. selectedFields == nil accepts every field the
@@ -233,165 +167,75 @@ func intersectFields(fields, selected []string) []string {
 // version from the actual current version with only the accepted fields overlaid, promotes that
 // derived version to live, and marks the submitted version partially_accepted, referencing it.
 //
+// resolutions (field name -> ConflictResolution, nil for none) lets the reviewer settle a
+// conflicting field instead of having it excluded: take the submitted value, keep the current
+// one, or supply a hand-merged value. A conflicting list field with no resolution - system_ids,
+// publisher_ids, studio_ids, license_ids, properties, tags - is set-merged automatically, keeping
+// both sides' additions and removals and matching tags and properties by name (see setMerge), so
+// only conflicting scalar fields without a resolution come back as conflicts. An unusable
+// resolution is a *ResolutionError.
+//
 // liveCoverAssetId/liveSampleAssetIds are an optional override (nil/nil for the common case) -
 // see design.md's "Staged edit-session assets ride on the submitted version as separate staged
 // fields": when the submitted version being accepted carries staged (unpromoted) assets, the
 // caller promotes them via the asset store first and passes the resulting live ids here, so the
 // version that goes live carries live ids rather than the version's own (still-unpromoted)
 // coverAssetId/sampleAssetIds.
-func AcceptVolumeVersion(c context.Context, id string, version int, selectedFields []string, reviewedBy string, reviewNote *string, liveCoverAssetId *string, liveSampleAssetIds []string) (*vo.VolumeVersionVO, []string, error) {
-	submitted, err := getVolumeVersion(c, id, version)
-	if err != nil {
-		return nil, nil, err
-	}
-	if submitted == nil {
-		return nil, nil, fmt.Errorf("volume %s: version %d not found", id, version)
-	}
-	if submitted.State != models.VersionStateSubmitted {
-		return nil, nil, fmt.Errorf("volume %s: version %d is not submitted (state: %s)", id, version, submitted.State)
-	}
-	if submitted.BaseVersion == nil {
-		return nil, nil, fmt.Errorf("volume %s: version %d has no base version to review against", id, version)
-	}
-
-	meta, err := getVolumeMeta(c, id)
-	if err != nil {
-		return nil, nil, err
-	}
-	if meta == nil {
-		return nil, nil, fmt.Errorf("volume %s: meta record not found", id)
-	}
-
-	current, err := getVolumeVersion(c, id, meta.CurrentVersion)
-	if err != nil {
-		return nil, nil, err
-	}
-	if current == nil {
-		return nil, nil, fmt.Errorf("volume %s: current version %d not found", id, meta.CurrentVersion)
-	}
-
-	now := time.Now()
-
-	if selectedFields == nil && *submitted.BaseVersion == meta.CurrentVersion {
-		if err := archiveVolumeVersion(c, id, meta.CurrentVersion); err != nil {
-			return nil, nil, err
-		}
-		submitted.State = models.VersionStateLive
-		submitted.ReviewedBy = &reviewedBy
-		submitted.ReviewedAt = &now
-		submitted.ReviewNote = reviewNote
-		update := bson.D{
-			{Key: "state", Value: string(models.VersionStateLive)},
-			{Key: "reviewed_by", Value: reviewedBy},
-			{Key: "reviewed_at", Value: now},
-			{Key: "review_note", Value: reviewNote},
-			{Key: "staged_cover_asset_id", Value: nil},
-			{Key: "staged_sample_asset_ids", Value: nil},
-		}
-		submitted.StagedCoverAssetId = nil
-		submitted.StagedSampleAssetIds = nil
-		if liveCoverAssetId != nil {
-			update = append(update, bson.E{Key: "cover_asset_id", Value: *liveCoverAssetId})
-			submitted.CoverAssetId = *liveCoverAssetId
-		}
-		if liveSampleAssetIds != nil {
-			update = append(update, bson.E{Key: "sample_asset_ids", Value: liveSampleAssetIds})
-			submitted.SampleAssetIds = liveSampleAssetIds
-		}
-		if err := setVolumeVersionState(c, id, version, update); err != nil {
-			return nil, nil, err
-		}
-		if err := setVolumeMetaCurrentVersion(c, id, version); err != nil {
-			return nil, nil, err
-		}
-		return volumeVersionModelToVO(c, submitted), nil, nil
-	}
-
-	baseVersion, err := getVolumeVersion(c, id, *submitted.BaseVersion)
+//
+// expectedCurrentVersion is UpdateVolume's optimistic-concurrency precondition - a stale value
+// returns a *ConflictError before anything is written.
+//
+// Under VolumeReferenceValidation, the relation ids the accept would add to the live volume are
+// checked in the accept's transaction - ids it already carries, and ids in fields the accept
+// leaves out, aren't. A ReferenceValidationReject finding returns a *ReferenceError and leaves
+// the submission pending.
+//
+// A version another reviewer has claimed (see ClaimVersion) is refused with a *ClaimError, and
+// one VolumeApprovalPolicy isn't yet satisfied for - reviewedBy's accept counting as their
+// approval - with an *ApprovalError.
+func AcceptVolumeVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, liveCoverAssetId *string, liveSampleAssetIds []string, expectedCurrentVersion *int) (*vo.VolumeVersionVO, []string, error) {
+	overrides := prepareVolumeAccept(liveCoverAssetId, liveSampleAssetIds)
+	result, conflicts, err := volumeVersioning.acceptVersionWithOverrides(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, overrides, expectedCurrentVersion)
 	if err != nil {
-		return nil, nil, err
-	}
-	if baseVersion == nil {
-		return nil, nil, fmt.Errorf("volume %s: base version %d not found", id, *submitted.BaseVersion)
-	}
-
-	changed := volumeVersionChangedFields(submitted, baseVersion)
-	target := changed
-	if selectedFields != nil {
-		target = intersectFields(changed, selectedFields)
-	}
-	sort.Strings(target)
-
-	derived := *current
-	var conflicts []string
-	var accepted []string
-	for _, field := range target {
-		if !reflect.DeepEqual(volumeVersionFieldValue(current, field), volumeVersionFieldValue(baseVersion, field)) {
-			conflicts = append(conflicts, field)
-			continue
-		}
-		setVolumeVersionFieldValue(&derived, field, volumeVersionFieldValue(submitted, field))
-		accepted = append(accepted, field)
+		return nil, conflicts, err
 	}
+	return volumeVersionModelToVO(c, result), conflicts, nil
+}
 
-	nextVersion, err := nextVolumeVersionNumber(c, id)
+// PreviewAcceptVolumeVersion is AcceptVolumeVersion as a dry run, taking the same arguments: it
+// runs the same checks and works out the same outcome, but writes nothing, so a reviewer can
+// see the resulting live volume and any conflicts before confirming. A submission or current
+// version that changes in between can of course change the real outcome - pass the preview's
+// CurrentVersion as the real accept's expectedCurrentVersion to refuse that case.
+func PreviewAcceptVolumeVersion(c context.Context, id string, version int, selectedFields []string, resolutions map[string]ConflictResolution, reviewedBy string, reviewNote *string, liveCoverAssetId *string, liveSampleAssetIds []string, expectedCurrentVersion *int) (*AcceptPreview[vo.VolumeVersionVO], error) {
+	overrides := prepareVolumeAccept(liveCoverAssetId, liveSampleAssetIds)
+	plan, err := volumeVersioning.previewAccept(c, id, version, selectedFields, resolutions, reviewedBy, reviewNote, overrides, expectedCurrentVersion)
 	if err != nil {
-		return nil, nil, err
+		logging.Logger.Error(fmt.Sprintf("Error while previewing VolumeVersion accept: %+v", err))
+		return nil, err
 	}
+	return newAcceptPreview(plan, func(v *models.VolumeVersion) *vo.VolumeVersionVO { return volumeVersionModelToVO(c, v) }), nil
+}
 
-	derived.ID = primitive.NewObjectID().Hex()
-	derived.RecordID = id
-	derived.Version = nextVersion
-	derived.State = models.VersionStateLive
-	derived.BaseVersion = &meta.CurrentVersion
-	derived.SubmittedBy = submitted.SubmittedBy
-	derived.SubmittedAt = now
-	derived.ReviewedBy = &reviewedBy
-	derived.ReviewedAt = &now
-	derived.ReviewNote = reviewNote
-	derived.ResultingVersion = nil
-	derived.StagedCoverAssetId = nil
-	derived.StagedSampleAssetIds = nil
+// prepareVolumeAccept turns AcceptVolumeVersion's live asset ids into
+// acceptVersionWithOverrides' overrides.
+func prepareVolumeAccept(liveCoverAssetId *string, liveSampleAssetIds []string) map[string]any {
+	overrides := map[string]any{}
 	if liveCoverAssetId != nil {
-		derived.CoverAssetId = *liveCoverAssetId
+		overrides["cover_asset_id"] = *liveCoverAssetId
 	}
 	if liveSampleAssetIds != nil {
-		derived.SampleAssetIds = liveSampleAssetIds
+		overrides["sample_asset_ids"] = liveSampleAssetIds
 	}
-
-	if _, err := database.Insert[models.VolumeVersion](volumeVersionCollection, derived); err != nil {
-		return nil, nil, err
-	}
-	if err := archiveVolumeVersion(c, id, meta.CurrentVersion); err != nil {
-		return nil, nil, err
-	}
-	if err := setVolumeMetaCurrentVersion(c, id, nextVersion); err != nil {
-		return nil, nil, err
-	}
-	if err := setVolumeVersionState(c, id, version, bson.D{
-		{Key: "state", Value: string(models.VersionStatePartiallyAccepted)},
-		{Key: "reviewed_by", Value: reviewedBy},
-		{Key: "reviewed_at", Value: now},
-		{Key: "review_note", Value: reviewNote},
-		{Key: "resulting_version", Value: nextVersion},
-	}); err != nil {
-		return nil, nil, err
-	}
-
-	logging.Logger.Info("AcceptVolumeVersion: derived version", "id", id, "version", nextVersion, "accepted", accepted, "conflicts", conflicts)
-
-	return volumeVersionModelToVO(c, &derived), conflicts, nil
+	return overrides
 }
 
 // CountSubmittedVolumeVersionsBySubmitter counts a submitter's currently-pending (state:
 // submitted) volume versions - the version-model replacement for
-// proposedchanges.CountPendingBySubmitter, used by submissioncap's cap check.
+// proposedchanges.CountPendingBySubmitter. The cap itself is VolumeSubmissionCap, enforced when
+// the version is created; this is for showing a submitter where they stand.
 func CountSubmittedVolumeVersionsBySubmitter(c context.Context, submittedBy string) (int64, error) {
-	filter := bson.D{
-		{Key: "submitted_by", Value: submittedBy},
-		{Key: "state", Value: string(models.VersionStateSubmitted)},
-	}
-	return database.Db.Collection(volumeVersionCollection).CountDocuments(c, filter)
+	return volumeVersioning.countSubmittedBySubmitter(c, submittedBy)
 }
 
 // PendingStagedAssetIds is the set of staged cover/sample asset ids currently referenced by a
@@ -413,7 +257,7 @@ func ListPendingStagedAssetIds(c context.Context) (*PendingStagedAssetIds, error
 		{Key: "staged_cover_asset_id", Value: 1},
 		{Key: "staged_sample_asset_ids", Value: 1},
 	}
-	versions, err := database.Query[models.VolumeVersion](volumeVersionCollection, filter, nil, projection, 0, 0)
+	versions, err := storeQuery[models.VolumeVersion](c, volumeVersionCollection, filter, nil, projection, 0, 0)
 	if err != nil {
 		logging.Logger.Error(fmt.Sprintf("Error while querying database for pending staged asset ids: %+v", err))
 		return nil, err
@@ -432,88 +276,29 @@ func ListPendingStagedAssetIds(c context.Context) (*PendingStagedAssetIds, error
 // RejectVolumeVersion marks a submitted version rejected, with an optional note. The record's
 // current-version pointer is unchanged.
 func RejectVolumeVersion(c context.Context, id string, version int, reviewedBy string, reviewNote *string) error {
-	submitted, err := getVolumeVersion(c, id, version)
-	if err != nil {
-		return err
-	}
-	if submitted == nil {
-		return fmt.Errorf("volume %s: version %d not found", id, version)
-	}
-	if submitted.State != models.VersionStateSubmitted {
-		return fmt.Errorf("volume %s: version %d is not submitted (state: %s)", id, version, submitted.State)
-	}
-
-	now := time.Now()
-	return setVolumeVersionState(c, id, version, bson.D{
-		{Key: "state", Value: string(models.VersionStateRejected)},
-		{Key: "reviewed_by", Value: reviewedBy},
-		{Key: "reviewed_at", Value: now},
-		{Key: "review_note", Value: reviewNote},
-	})
+	return volumeVersioning.rejectVersion(c, id, version, reviewedBy, reviewNote)
 }
 
 // RetractVolumeVersion lets the original submitter withdraw their own pending submission,
 // setting its state to withdrawn - see design.md's "Retract/pull-back need a `withdrawn` version
-// state". The record's current-version pointer is unchanged. Returns an error if the version
-// isn't submitted, or wasn't submitted by submitterID.
+// state". The record's current-version pointer is unchanged. Returns an *InvalidStateError if the
+// version isn't submitted, or a *NotSubmitterError if it wasn't submitted by submitterID.
 func RetractVolumeVersion(c context.Context, id string, version int, submitterID string) (*vo.VolumeVersionVO, error) {
-	submitted, err := getVolumeVersion(c, id, version)
+	result, err := volumeVersioning.retractVersion(c, id, version, submitterID)
 	if err != nil {
 		return nil, err
 	}
-	if submitted == nil {
-		return nil, fmt.Errorf("volume %s: version %d not found", id, version)
-	}
-	if submitted.State != models.VersionStateSubmitted {
-		return nil, fmt.Errorf("volume %s: version %d is not submitted (state: %s)", id, version, submitted.State)
-	}
-	if submitted.SubmittedBy != submitterID {
-		return nil, fmt.Errorf("volume %s: version %d was not submitted by %s", id, version, submitterID)
-	}
-
-	if err := setVolumeVersionState(c, id, version, bson.D{
-		{Key: "state", Value: string(models.VersionStateWithdrawn)},
-	}); err != nil {
-		return nil, err
-	}
-	submitted.State = models.VersionStateWithdrawn
-	return volumeVersionModelToVO(c, submitted), nil
+	return volumeVersionModelToVO(c, result), nil
 }
 
 // SetCurrentVolumeVersion rolls a record back (or forward) to an arbitrary existing version,
 // independent of the submit/review flow - marking that version live and archiving whichever
-// version was previously current.
-func SetCurrentVolumeVersion(c context.Context, id string, version int) (*vo.VolumeVersionVO, error) {
-	meta, err := getVolumeMeta(c, id)
+// version was previously current. A stale non-nil expectedCurrentVersion returns a
+// *ConflictError instead (see UpdateVolume).
+func SetCurrentVolumeVersion(c context.Context, id string, version int, expectedCurrentVersion *int) (*vo.VolumeVersionVO, error) {
+	result, err := volumeVersioning.setCurrentVersion(c, id, version, expectedCurrentVersion)
 	if err != nil {
 		return nil, err
 	}
-	if meta == nil {
-		return nil, nil
-	}
-
-	target, err := getVolumeVersion(c, id, version)
-	if err != nil {
-		return nil, err
-	}
-	if target == nil {
-		return nil, fmt.Errorf("volume %s: version %d not found", id, version)
-	}
-
-	if version == meta.CurrentVersion {
-		return volumeVersionModelToVO(c, target), nil
-	}
-
-	if err := archiveVolumeVersion(c, id, meta.CurrentVersion); err != nil {
-		return nil, err
-	}
-	if err := setVolumeVersionState(c, id, version, bson.D{{Key: "state", Value: string(models.VersionStateLive)}}); err != nil {
-		return nil, err
-	}
-	if err := setVolumeMetaCurrentVersion(c, id, version); err != nil {
-		return nil, err
-	}
-
-	target.State = models.VersionStateLive
-	return volumeVersionModelToVO(c, target), nil
+	return volumeVersionModelToVO(c, result), nil
 }